@@ -0,0 +1,51 @@
+// Command lockin-helper is the only part of LockIn that runs as root. It
+// listens on a local socket for "apply this block set" and "clear" requests
+// and rewrites LockIn's section of the hosts file accordingly.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/helper"
+)
+
+func main() {
+	socket := flag.String("socket", helper.SocketPath(), "helper socket path")
+	allow := flag.String("allow-uid", "", "comma-separated user ids allowed to send requests (root is always allowed)")
+	flag.Parse()
+
+	if os.Geteuid() != 0 {
+		log.Fatal("lockin-helper must run as root")
+	}
+
+	var uids []int
+	for _, field := range strings.Split(*allow, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		uid, err := strconv.Atoi(field)
+		if err != nil {
+			log.Fatalf("invalid uid %q", field)
+		}
+		uids = append(uids, uid)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := helper.NewServer(blocker.HostsEnforcer{}, uids)
+	log.Println("lockin-helper listening on", *socket)
+	err := server.ListenAndServe(ctx, *socket)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
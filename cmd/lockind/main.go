@@ -0,0 +1,141 @@
+// Command lockind is the LockIn background daemon. It runs the scheduler,
+// owns the blockers and serves the control socket the TUI and CLI talk to.
+// It runs as the desktop user; hosts-file writes go through lockin-helper
+// unless lockind itself happens to be root.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/config"
+	"github.com/youssef28m/LockIn/internal/core"
+	"github.com/youssef28m/LockIn/internal/daemon"
+	"github.com/youssef28m/LockIn/internal/helper"
+	"github.com/youssef28m/LockIn/internal/hooks"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/notify"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// configCheckInterval is how often the config file is checked for changes.
+const configCheckInterval = 2 * time.Second
+
+func main() {
+	socket := flag.String("socket", daemon.SocketPath(), "control socket path")
+	helperSocket := flag.String("helper", helper.SocketPath(), "privileged helper socket path")
+	configPath := flag.String("config", config.Path(), "config file path")
+	flag.Parse()
+
+	storage.CreateDB()
+	db := storage.Connect()
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Printf("Using defaults for invalid settings:\n%v", err)
+	}
+	seedProfiles(db, cfg)
+
+	scheduler := core.NewScheduler(db, newEnforcer(cfg.Blocker, *helperSocket))
+	scheduler.SetInterval(cfg.Daemon.TickInterval.Duration)
+	scheduler.SetNotifier(notify.New(cfg.Notify.Backend))
+	scheduler.SetHooks(newHooks(cfg.Hooks, *configPath))
+	go scheduler.Run(ctx)
+
+	// A config that no longer loads cleanly is ignored as a whole, so a
+	// half-saved file can't switch blocking off.
+	go config.Watch(ctx, *configPath, configCheckInterval, func(next config.Config, err error) {
+		if err != nil {
+			log.Printf("Config file changed but isn't valid; keeping the current settings:\n%v", err)
+			return
+		}
+		log.Println("Reloaded", *configPath)
+
+		if next.Blocker != cfg.Blocker {
+			scheduler.SetEnforcer(newEnforcer(next.Blocker, *helperSocket))
+		}
+		if next.Notify != cfg.Notify {
+			scheduler.SetNotifier(notify.New(next.Notify.Backend))
+		}
+		scheduler.SetHooks(newHooks(next.Hooks, *configPath))
+		scheduler.SetInterval(next.Daemon.TickInterval.Duration)
+		seedProfiles(db, next)
+		cfg = next
+	})
+
+	server := daemon.NewServer(db, scheduler)
+	log.Println("lockind listening on", *socket)
+	err = server.ListenAndServe(ctx, *socket)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newEnforcer returns the enforcer for the configured backend.
+func newEnforcer(cfg config.Blocker, helperSocket string) core.Enforcer {
+	hosts := blocker.HostsEnforcer{Path: cfg.HostsPath, RedirectIP: cfg.RedirectIP}
+	client := helper.NewClient(helperSocket)
+	client.RedirectIP = cfg.RedirectIP
+
+	switch cfg.Backend {
+	case config.BackendNone:
+		return noEnforcer{}
+	case config.BackendHosts:
+		return hosts
+	case config.BackendHelper:
+		return client
+	}
+	if os.Geteuid() == 0 {
+		return hosts
+	}
+	return client
+}
+
+// newHooks returns the configured hooks. Without a dir set, they live in
+// the hooks directory next to the config file.
+func newHooks(cfg config.Hooks, configPath string) hooks.Hooks {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = filepath.Join(filepath.Dir(configPath), "hooks")
+	}
+	return hooks.Hooks{
+		Dir:        dir,
+		Start:      cfg.Start,
+		End:        cfg.End,
+		BreakStart: cfg.BreakStart,
+		BreakEnd:   cfg.BreakEnd,
+		Timeout:    cfg.Timeout.Duration,
+	}
+}
+
+func seedProfiles(db *sql.DB, cfg config.Config) {
+	profiles := make([]models.Profile, len(cfg.Profiles))
+	for i, profile := range cfg.Profiles {
+		profiles[i] = models.Profile{Name: profile.Name, DurationSeconds: int64(profile.Duration.Seconds())}
+	}
+	err := service.SeedProfiles(db, profiles)
+	if err != nil {
+		log.Println("Error creating profiles from config:", err)
+	}
+}
+
+// noEnforcer is the "none" backend: sessions are tracked but nothing is
+// blocked.
+type noEnforcer struct{}
+
+func (noEnforcer) Apply(domains []string) error { return nil }
+
+func (noEnforcer) Clear() error { return nil }
@@ -5,14 +5,49 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/cli"
+	"github.com/youssef28m/LockIn/internal/config"
+	"github.com/youssef28m/LockIn/internal/daemon"
 	"github.com/youssef28m/LockIn/internal/ui"
+	"github.com/youssef28m/LockIn/internal/ui/pages"
+	"github.com/youssef28m/LockIn/internal/ui/theme"
 )
 
 
 func main() {
-	p := tea.NewProgram(ui.NewRootModel())
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	svc, err := daemon.Connect()
+	if err != nil {
+		fmt.Printf("Alas, there's been an error: %v", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Using defaults for settings that aren't valid (see lockin config validate):\n%v\n", err)
+	}
+	th, err := theme.Resolve(cfg.UI.Theme, cfg.UI.EmojiEnabled(), theme.NoColor())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v; using the dark theme\n", err)
+		th, _ = theme.New(theme.Dark, cfg.UI.EmojiEnabled(), !theme.NoColor())
+	}
+
+	keys, err := pages.NewKeymap(cfg.Keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring [keys] in config file: %v\n", err)
+		keys = pages.DefaultKeymap()
+	}
+
+	p := tea.NewProgram(ui.NewRootModel(svc, ui.Options{
+		Theme:           th,
+		Keys:            keys,
+		DefaultDuration: cfg.Session.DefaultDuration.Duration,
+	}))
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
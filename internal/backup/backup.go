@@ -0,0 +1,207 @@
+// Package backup saves the whole LockIn database as a portable JSON archive
+// and restores it, on this machine or another one.
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// Format identifies LockIn backups among other JSON files.
+const Format = "lockin-backup"
+
+// Version is the version of the archive layout written by this build. The
+// database schema is versioned separately, in SchemaVersion.
+const Version = 1
+
+// How a restore treats what is already in the database.
+const (
+	// ModeMerge adds what the database doesn't have yet.
+	ModeMerge = "merge"
+	// ModeReplace deletes the sessions, block lists and profiles first.
+	// The event log is kept: the sessions are logged as deleted.
+	ModeReplace = "replace"
+)
+
+// Archive is a backup: every table as it was in the schema version it was
+// taken in. Checksum covers Tables, so a damaged file is refused. It has no
+// secret in it, so anyone can edit a backup and fix the sum; that is why a
+// restore only ever appends to the event log.
+type Archive struct {
+	Format        string                   `json:"format"`
+	Version       int                      `json:"version"`
+	SchemaVersion int                      `json:"schema_version"`
+	CreatedAt     time.Time                `json:"created_at"`
+	Checksum      string                   `json:"checksum"`
+	Tables        map[string]storage.Table `json:"tables"`
+}
+
+// Result reports what a restore added and how many rows it skipped because
+// the database already had them.
+type Result struct {
+	Mode     string `json:"mode"`
+	Sessions int    `json:"sessions"`
+	Sites    int    `json:"sites"`
+	Apps     int    `json:"apps"`
+	Profiles int    `json:"profiles"`
+	Events   int    `json:"events"`
+	Skipped  int    `json:"skipped"`
+	// Replaced is how many sessions a replace removed from history. They
+	// stay in the event log, logged as deleted.
+	Replaced int `json:"replaced"`
+}
+
+// Create takes a backup of db.
+func Create(db *sql.DB, now time.Time) (*Archive, error) {
+	version, tables, err := storage.DumpTables(db)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := checksum(tables)
+	if err != nil {
+		return nil, err
+	}
+	return &Archive{
+		Format:        Format,
+		Version:       Version,
+		SchemaVersion: version,
+		CreatedAt:     now.UTC(),
+		Checksum:      sum,
+		Tables:        tables,
+	}, nil
+}
+
+// Rows counts the rows of one table.
+func (a *Archive) Rows(table string) int {
+	return len(a.Tables[table].Rows)
+}
+
+// Verify checks that a is a backup this build can read and that its tables
+// match the checksum.
+func (a *Archive) Verify() error {
+	if a.Format != Format {
+		return fmt.Errorf("not a LockIn backup")
+	}
+	if a.Version > Version {
+		return fmt.Errorf("backup version %d is newer than this LockIn understands (%d)", a.Version, Version)
+	}
+	if a.SchemaVersion > storage.SchemaVersion {
+		return fmt.Errorf("backup was taken by a newer LockIn (schema version %d, this build has %d)", a.SchemaVersion, storage.SchemaVersion)
+	}
+
+	sum, err := checksum(a.Tables)
+	if err != nil {
+		return err
+	}
+	if sum != a.Checksum {
+		return fmt.Errorf("backup checksum doesn't match; the file is damaged or was edited")
+	}
+	return nil
+}
+
+// checksum is the SHA-256 of the tables' compact JSON. Map keys are sorted
+// when encoding, so the same tables always give the same sum.
+func checksum(tables map[string]storage.Table) (string, error) {
+	data, err := json.Marshal(tables)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Write writes a as indented JSON.
+func Write(w io.Writer, a *Archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Read reads and verifies an archive. Numbers are kept exactly as written
+// so the checksum sees what Create saw.
+func Read(r io.Reader) (*Archive, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var a Archive
+	err = dec.Decode(&a)
+	if err != nil {
+		if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			return nil, fmt.Errorf("not a LockIn backup")
+		}
+		return nil, fmt.Errorf("invalid backup: %w", err)
+	}
+
+	err = a.Verify()
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Restore loads a into db. The archive's rows are first loaded into a
+// scratch database in the schema they were saved in and migrated from
+// there, so backups from older versions restore like current ones. They
+// are then copied into db in one transaction under new IDs, with their
+// events appended to db's log after a restored event.
+func Restore(db *sql.DB, a *Archive, mode string) (*Result, error) {
+	if mode != ModeMerge && mode != ModeReplace {
+		return nil, fmt.Errorf("unknown restore mode %q (want %s or %s)", mode, ModeMerge, ModeReplace)
+	}
+	err := a.Verify()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "lockin-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	scratch, err := storage.Open(filepath.Join(dir, "restore.db"))
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+
+	err = storage.LoadTables(scratch, a.SchemaVersion, a.Tables)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %w", err)
+	}
+
+	counts, err := storage.RestoreFrom(db, scratch, mode == ModeReplace)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Mode:     mode,
+		Sessions: counts.Sessions,
+		Sites:    counts.Sites,
+		Apps:     counts.Apps,
+		Profiles: counts.Profiles,
+		Events:   counts.Events,
+		Skipped:  counts.Skipped,
+		Replaced: counts.Replaced,
+	}, nil
+}
+
+// DefaultName is the file name backups are saved under unless told
+// otherwise, like lockin-backup-2026-03-10.json.
+func DefaultName(now time.Time) string {
+	return fmt.Sprintf("%s-%s.json", Format, now.Format("2006-01-02"))
+}
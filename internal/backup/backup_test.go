@@ -0,0 +1,381 @@
+package backup
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+var testNow = time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	err = storage.InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	return db
+}
+
+// seed fills db with a little of everything a backup holds.
+func seed(t *testing.T, db *sql.DB) {
+	sessions := []models.Session{
+		{StartTime: testNow.Add(-48 * time.Hour).Unix(), DurationSeconds: 1500, Profile: "work", Status: models.StatusCompleted, Note: "draft <intro> & outline"},
+		{StartTime: testNow.Add(-24 * time.Hour).Unix(), DurationSeconds: 3000, Strict: true, Status: models.StatusAborted, TamperCount: 2},
+		{StartTime: testNow.Add(-10 * time.Minute).Unix(), DurationSeconds: 1500, Active: true},
+	}
+	for _, session := range sessions {
+		if _, err := storage.InsertSession(db, session); err != nil {
+			t.Fatalf("Failed to insert session: %v", err)
+		}
+	}
+	storage.CreateBlockedSite(db, "youtube.com")
+	storage.CreateBlockedSite(db, "reddit.com")
+	storage.CreateBlockedApp(db, "steam")
+	storage.EnsureProfile(db, "work", 3000)
+}
+
+// roundTrip writes a and reads it back as a file would be.
+func roundTrip(t *testing.T, a *Archive) *Archive {
+	var b bytes.Buffer
+	err := Write(&b, a)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	read, err := Read(&b)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return read
+}
+
+// Test Create and Restore - a backup restored into an empty database gives
+// back every row, and sessions that were running come back ended
+func TestBackupRestore(t *testing.T) {
+	src := newTestDB(t)
+	seed(t, src)
+
+	archive, err := Create(src, testNow)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if archive.SchemaVersion != storage.SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", archive.SchemaVersion, storage.SchemaVersion)
+	}
+
+	dst := newTestDB(t)
+	result, err := Restore(dst, roundTrip(t, archive), ModeMerge)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	want := Result{Mode: ModeMerge, Sessions: 3, Sites: 2, Apps: 1, Profiles: 1}
+	if *result != want {
+		t.Errorf("Restore() = %+v, want %+v", *result, want)
+	}
+
+	before, _ := storage.GetAllSessions(src)
+	after, _ := storage.GetAllSessions(dst)
+	if len(after) != len(before) {
+		t.Fatalf("restored %d sessions, want %d", len(after), len(before))
+	}
+	for i := range before {
+		b, a := before[i], after[i]
+		b.ID, a.ID = 0, 0
+		b.Active = false
+		if a != b {
+			t.Errorf("session %d = %+v, want %+v", i, a, b)
+		}
+	}
+
+	profile, err := storage.GetProfileByName(dst, "work")
+	if err != nil || profile.DurationSeconds != 3000 {
+		t.Errorf("profile work = %+v, %v; want a 3000s profile", profile, err)
+	}
+}
+
+// Test Restore modes - merging skips what is already there, replacing
+// starts from nothing
+func TestRestoreModes(t *testing.T) {
+	src := newTestDB(t)
+	seed(t, src)
+	archive, err := Create(src, testNow)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		mode  string
+		setup func(db *sql.DB)
+		want  Result
+		sites int
+	}{
+		{
+			name:  "merge into a copy adds nothing",
+			mode:  ModeMerge,
+			setup: func(db *sql.DB) { seed(t, db) },
+			want:  Result{Mode: ModeMerge, Skipped: 7},
+			sites: 2,
+		},
+		{
+			name: "merge keeps local rows",
+			mode: ModeMerge,
+			setup: func(db *sql.DB) {
+				storage.CreateBlockedSite(db, "youtube.com")
+				storage.CreateBlockedSite(db, "news.example.com")
+			},
+			want:  Result{Mode: ModeMerge, Sessions: 3, Sites: 1, Apps: 1, Profiles: 1, Skipped: 1},
+			sites: 3,
+		},
+		{
+			name: "replace drops local rows",
+			mode: ModeReplace,
+			setup: func(db *sql.DB) {
+				storage.CreateBlockedSite(db, "news.example.com")
+				storage.EnsureProfile(db, "work", 600)
+			},
+			want:  Result{Mode: ModeReplace, Sessions: 3, Sites: 2, Apps: 1, Profiles: 1},
+			sites: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			tt.setup(db)
+
+			result, err := Restore(db, archive, tt.mode)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if *result != tt.want {
+				t.Errorf("Restore() = %+v, want %+v", *result, tt.want)
+			}
+			sites, _ := storage.GetAllBlockedSites(db)
+			if len(sites) != tt.sites {
+				t.Errorf("got %d blocked sites, want %d", len(sites), tt.sites)
+			}
+		})
+	}
+}
+
+// Test Restore and the event log - a restore only appends: what was logged
+// stays, sessions a replace removes are logged as deleted, and the backup's
+// events follow a restored event
+func TestRestoreKeepsLog(t *testing.T) {
+	src := newTestDB(t)
+	session := models.Session{StartTime: testNow.Add(-time.Hour).Unix(), DurationSeconds: 1500}
+	session.Complete()
+	if _, err := storage.RecordSession(src, session, models.NewEvent(models.EventCompleted, testNow, nil)); err != nil {
+		t.Fatalf("Failed to record session: %v", err)
+	}
+	archive, err := Create(src, testNow)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, mode := range []string{ModeMerge, ModeReplace} {
+		t.Run(mode, func(t *testing.T) {
+			db := newTestDB(t)
+			local := models.Session{StartTime: testNow.Add(-3 * time.Hour).Unix(), DurationSeconds: 600}
+			local.Abort(testNow.Add(-170 * time.Minute))
+			localID, err := storage.RecordSession(db, local, models.NewEvent(models.EventAborted, testNow.Add(-170*time.Minute), nil))
+			if err != nil {
+				t.Fatalf("Failed to record session: %v", err)
+			}
+			before, _ := storage.GetSessionEvents(db, storage.EventFilter{})
+
+			result, err := Restore(db, archive, mode)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if replaced := mode == ModeReplace; (result.Replaced == 1) != replaced {
+				t.Errorf("Replaced = %d, want it to count the local session only when replacing", result.Replaced)
+			}
+
+			// Oldest first: GetSessionEvents sorts by time, so go by ID.
+			after, _ := storage.GetSessionEvents(db, storage.EventFilter{})
+			sort.Slice(after, func(i, j int) bool { return after[i].ID < after[j].ID })
+			if len(after) < len(before)+2 {
+				t.Fatalf("events after = %+v, want the %d before plus more", after, len(before))
+			}
+			for i, e := range before {
+				if after[i].ID != e.ID || after[i].Type != e.Type {
+					t.Errorf("event %d = %+v, want %+v kept", i, after[i], e)
+				}
+			}
+			added := after[len(before):]
+			if added[0].Type != models.EventRestored || added[0].SessionID != 0 {
+				t.Errorf("first added event = %+v, want restored", added[0])
+			}
+			var types []string
+			for _, e := range added[1:] {
+				types = append(types, e.Type)
+				if e.Type == models.EventDeleted && e.SessionID != localID {
+					t.Errorf("deleted event = %+v, want it for session %d", e, localID)
+				}
+				if e.Type == models.EventCompleted && e.Payload["restored"] != true {
+					t.Errorf("restored event = %+v, want it marked restored", e)
+				}
+			}
+			want := []string{models.EventCompleted}
+			if mode == ModeReplace {
+				want = []string{models.EventDeleted, models.EventCompleted}
+			}
+			if !slices.Equal(types, want) {
+				t.Errorf("events after the restore = %v, want %v", types, want)
+			}
+
+			audit, err := storage.VerifyAudit(db)
+			if err != nil || !audit.OK() {
+				t.Errorf("VerifyAudit() = %+v, %v; want the log to check out", audit, err)
+			}
+		})
+	}
+}
+
+// Test Restore from an old schema - a backup taken before profiles and
+// session outcomes existed is migrated on the way in
+func TestRestoreOldSchema(t *testing.T) {
+	tables := map[string]storage.Table{
+		"sessions": {
+			Columns: []string{"id", "start_time", "duration_seconds", "active"},
+			Rows:    [][]any{{7, testNow.Add(-time.Hour).Unix(), 1500, 0}},
+		},
+		"blocked_sites": {
+			Columns: []string{"id", "domain"},
+			Rows:    [][]any{{3, "youtube.com"}},
+		},
+		"blocked_apps": {Columns: []string{"id", "process_name"}, Rows: [][]any{}},
+	}
+	sum, err := checksum(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := &Archive{Format: Format, Version: 1, SchemaVersion: 1, CreatedAt: testNow, Checksum: sum, Tables: tables}
+
+	db := newTestDB(t)
+	result, err := Restore(db, roundTrip(t, archive), ModeMerge)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if result.Sessions != 1 || result.Sites != 1 {
+		t.Errorf("Restore() = %+v, want 1 session and 1 site", *result)
+	}
+
+	sessions, _ := storage.GetAllSessions(db)
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	if s := sessions[0]; s.DurationSeconds != 1500 || s.Profile != "" || s.Status != "" {
+		t.Errorf("session = %+v, want 1500s with the migration defaults", s)
+	}
+	// The event log migration backfills a start, filed under the new ID.
+	events, _ := storage.GetSessionEvents(db, storage.EventFilter{SessionID: sessions[0].ID})
+	if result.Events != 1 || len(events) != 1 || events[0].Type != models.EventStarted {
+		t.Errorf("events = %+v, want one backfilled start for session %d", events, sessions[0].ID)
+	}
+}
+
+// Test Read - archives that are damaged, edited or too new are refused
+func TestReadRejects(t *testing.T) {
+	db := newTestDB(t)
+	seed(t, db)
+	archive, err := Create(db, testNow)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	var b bytes.Buffer
+	if err := Write(&b, archive); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	good := b.String()
+
+	edit := func(fn func(m map[string]any)) string {
+		var m map[string]any
+		json.Unmarshal([]byte(good), &m)
+		fn(m)
+		data, _ := json.Marshal(m)
+		return string(data)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "not JSON",
+			data:    "youtube.com\n",
+			wantErr: "not a LockIn backup",
+		},
+		{
+			name:    "other JSON",
+			data:    `{"version": 1, "sites": ["youtube.com"]}`,
+			wantErr: "not a LockIn backup",
+		},
+		{
+			name:    "edited row",
+			data:    strings.Replace(good, `"youtube.com"`, `"example.com"`, 1),
+			wantErr: "checksum doesn't match",
+		},
+		{
+			name:    "truncated",
+			data:    good[:len(good)/2],
+			wantErr: "invalid backup",
+		},
+		{
+			name:    "newer archive",
+			data:    edit(func(m map[string]any) { m["version"] = Version + 1 }),
+			wantErr: "newer than this LockIn understands",
+		},
+		{
+			name:    "newer schema",
+			data:    edit(func(m map[string]any) { m["schema_version"] = storage.SchemaVersion + 1 }),
+			wantErr: "taken by a newer LockIn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Test Restore - a table the schema doesn't have fails the restore and
+// leaves the database alone
+func TestRestoreUnknownTable(t *testing.T) {
+	tables := map[string]storage.Table{
+		"blocked_sites": {Columns: []string{"id", "domain"}, Rows: [][]any{{1, "youtube.com"}}},
+		"schedules":     {Columns: []string{"id"}, Rows: [][]any{{1}}},
+	}
+	sum, _ := checksum(tables)
+	archive := &Archive{Format: Format, Version: Version, SchemaVersion: storage.SchemaVersion, Checksum: sum, Tables: tables}
+
+	db := newTestDB(t)
+	_, err := Restore(db, archive, ModeReplace)
+	if err == nil || !strings.Contains(err.Error(), `unknown table "schedules"`) {
+		t.Fatalf("Restore() error = %v, want an unknown table error", err)
+	}
+	sites, _ := storage.GetAllBlockedSites(db)
+	if len(sites) != 0 {
+		t.Errorf("got %v, want nothing restored", sites)
+	}
+}
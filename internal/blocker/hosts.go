@@ -2,14 +2,16 @@ package blocker
 
 import (
 	"database/sql"
+	"errors"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"github.com/youssef28m/LockIn/internal/storage"
 )
 
-var hostsPath = `C:\Windows\System32\drivers\etc\hosts`
-
 func BlockWebsites(db *sql.DB) error {
 	sites, err := storage.GetAllBlockedSites(db)
 	if err != nil {
@@ -45,7 +47,7 @@ func UnblockWebsites(db *sql.DB) error {
 
 
 func BlockSite(domain string) error {
-	entry := "127.0.0.1    " + domain
+	entry := hostsEntry(domain)
 
 	file, err := os.ReadFile(hostsPath)
 	if err != nil {
@@ -82,6 +84,235 @@ func UnblockSite(domain string) error {
 		}
 	}
 
-	return os.WriteFile(hostsPath, []byte(strings.Join(result, "\n")), 0644)
+	return replaceFile(hostsPath, []byte(strings.Join(result, "\n")))
+
+}
+
+// DefaultRedirectIP is where blocked domains point unless configured
+// otherwise.
+const DefaultRedirectIP = "127.0.0.1"
+
+func hostsEntry(domain string) string {
+	return redirectEntry(DefaultRedirectIP, domain)
+}
+
+func redirectEntry(ip, domain string) string {
+	return ip + "    " + domain
+}
+
+//***********************************************************//
+// Managed block set
+//***********************************************************//
+
+// LockIn keeps the entries it owns between these markers so the whole set
+// can be replaced or removed without touching anything else in the file.
+const (
+	sectionBegin = "# BEGIN LockIn"
+	sectionEnd   = "# END LockIn"
+)
+
+// ApplyBlockSet makes the LockIn section of the hosts file contain exactly
+// domains. The file is only rewritten when the section actually changes.
+func ApplyBlockSet(domains []string) error {
+	return HostsEnforcer{}.Apply(domains)
+}
+
+// ClearBlockSet removes the LockIn section from the hosts file.
+func ClearBlockSet() error {
+	return ApplyBlockSet(nil)
+}
+
+func applyBlockSet(path, ip string, domains []string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	rest := stripSection(string(file))
+	updated := rest
+	if len(domains) > 0 {
+		var b strings.Builder
+		b.WriteString(rest)
+		if rest != "" && !strings.HasSuffix(rest, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString(sectionBegin + "\n")
+		for _, domain := range domains {
+			b.WriteString(redirectEntry(ip, domain) + "\n")
+		}
+		b.WriteString(sectionEnd + "\n")
+		updated = b.String()
+	}
+
+	if updated == string(file) {
+		return nil
+	}
+	return replaceFile(path, []byte(updated))
+}
+
+// createTemp and rename are os.CreateTemp and os.Rename, swapped out by
+// tests.
+var (
+	createTemp = os.CreateTemp
+	rename     = os.Rename
+)
+
+// replaceFile swaps path's contents for data without ever leaving it half
+// written: data goes to a temp file next to it, which gets path's mode and
+// is synced before it is renamed over path.
+//
+// That needs a writable directory and a path that can be renamed over.
+// Under the helper's sandbox /etc is read-only, and in containers
+// /etc/hosts is often a bind mount, so when either is refused the file is
+// rewritten in place instead.
+func replaceFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := createTemp(filepath.Dir(path), "."+filepath.Base(path)+".lockin-*")
+	if cantReplace(err) {
+		return writeInPlace(path, data)
+	}
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = tmp.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	err = rename(tmp.Name(), path)
+	if cantReplace(err) {
+		return writeInPlace(path, data)
+	}
+	if err != nil {
+		return err
+	}
+	// Make the rename itself durable; not every system can sync a
+	// directory, and the file is already in place either way.
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// cantReplace reports whether err means path can't be swapped for a new
+// file, though it may still be writable itself.
+func cantReplace(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) ||
+		errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EXDEV)
+}
+
+// writeInPlace truncates path and writes data into it. A crash part way
+// leaves it half written, so it is only used when replaceFile can't swap
+// the file.
+func writeInPlace(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func stripSection(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+
+	var b strings.Builder
+	inSection := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == sectionBegin:
+			inSection = true
+		case trimmed == sectionEnd:
+			inSection = false
+		case !inSection:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// HostsEnforcer blocks domains by rewriting LockIn's section of the hosts
+// file. It needs write access to the file, so it is only used directly when
+// running as root; otherwise the privileged helper does the writing. Zero
+// fields mean the system hosts file and DefaultRedirectIP.
+type HostsEnforcer struct {
+	Path       string
+	RedirectIP string
+}
+
+func (h HostsEnforcer) Apply(domains []string) error {
+	path, ip := h.Path, h.RedirectIP
+	if path == "" {
+		path = hostsPath
+	}
+	if ip == "" {
+		ip = DefaultRedirectIP
+	}
+	return applyBlockSet(path, ip, domains)
+}
+
+func (h HostsEnforcer) Clear() error { return h.Apply(nil) }
+
+// Blocked returns the domains in LockIn's section of the hosts file.
+func (h HostsEnforcer) Blocked() ([]string, error) {
+	path := h.Path
+	if path == "" {
+		path = hostsPath
+	}
+	return readBlockSet(path)
+}
+
+func readBlockSet(path string) ([]string, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var domains []string
+	inSection := false
+	for _, line := range strings.Split(string(file), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == sectionBegin:
+			inSection = true
+		case trimmed == sectionEnd:
+			inSection = false
+		case inSection:
+			fields := strings.Fields(trimmed)
+			if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+				domains = append(domains, fields[1:]...)
+			}
+		}
+	}
+	return domains, nil
+}
 
+// ApplyRedirect applies domains pointing at ip instead of h.RedirectIP.
+func (h HostsEnforcer) ApplyRedirect(ip string, domains []string) error {
+	h.RedirectIP = ip
+	return h.Apply(domains)
 }
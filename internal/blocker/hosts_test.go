@@ -3,7 +3,9 @@ package blocker
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/youssef28m/LockIn/internal/validator"
@@ -182,3 +184,196 @@ func BenchmarkUnblockSite(b *testing.B) {
 		UnblockSite(domain)
 	}
 }
+
+// Test ApplyBlockSet - writes a marked section and replaces it on reapply
+func TestApplyBlockSet(t *testing.T) {
+	tempHostsPath := "test_hosts_set.txt"
+	defer os.Remove(tempHostsPath)
+
+	original := "127.0.0.1 localhost\n::1 localhost\n"
+	os.WriteFile(tempHostsPath, []byte(original), 0644)
+
+	oldHostsPath := hostsPath
+	hostsPath = tempHostsPath
+	defer func() { hostsPath = oldHostsPath }()
+
+	err := ApplyBlockSet([]string{"a.example.com", "b.example.com"})
+	if err != nil {
+		t.Fatalf("Failed to apply block set: %v", err)
+	}
+
+	content, _ := os.ReadFile(tempHostsPath)
+	if !strings.HasPrefix(string(content), original) {
+		t.Errorf("Existing entries were not preserved:\n%s", content)
+	}
+	if !strings.Contains(string(content), "127.0.0.1    a.example.com") {
+		t.Errorf("Expected a.example.com to be blocked:\n%s", content)
+	}
+
+	// Reapplying replaces the section rather than appending a second one
+	err = ApplyBlockSet([]string{"b.example.com"})
+	if err != nil {
+		t.Fatalf("Failed to reapply block set: %v", err)
+	}
+
+	content, _ = os.ReadFile(tempHostsPath)
+	if strings.Contains(string(content), "a.example.com") {
+		t.Errorf("a.example.com should have been dropped:\n%s", content)
+	}
+	if strings.Count(string(content), sectionBegin) != 1 {
+		t.Errorf("Expected exactly one LockIn section:\n%s", content)
+	}
+}
+
+// Test replaceFile - the hosts file is swapped whole, keeps its mode and
+// leaves no temp file behind
+func TestReplaceFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts")
+	os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0604)
+	os.Chmod(path, 0604)
+
+	err := applyBlockSet(path, DefaultRedirectIP, []string{"a.example.com"})
+	if err != nil {
+		t.Fatalf("applyBlockSet() error = %v", err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0604 {
+		t.Errorf("mode = %v, want -rw----r--", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want just hosts", len(entries))
+	}
+
+	err = applyBlockSet(filepath.Join(dir, "missing"), DefaultRedirectIP, []string{"a.example.com"})
+	if err == nil {
+		t.Error("applyBlockSet() on a missing file succeeded")
+	}
+}
+
+// Test replaceFile fallback - when the directory is read-only or the file
+// can't be renamed over, as under the helper's sandbox or a bind-mounted
+// /etc/hosts, the file is rewritten in place
+func TestReplaceFileInPlace(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+	}{
+		{
+			name: "read-only directory",
+			setup: func(t *testing.T, dir string) {
+				if os.Geteuid() == 0 {
+					t.Skip("root can write to a read-only directory")
+				}
+				os.Chmod(dir, 0555)
+				t.Cleanup(func() { os.Chmod(dir, 0755) })
+			},
+		},
+		{
+			name: "read-only file system",
+			setup: func(t *testing.T, dir string) {
+				createTemp = func(dir, pattern string) (*os.File, error) {
+					return nil, &os.PathError{Op: "createtemp", Path: dir, Err: syscall.EROFS}
+				}
+				t.Cleanup(func() { createTemp = os.CreateTemp })
+			},
+		},
+		{
+			name: "rename refused",
+			setup: func(t *testing.T, dir string) {
+				rename = func(from, to string) error {
+					return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EBUSY}
+				}
+				t.Cleanup(func() { rename = os.Rename })
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "hosts")
+			original := "127.0.0.1 localhost\n"
+			os.WriteFile(path, []byte(original), 0644)
+			tt.setup(t, dir)
+
+			enforcer := HostsEnforcer{Path: path}
+			err := enforcer.Apply([]string{"a.example.com"})
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			content, _ := os.ReadFile(path)
+			if !strings.Contains(string(content), "127.0.0.1    a.example.com") {
+				t.Errorf("a.example.com wasn't blocked:\n%s", content)
+			}
+
+			err = enforcer.Clear()
+			if err != nil {
+				t.Fatalf("Clear() error = %v", err)
+			}
+			content, _ = os.ReadFile(path)
+			if string(content) != original {
+				t.Errorf("hosts file wasn't restored:\n%s", content)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("directory holds %d files, want just hosts", len(entries))
+			}
+		})
+	}
+}
+
+// Test ClearBlockSet - restores the file to what it was before
+func TestClearBlockSet(t *testing.T) {
+	tempHostsPath := "test_hosts_clear.txt"
+	defer os.Remove(tempHostsPath)
+
+	original := "127.0.0.1 localhost\n"
+	os.WriteFile(tempHostsPath, []byte(original), 0644)
+
+	oldHostsPath := hostsPath
+	hostsPath = tempHostsPath
+	defer func() { hostsPath = oldHostsPath }()
+
+	ApplyBlockSet([]string{"clear.example.com"})
+	err := ClearBlockSet()
+	if err != nil {
+		t.Fatalf("Failed to clear block set: %v", err)
+	}
+
+	content, _ := os.ReadFile(tempHostsPath)
+	if string(content) != original {
+		t.Errorf("Expected hosts file to be restored, got:\n%s", content)
+	}
+}
+
+// Test HostsEnforcer - a configured path and redirect address are used
+func TestHostsEnforcerSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0644)
+
+	enforcer := HostsEnforcer{Path: path, RedirectIP: "0.0.0.0"}
+	err := enforcer.Apply([]string{"a.example.com"})
+	if err != nil {
+		t.Fatalf("Failed to apply block set: %v", err)
+	}
+
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "0.0.0.0    a.example.com") {
+		t.Errorf("hosts file doesn't redirect to 0.0.0.0:\n%s", content)
+	}
+	blocked, err := enforcer.Blocked()
+	if err != nil || len(blocked) != 1 || blocked[0] != "a.example.com" {
+		t.Errorf("Blocked() = %v, %v; want [a.example.com]", blocked, err)
+	}
+
+	err = enforcer.ApplyRedirect("::1", []string{"a.example.com"})
+	if err != nil {
+		t.Fatalf("Failed to apply block set: %v", err)
+	}
+	content, _ = os.ReadFile(path)
+	if !strings.Contains(string(content), "::1    a.example.com") || strings.Contains(string(content), "0.0.0.0") {
+		t.Errorf("hosts file doesn't redirect to ::1 only:\n%s", content)
+	}
+}
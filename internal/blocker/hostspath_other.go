@@ -0,0 +1,5 @@
+//go:build !windows
+
+package blocker
+
+var hostsPath = "/etc/hosts"
@@ -0,0 +1,3 @@
+package blocker
+
+var hostsPath = `C:\Windows\System32\drivers\etc\hosts`
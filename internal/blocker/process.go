@@ -1,3 +1,103 @@
 package blocker
 
-// TODO: Add process blocking functionality
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// procRoot is where process information is read from. Tests point it at a
+// fake tree.
+var procRoot = "/proc"
+
+// Process is one running process as seen in /proc.
+type Process struct {
+	PID int
+	// Name is the kernel's name for the process (/proc/<pid>/comm), which is
+	// cut off after 15 characters.
+	Name string
+	// Command is the base name of argv[0], which isn't truncated.
+	Command string
+}
+
+// commLimit is how many characters of a name the kernel keeps in comm.
+const commLimit = 15
+
+// Matches reports whether p is an instance of the named app. Names compare
+// case-insensitively, and long names still match the truncated comm.
+func (p Process) Matches(name string) bool {
+	if strings.EqualFold(p.Name, name) || strings.EqualFold(p.Command, name) {
+		return true
+	}
+	return len(name) > commLimit && strings.EqualFold(p.Name, name[:commLimit])
+}
+
+// RunningProcesses lists every process visible in /proc. Processes that
+// exit while being read are skipped.
+func RunningProcesses() ([]Process, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		comm, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+
+		process := Process{PID: pid, Name: strings.TrimSpace(string(comm))}
+
+		// Kernel threads have an empty cmdline; they keep just the comm name.
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline"))
+		if err == nil && len(cmdline) > 0 {
+			argv0, _, _ := bytes.Cut(cmdline, []byte{0})
+			process.Command = filepath.Base(string(argv0))
+		}
+
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// ProcessNames returns the distinct names of running processes, sorted,
+// preferring the untruncated command name where there is one.
+func ProcessNames(processes []Process) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range processes {
+		name := p.Name
+		if p.Command != "" && strings.HasPrefix(p.Command, p.Name) {
+			name = p.Command
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	return names
+}
+
+// IsRunning reports whether any of processes is an instance of name.
+func IsRunning(processes []Process, name string) bool {
+	for _, p := range processes {
+		if p.Matches(name) {
+			return true
+		}
+	}
+	return false
+}
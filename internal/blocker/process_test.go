@@ -0,0 +1,74 @@
+package blocker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFakeProc builds a /proc-like tree with the given comm and cmdline
+func writeFakeProc(t *testing.T, procs map[string][2]string) string {
+	root := t.TempDir()
+	for pid, p := range procs {
+		dir := filepath.Join(root, pid)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "comm"), []byte(p[0]+"\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "cmdline"), []byte(p[1]), 0644)
+	}
+	// Non-process entries must be ignored
+	os.WriteFile(filepath.Join(root, "uptime"), []byte("1 1"), 0644)
+	os.MkdirAll(filepath.Join(root, "sys"), 0755)
+	return root
+}
+
+// Test RunningProcesses - reads comm and argv[0] from a fake /proc
+func TestRunningProcesses(t *testing.T) {
+	root := writeFakeProc(t, map[string][2]string{
+		"1":   {"systemd", "/sbin/init\x00splash\x00"},
+		"42":  {"steam", "/usr/bin/steam\x00-silent\x00"},
+		"77":  {"kworker/0:1", ""},
+		"100": {"signal-desktop-", "/opt/Signal/signal-desktop-beta\x00"},
+	})
+
+	oldProcRoot := procRoot
+	procRoot = root
+	defer func() { procRoot = oldProcRoot }()
+
+	processes, err := RunningProcesses()
+	if err != nil {
+		t.Fatalf("Failed to list processes: %v", err)
+	}
+	if len(processes) != 4 {
+		t.Fatalf("Expected 4 processes, got %d: %+v", len(processes), processes)
+	}
+
+	names := ProcessNames(processes)
+	expected := []string{"kworker/0:1", "signal-desktop-beta", "steam", "systemd"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("ProcessNames = %v, expected %v", names, expected)
+	}
+}
+
+// Test Process.Matches - case, argv[0] and truncated comm names
+func TestProcessMatches(t *testing.T) {
+	p := Process{PID: 100, Name: "signal-desktop-", Command: "signal-desktop-beta"}
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"signal-desktop-beta", true},
+		{"Signal-Desktop-Beta", true},
+		{"signal-desktop-", true},
+		{"signal-desktop-alpha", true}, // indistinguishable by comm alone
+		{"signal", false},
+		{"steam", false},
+	}
+
+	for _, test := range tests {
+		if got := p.Matches(test.name); got != test.expected {
+			t.Errorf("Matches(%q) = %v, expected %v", test.name, got, test.expected)
+		}
+	}
+}
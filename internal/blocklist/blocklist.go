@@ -0,0 +1,297 @@
+// Package blocklist reads and writes shareable block lists: LockIn's own
+// JSON, plain text with one entry per line, and the hosts-file format used
+// by public lists such as StevenBlack's.
+package blocklist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/youssef28m/LockIn/internal/validator"
+)
+
+// Formats a block list can be read or written in.
+const (
+	FormatAuto  = "auto"
+	FormatJSON  = "json"
+	FormatText  = "text"
+	FormatHosts = "hosts"
+)
+
+// Version is the version written in JSON exports.
+const Version = 1
+
+// List is a block list. In JSON it carries both sites and apps; the text
+// and hosts formats hold one kind of entry only.
+type List struct {
+	Version int      `json:"version"`
+	Sites   []string `json:"sites"`
+	Apps    []string `json:"apps,omitempty"`
+}
+
+// Entry is one raw entry from a file and where it was found, like
+// "line 12" or "sites[3]".
+type Entry struct {
+	Pos   string
+	Value string
+}
+
+// Document is a parsed file before its entries are checked.
+type Document struct {
+	Sites []Entry
+	Apps  []Entry
+}
+
+// Rejected is an entry left out of an import and why.
+type Rejected struct {
+	Pos    string `json:"pos"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+func (r Rejected) String() string {
+	return fmt.Sprintf("%s: %q: %s", r.Pos, r.Value, r.Reason)
+}
+
+//***********************************************************//
+// Reading
+//***********************************************************//
+
+// Detect guesses the format of a file from its name, then its contents.
+func Detect(name string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".hosts":
+		return FormatHosts
+	}
+	if filepath.Base(name) == "hosts" {
+		return FormatHosts
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		return FormatJSON
+	}
+
+	// A file is a hosts file if its first entry starts with an address.
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 1 && isAddress(fields[0]) {
+			return FormatHosts
+		}
+		break
+	}
+	return FormatText
+}
+
+// Parse reads a file in format, which must not be FormatAuto. Text files
+// are read as sites.
+func Parse(data []byte, format string) (Document, error) {
+	switch format {
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatText:
+		return parseText(data)
+	case FormatHosts:
+		return parseHosts(data)
+	}
+	return Document{}, fmt.Errorf("unknown format %q (want json, text or hosts)", format)
+}
+
+func parseJSON(data []byte) (Document, error) {
+	var list List
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return Document{}, fmt.Errorf("invalid JSON block list: %w", err)
+	}
+	if list.Version > Version {
+		return Document{}, fmt.Errorf("block list version %d is newer than this LockIn understands (%d)", list.Version, Version)
+	}
+
+	return list.Document(), nil
+}
+
+// Document returns the list's entries unchecked, placed by their index.
+func (l List) Document() Document {
+	var doc Document
+	for i, site := range l.Sites {
+		doc.Sites = append(doc.Sites, Entry{Pos: fmt.Sprintf("sites[%d]", i), Value: site})
+	}
+	for i, app := range l.Apps {
+		doc.Apps = append(doc.Apps, Entry{Pos: fmt.Sprintf("apps[%d]", i), Value: app})
+	}
+	return doc
+}
+
+func parseText(data []byte) (Document, error) {
+	var doc Document
+	err := eachLine(data, func(n int, line string) {
+		line = strings.TrimSpace(stripComment(line))
+		if line != "" {
+			doc.Sites = append(doc.Sites, Entry{Pos: fmt.Sprintf("line %d", n), Value: line})
+		}
+	})
+	return doc, err
+}
+
+// localNames are the entries every hosts file starts with. They aren't
+// blocks, so they are skipped rather than rejected.
+var localNames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+	"0.0.0.0":               true,
+}
+
+func parseHosts(data []byte) (Document, error) {
+	var doc Document
+	err := eachLine(data, func(n int, line string) {
+		fields := strings.Fields(stripComment(line))
+		if len(fields) == 0 {
+			return
+		}
+		pos := fmt.Sprintf("line %d", n)
+		if !isAddress(fields[0]) || len(fields) == 1 {
+			// Keep the line so it is reported rather than dropped.
+			doc.Sites = append(doc.Sites, Entry{Pos: pos, Value: strings.Join(fields, " ")})
+			return
+		}
+		for _, name := range fields[1:] {
+			if !localNames[strings.ToLower(name)] {
+				doc.Sites = append(doc.Sites, Entry{Pos: pos, Value: name})
+			}
+		}
+	})
+	return doc, err
+}
+
+// isAddress accepts IP addresses, with a zone as in fe80::1%lo0.
+func isAddress(s string) bool {
+	if i := strings.IndexByte(s, '%'); i >= 0 {
+		s = s[:i]
+	}
+	return net.ParseIP(s) != nil
+}
+
+func eachLine(data []byte, fn func(n int, line string)) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	n := 0
+	for scanner.Scan() {
+		n++
+		fn(n, scanner.Text())
+	}
+	return scanner.Err()
+}
+
+func stripComment(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+//***********************************************************//
+// Checking
+//***********************************************************//
+
+// Check normalizes a document's entries the way the block lists store
+// them, keeps the first of any duplicates, and returns the ones that fail
+// validation separately.
+func Check(doc Document) (List, []Rejected) {
+	list := List{Version: Version}
+	var rejected []Rejected
+
+	seen := make(map[string]bool)
+	for _, entry := range doc.Sites {
+		domain := NormalizeSite(entry.Value)
+		if !validator.IsValidDomain(domain) {
+			rejected = append(rejected, Rejected{Pos: entry.Pos, Value: entry.Value, Reason: "invalid domain"})
+			continue
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			list.Sites = append(list.Sites, domain)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for _, entry := range doc.Apps {
+		name := strings.TrimSpace(entry.Value)
+		if !validator.IsValidProcessName(name) {
+			rejected = append(rejected, Rejected{Pos: entry.Pos, Value: entry.Value, Reason: "invalid process name"})
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			list.Apps = append(list.Apps, name)
+		}
+	}
+
+	return list, rejected
+}
+
+// NormalizeSite is how a domain is stored: trimmed and lowercased.
+func NormalizeSite(domain string) string {
+	return strings.ToLower(strings.TrimSpace(domain))
+}
+
+//***********************************************************//
+// Writing
+//***********************************************************//
+
+// WriteJSON writes list with both its sites and apps.
+func WriteJSON(w io.Writer, list List) error {
+	list.Version = Version
+	if list.Sites == nil {
+		list.Sites = []string{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// WriteText writes one entry per line.
+func WriteText(w io.Writer, entries []string) error {
+	for _, entry := range entries {
+		_, err := fmt.Fprintln(w, entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHosts writes domains as hosts-file lines pointing at ip.
+func WriteHosts(w io.Writer, domains []string, ip string) error {
+	_, err := fmt.Fprintln(w, "# Block list exported from LockIn")
+	if err != nil {
+		return err
+	}
+	for _, domain := range domains {
+		_, err := fmt.Fprintf(w, "%s %s\n", ip, domain)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package blocklist
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Test Detect - formats are guessed from the name, then the contents
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		want string
+	}{
+		{"json extension", "list.json", "", FormatJSON},
+		{"hosts extension", "ads.hosts", "", FormatHosts},
+		{"system hosts", "/etc/hosts", "", FormatHosts},
+		{"json content", "-", "  {\"sites\": []}", FormatJSON},
+		{"hosts content", "list.txt", "# comment\n\n0.0.0.0 example.com\n", FormatHosts},
+		{"text content", "list.txt", "# comment\nexample.com\n", FormatText},
+		{"empty", "-", "", FormatText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.file, []byte(tt.data)); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test hosts import - a public hosts list keeps its blocks, skips the
+// local entries and rejects what isn't a domain
+func TestParseHostsFile(t *testing.T) {
+	data, err := os.ReadFile("testdata/stevenblack.hosts")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	doc, err := Parse(data, Detect("stevenblack.hosts", data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	list, rejected := Check(doc)
+
+	wantSites := []string{
+		"ck.getcookiestxt.com",
+		"eu1.clevertap-prod.com",
+		"wizhumpgyros.com",
+		"coccyxwickimp.com",
+		"webmail-who-int.000webhostapp.com",
+		"010sec.com",
+	}
+	if !reflect.DeepEqual(list.Sites, wantSites) {
+		t.Errorf("Sites = %q, want %q", list.Sites, wantSites)
+	}
+
+	wantRejected := []Rejected{
+		{Pos: "line 38", Value: "ad_server.example.com", Reason: "invalid domain"},
+		{Pos: "line 39", Value: "not-a-hosts-line", Reason: "invalid domain"},
+	}
+	if !reflect.DeepEqual(rejected, wantRejected) {
+		t.Errorf("rejected = %+v, want %+v", rejected, wantRejected)
+	}
+}
+
+// Test Check - entries are normalized, deduped and validated per kind
+func TestCheck(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"version": 1,
+		"sites": ["YouTube.com", " reddit.com ", "youtube.com", "http://evil.com"],
+		"apps": ["steam", "steam", "rm -rf /"]
+	}`), FormatJSON)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	list, rejected := Check(doc)
+	if want := []string{"youtube.com", "reddit.com"}; !reflect.DeepEqual(list.Sites, want) {
+		t.Errorf("Sites = %q, want %q", list.Sites, want)
+	}
+	if want := []string{"steam"}; !reflect.DeepEqual(list.Apps, want) {
+		t.Errorf("Apps = %q, want %q", list.Apps, want)
+	}
+	wantRejected := []Rejected{
+		{Pos: "sites[3]", Value: "http://evil.com", Reason: "invalid domain"},
+		{Pos: "apps[2]", Value: "rm -rf /", Reason: "invalid process name"},
+	}
+	if !reflect.DeepEqual(rejected, wantRejected) {
+		t.Errorf("rejected = %+v, want %+v", rejected, wantRejected)
+	}
+}
+
+// Test Parse errors - broken files and newer versions are refused
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"broken json", "{", FormatJSON},
+		{"newer version", `{"version": 2, "sites": []}`, FormatJSON},
+		{"unknown format", "", "csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), tt.format)
+			if err == nil {
+				t.Error("Parse() succeeded, want an error")
+			}
+		})
+	}
+}
+
+// Test round trip - what is written reads back the same in every format
+func TestRoundTrip(t *testing.T) {
+	list := List{Version: Version, Sites: []string{"youtube.com", "reddit.com"}, Apps: []string{"steam"}}
+
+	tests := []struct {
+		format string
+		write  func(b *bytes.Buffer) error
+		want   List
+	}{
+		{FormatJSON, func(b *bytes.Buffer) error { return WriteJSON(b, list) }, list},
+		{FormatText, func(b *bytes.Buffer) error { return WriteText(b, list.Sites) },
+			List{Version: Version, Sites: list.Sites}},
+		{FormatHosts, func(b *bytes.Buffer) error { return WriteHosts(b, list.Sites, "0.0.0.0") },
+			List{Version: Version, Sites: list.Sites}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b bytes.Buffer
+			err := tt.write(&b)
+			if err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if got := Detect("-", b.Bytes()); got != tt.format {
+				t.Errorf("Detect() = %q, want %q", got, tt.format)
+			}
+
+			doc, err := Parse(b.Bytes(), tt.format)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, rejected := Check(doc)
+			if len(rejected) > 0 || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read back %+v (rejected %v), want %+v", got, rejected, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+func init() {
+	register(command{
+		name:    "audit",
+		summary: "check the session event log hasn't been edited: audit verify",
+		run:     runAudit,
+	})
+}
+
+func runAudit(env *Env, args []string) error {
+	_, args, err := subcommand(env, "audit", []string{"verify"}, args)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(env, "audit verify")
+	format := outputFlag(fs)
+	err = fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(env.Stderr, "Usage: lockin audit verify [-o format]")
+		return errUsage
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	report, err := svc.VerifyAudit()
+	if err != nil {
+		return err
+	}
+
+	out := AuditOutput{
+		Version:   OutputVersion,
+		OK:        report.OK(),
+		Events:    report.Events,
+		Problem:   report.Problem,
+		EventID:   report.EventID,
+		SessionID: report.SessionID,
+	}
+	err = render(env.Stdout, *format, out, func() error {
+		if report.OK() {
+			fmt.Fprintf(env.Stdout, "Audit log OK: %s checked\n", plural(report.Events, "event"))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return errors.New("audit log check failed: " + report.Problem)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/youssef28m/LockIn/internal/backup"
+)
+
+func init() {
+	register(command{
+		name:    "backup",
+		summary: "save everything to a portable archive: backup [file|-]",
+		run:     runBackup,
+	})
+	register(command{
+		name:    "restore",
+		summary: "load a backup, merging or replacing: restore [--replace] <file|->",
+		run:     runRestore,
+	})
+}
+
+func runBackup(env *Env, args []string) error {
+	fs := newFlagSet(env, "backup")
+	force := fs.Bool("force", false, "overwrite the file if it exists")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	var name string
+	switch len(positional) {
+	case 0:
+		name = backup.DefaultName(env.Now())
+	case 1:
+		name = positional[0]
+	default:
+		fmt.Fprintln(env.Stderr, "Usage: lockin backup [--force] [file|-]")
+		return errUsage
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	archive, err := svc.Backup()
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	err = backup.Write(&b, archive)
+	if err != nil {
+		return err
+	}
+	if name == "-" {
+		_, err = env.Stdout.Write(b.Bytes())
+		return err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	// Backups hold the whole history, so only the user may read them.
+	f, err := os.OpenFile(name, flags, 0600)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", name)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "Backed up %s to %s\n", archiveSummary(archive), name)
+	return nil
+}
+
+func runRestore(env *Env, args []string) error {
+	fs := newFlagSet(env, "restore")
+	replace := fs.Bool("replace", false, "delete sessions, block lists and profiles first instead of merging; the event log is kept")
+	output := outputFlag(fs)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(env.Stderr, "Usage: lockin restore [--replace] <file|->")
+		return errUsage
+	}
+
+	name := positional[0]
+	var r io.Reader = env.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	archive, err := backup.Read(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	mode := backup.ModeMerge
+	if *replace {
+		mode = backup.ModeReplace
+	}
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	result, err := svc.Restore(archive, mode)
+	if err != nil {
+		return err
+	}
+
+	return render(env.Stdout, *output, restoreOutput(result, archive), func() error {
+		verb := "Merged"
+		if result.Mode == backup.ModeReplace {
+			verb = "Replaced everything with"
+		}
+		fmt.Fprintf(env.Stdout, "%s the backup from %s: added %s, %s, %s and %s",
+			verb, archive.CreatedAt.In(env.Now().Location()).Format("2006-01-02 15:04"),
+			plural(result.Sessions, "session"), plural(result.Sites, "site"),
+			plural(result.Apps, "app"), plural(result.Profiles, "profile"))
+		if result.Skipped > 0 {
+			fmt.Fprintf(env.Stdout, " (%d already there)", result.Skipped)
+		}
+		fmt.Fprintln(env.Stdout)
+		if result.Replaced > 0 {
+			fmt.Fprintf(env.Stdout, "Removed %s from history (still in the event log, marked deleted)\n", plural(result.Replaced, "earlier session"))
+		}
+		return nil
+	})
+}
+
+// archiveSummary counts what a backup holds, like "12 sessions, 3 sites, 1
+// app and 2 profiles".
+func archiveSummary(a *backup.Archive) string {
+	return fmt.Sprintf("%s, %s, %s and %s",
+		plural(a.Rows("sessions"), "session"), plural(a.Rows("blocked_sites"), "site"),
+		plural(a.Rows("blocked_apps"), "app"), plural(a.Rows("profiles"), "profile"))
+}
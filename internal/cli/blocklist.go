@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+)
+
+func init() {
+	register(command{
+		name:    "sites",
+		summary: "manage blocked websites: sites add|rm|ls",
+		run:     runSites,
+	})
+	register(command{
+		name:    "apps",
+		summary: "manage blocked applications: apps add|rm|ls",
+		run:     runApps,
+	})
+}
+
+var listVerbs = []string{"add", "rm", "ls"}
+
+func runSites(env *Env, args []string) error {
+	verb, args, err := subcommand(env, "sites", listVerbs, args)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(env, "sites "+verb)
+	format := outputFlag(fs)
+	args, err = parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if verb != "ls" && len(args) == 0 {
+		fmt.Fprintf(env.Stderr, "Usage: lockin sites %s <domain>...\n", verb)
+		return errUsage
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "add":
+		for _, domain := range args {
+			err := svc.AddBlockedSite(domain)
+			if err != nil {
+				return fmt.Errorf("%s: %w", domain, err)
+			}
+			fmt.Fprintf(env.Stdout, "Blocked %s\n", domain)
+		}
+	case "rm":
+		for _, domain := range args {
+			err := svc.RemoveBlockedSite(domain)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(env.Stdout, "Unblocked %s\n", domain)
+		}
+	case "ls":
+		sites, err := svc.ListBlockedSites()
+		if err != nil {
+			return err
+		}
+		return render(env.Stdout, *format, sitesOutput(sites), func() error {
+			for _, site := range sites {
+				fmt.Fprintln(env.Stdout, site.Domain)
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+func runApps(env *Env, args []string) error {
+	verb, args, err := subcommand(env, "apps", listVerbs, args)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(env, "apps "+verb)
+	format := outputFlag(fs)
+	args, err = parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if verb != "ls" && len(args) == 0 {
+		fmt.Fprintf(env.Stderr, "Usage: lockin apps %s <process-name>...\n", verb)
+		return errUsage
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "add":
+		for _, name := range args {
+			err := svc.AddBlockedApp(name)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			fmt.Fprintf(env.Stdout, "Blocked %s\n", name)
+		}
+	case "rm":
+		for _, name := range args {
+			err := svc.RemoveBlockedApp(name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(env.Stdout, "Unblocked %s\n", name)
+		}
+	case "ls":
+		apps, err := svc.ListBlockedApps()
+		if err != nil {
+			return err
+		}
+		return render(env.Stdout, *format, appsOutput(apps), func() error {
+			for _, app := range apps {
+				fmt.Fprintln(env.Stdout, app.ProcessName)
+			}
+			return nil
+		})
+	}
+	return nil
+}
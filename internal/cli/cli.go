@@ -0,0 +1,133 @@
+// Package cli implements LockIn's non-interactive subcommands. Running
+// lockin without arguments opens the TUI instead.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/config"
+	"github.com/youssef28m/LockIn/internal/daemon"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+// Env carries what a command needs from the outside world so tests can
+// supply their own.
+type Env struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// Connect returns the service commands operate on. It is only called
+	// by commands that need one.
+	Connect func() (service.Service, error)
+	Now     func() time.Time
+	// ConfigPath is the config file commands read by default.
+	ConfigPath string
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(env *Env, args []string) error
+}
+
+var commands = map[string]command{}
+
+func register(cmd command) { commands[cmd.name] = cmd }
+
+// errUsage is returned after a command has already printed its usage.
+var errUsage = errors.New("usage")
+
+// Run executes the subcommand named by args[0] and returns the exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	return run(&Env{Stdin: os.Stdin, Stdout: stdout, Stderr: stderr, Connect: daemon.Connect, Now: time.Now, ConfigPath: config.Path()}, args)
+}
+
+func run(env *Env, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(env.Stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(env.Stderr, "lockin: unknown command %q\n\n", args[0])
+		usage(env.Stderr)
+		return 2
+	}
+
+	err := cmd.run(env, args[1:])
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "lockin %s: %v\n", cmd.name, err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: lockin [command] [flags]")
+	fmt.Fprintln(w, "\nWith no command, lockin opens the interactive UI.")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-18s %s\n", name, commands[name].summary)
+	}
+}
+
+// newFlagSet returns a flag set that reports errors to env instead of
+// exiting the process.
+func newFlagSet(env *Env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("lockin "+name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	return fs
+}
+
+// parseInterspersed parses flags that may come before, between or after
+// positional arguments, so both "start --strict 50m" and "start 50m
+// --strict" work. It returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// subcommand splits "sites add x" style arguments and reports unknown verbs.
+func subcommand(env *Env, name string, verbs []string, args []string) (string, []string, error) {
+	if len(args) == 0 {
+		fmt.Fprintf(env.Stderr, "Usage: lockin %s %s\n", name, strings.Join(verbs, "|"))
+		return "", nil, errUsage
+	}
+	for _, verb := range verbs {
+		if args[0] == verb {
+			return verb, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown subcommand %q (want %s)", args[0], strings.Join(verbs, ", "))
+}
@@ -0,0 +1,658 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// newTestEnv returns an Env backed by a fresh database in a temp dir
+func newTestEnv(t *testing.T) (*Env, *bytes.Buffer, *bytes.Buffer) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = storage.InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	svc := service.NewLocal(db)
+	env := &Env{
+		Stdout:  stdout,
+		Stderr:  stderr,
+		Connect: func() (service.Service, error) { return svc, nil },
+		Now:     time.Now,
+	}
+	return env, stdout, stderr
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      [][]string
+		args       []string
+		wantCode   int
+		wantOut    []string
+		wantErr    []string
+		notWantOut []string
+	}{
+		{
+			name:    "no args prints usage",
+			args:    []string{"help"},
+			wantOut: []string{"Usage: lockin", "start", "sites"},
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"frobnicate"},
+			wantCode: 2,
+			wantErr:  []string{`unknown command "frobnicate"`},
+		},
+		{
+			name:    "start with duration",
+			args:    []string{"start", "50m"},
+			wantOut: []string{"Started 50m session", "lockind isn't running"},
+		},
+		{
+			name:    "start with trailing profile flag",
+			args:    []string{"start", "1h30m", "--profile", "work"},
+			wantOut: []string{"Started 1h30m session"},
+		},
+		{
+			name:    "start with a break",
+			args:    []string{"start", "25m", "--break", "5"},
+			wantOut: []string{"Started 25m session", "Then a 5m break until"},
+		},
+		{
+			name:     "break too long",
+			args:     []string{"start", "25m", "--break", "3h"},
+			wantCode: 1,
+			wantErr:  []string{"break can't last longer than 2h"},
+		},
+		{
+			name:    "bare number is minutes",
+			args:    []string{"start", "25"},
+			wantOut: []string{"Started 25m session"},
+		},
+		{
+			name:    "events of a stopped session",
+			setup:   [][]string{{"start", "25m"}, {"stop"}},
+			args:    []string{"events"},
+			wantOut: []string{"started", "duration_seconds=1500", "aborted"},
+		},
+		{
+			name:    "audit of an untouched log",
+			setup:   [][]string{{"start", "25m"}, {"stop"}},
+			args:    []string{"audit", "verify"},
+			wantOut: []string{"Audit log OK: 2 events checked"},
+		},
+		{
+			name:     "audit without verify",
+			args:     []string{"audit"},
+			wantCode: 2,
+			wantErr:  []string{"Usage: lockin audit verify"},
+		},
+		{
+			name:    "extend",
+			setup:   [][]string{{"start", "25m"}},
+			args:    []string{"extend", "20m"},
+			wantOut: []string{"Added 20m; the session now ends at"},
+		},
+		{
+			name:    "shorten",
+			setup:   [][]string{{"start", "25m"}},
+			args:    []string{"extend", "--shorten", "10m"},
+			wantOut: []string{"Took off 10m"},
+		},
+		{
+			name:    "extend strict",
+			setup:   [][]string{{"start", "--strict", "25m"}},
+			args:    []string{"extend", "5"},
+			wantOut: []string{"Added 5m"},
+		},
+		{
+			name:     "shorten strict",
+			setup:    [][]string{{"start", "--strict", "25m"}},
+			args:     []string{"extend", "10m", "--shorten"},
+			wantCode: 1,
+			wantErr:  []string{"can be extended but not shortened"},
+		},
+		{
+			name:     "shorten past the end",
+			setup:    [][]string{{"start", "25m"}},
+			args:     []string{"extend", "--shorten", "1h"},
+			wantCode: 1,
+			wantErr:  []string{"that would end the session"},
+		},
+		{
+			name:     "extend with nothing running",
+			args:     []string{"extend", "20m"},
+			wantCode: 1,
+			wantErr:  []string{"no active session"},
+		},
+		{
+			name:     "extend without duration",
+			args:     []string{"extend"},
+			wantCode: 2,
+			wantErr:  []string{"Usage: lockin extend"},
+		},
+		{
+			name:     "start without duration",
+			args:     []string{"start"},
+			wantCode: 2,
+			wantErr:  []string{"Usage: lockin start"},
+		},
+		{
+			name:     "start with zero duration",
+			args:     []string{"start", "0m"},
+			wantCode: 1,
+			wantErr:  []string{"at least 1m0s"},
+		},
+		{
+			name:     "start with absurd duration",
+			args:     []string{"start", "400h"},
+			wantCode: 1,
+			wantErr:  []string{"longer than 12h0m0s"},
+		},
+		{
+			name:     "start with garbage duration",
+			args:     []string{"start", "soon"},
+			wantCode: 1,
+			wantErr:  []string{`invalid duration "soon"`},
+		},
+		{
+			name:     "start while running",
+			setup:    [][]string{{"start", "25m"}},
+			args:     []string{"start", "25m"},
+			wantCode: 1,
+			wantErr:  []string{"already active"},
+		},
+		{
+			name:    "profile default duration",
+			setup:   [][]string{{"start", "45m", "--profile", "study"}, {"stop"}},
+			args:    []string{"start", "--profile", "study"},
+			wantOut: []string{"Started 45m session"},
+		},
+		{
+			name:    "stats on a fresh database",
+			args:    []string{"stats"},
+			wantOut: []string{"All time:", "0 sessions", "Current:", "0 days", "Completion rate:", "Last 7 days"},
+		},
+		{
+			name:    "stats counts stopped sessions as aborted",
+			setup:   [][]string{{"start", "25m"}, {"stop"}},
+			args:    []string{"stats", "--output", "json"},
+			wantOut: []string{`"aborted": 1`, `"completed": 0`},
+		},
+		{
+			name:    "status idle",
+			args:    []string{"status"},
+			wantOut: []string{"No active session"},
+		},
+		{
+			name:    "status running",
+			setup:   [][]string{{"start", "50m", "--profile", "work", "--strict"}},
+			args:    []string{"status"},
+			wantOut: []string{"Session running", "Profile:", "work", "Strict:", "yes"},
+		},
+		{
+			name:    "stop running",
+			setup:   [][]string{{"start", "25m"}},
+			args:    []string{"stop"},
+			wantOut: []string{"Session stopped"},
+		},
+		{
+			name:     "stop idle",
+			args:     []string{"stop"},
+			wantCode: 1,
+			wantErr:  []string{"no active session"},
+		},
+		{
+			name:     "stop strict",
+			setup:    [][]string{{"start", "25m", "--strict"}},
+			args:     []string{"stop"},
+			wantCode: 1,
+			wantErr:  []string{"strict"},
+		},
+		{
+			name:    "sites add and ls",
+			setup:   [][]string{{"sites", "add", "youtube.com", "Reddit.com"}},
+			args:    []string{"sites", "ls"},
+			wantOut: []string{"youtube.com\nreddit.com\n"},
+		},
+		{
+			name:     "sites add invalid",
+			args:     []string{"sites", "add", "https://youtube.com"},
+			wantCode: 1,
+			wantErr:  []string{"invalid domain format"},
+		},
+		{
+			name:     "sites add duplicate",
+			setup:    [][]string{{"sites", "add", "youtube.com"}},
+			args:     []string{"sites", "add", "youtube.com"},
+			wantCode: 1,
+			wantErr:  []string{"already blocked"},
+		},
+		{
+			name:       "sites rm",
+			setup:      [][]string{{"sites", "add", "youtube.com", "reddit.com"}, {"sites", "rm", "youtube.com"}},
+			args:       []string{"sites", "ls"},
+			wantOut:    []string{"reddit.com"},
+			notWantOut: []string{"youtube.com"},
+		},
+		{
+			name:     "sites rm during strict session",
+			setup:    [][]string{{"sites", "add", "youtube.com"}, {"start", "25m", "--strict"}},
+			args:     []string{"sites", "rm", "youtube.com"},
+			wantCode: 1,
+			wantErr:  []string{"strict session"},
+		},
+		{
+			name:     "sites rm missing",
+			args:     []string{"sites", "rm", "youtube.com"},
+			wantCode: 1,
+			wantErr:  []string{"not blocked"},
+		},
+		{
+			name:     "sites without verb",
+			args:     []string{"sites"},
+			wantCode: 2,
+			wantErr:  []string{"Usage: lockin sites add|rm|ls"},
+		},
+		{
+			name:     "sites unknown verb",
+			args:     []string{"sites", "nuke"},
+			wantCode: 1,
+			wantErr:  []string{`unknown subcommand "nuke"`},
+		},
+		{
+			name:    "apps add and ls",
+			setup:   [][]string{{"apps", "add", "steam", "Discord"}},
+			args:    []string{"apps", "ls"},
+			wantOut: []string{"steam\nDiscord\n"},
+		},
+		{
+			name:     "apps add path",
+			args:     []string{"apps", "add", "/usr/bin/steam"},
+			wantCode: 1,
+			wantErr:  []string{"invalid process name"},
+		},
+		{
+			name:       "apps rm",
+			setup:      [][]string{{"apps", "add", "steam"}, {"apps", "rm", "steam"}},
+			args:       []string{"apps", "ls"},
+			notWantOut: []string{"steam"},
+		},
+		{
+			name:    "history empty",
+			args:    []string{"history"},
+			wantOut: []string{"No sessions yet"},
+		},
+		{
+			name:    "history lists sessions",
+			setup:   [][]string{{"start", "25m", "--profile", "work"}, {"stop"}, {"start", "50m"}},
+			args:    []string{"history"},
+			wantOut: []string{"PROFILE", "work", "25m", "50m", "running", "ended"},
+		},
+		{
+			name:     "history bad limit",
+			args:     []string{"history", "--limit", "0"},
+			wantCode: 1,
+			wantErr:  []string{"--limit must be positive"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env, stdout, stderr := newTestEnv(t)
+
+			for _, args := range test.setup {
+				if code := run(env, args); code != 0 {
+					t.Fatalf("setup %v exited %d: %s", args, code, stderr)
+				}
+			}
+			stdout.Reset()
+			stderr.Reset()
+
+			code := run(env, test.args)
+			if code != test.wantCode {
+				t.Errorf("exit code = %d, expected %d (stderr: %s)", code, test.wantCode, stderr)
+			}
+			for _, want := range test.wantOut {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout missing %q:\n%s", want, stdout)
+				}
+			}
+			for _, notWant := range test.notWantOut {
+				if strings.Contains(stdout.String(), notWant) {
+					t.Errorf("stdout should not contain %q:\n%s", notWant, stdout)
+				}
+			}
+			for _, want := range test.wantErr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr missing %q:\n%s", want, stderr)
+				}
+			}
+		})
+	}
+}
+
+// Test config validate - problems are listed with their lines
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantCode int
+		wantOut  string
+		wantErr  []string
+	}{
+		{"missing file", "", 0, "is valid", nil},
+		{"valid", "[session]\ndefault_duration = \"50m\"\n", 0, "is valid", nil},
+		{"invalid", "[blocker]\nbackend = \"magic\"\n\n[keys]\nsubmit = [\"x\"]\n", 1, "", []string{
+			`config.toml: line 2: blocker.backend: unknown backend "magic"`,
+			`config.toml: line 4: keys: key action "submit" can't use "x"`,
+			"has 2 problems",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, stdout, stderr := newTestEnv(t)
+			env.ConfigPath = filepath.Join(t.TempDir(), "config.toml")
+			if tt.content != "" {
+				err := os.WriteFile(env.ConfigPath, []byte(tt.content), 0644)
+				if err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+
+			code := run(env, []string{"config", "validate"})
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstderr: %s", code, tt.wantCode, stderr)
+			}
+			if !strings.Contains(stdout.String(), tt.wantOut) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout, tt.wantOut)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr = %q, want it to contain %q", stderr, want)
+				}
+			}
+		})
+	}
+}
+
+// Test blocklist import and export - files in each format go in with
+// rejected lines reported, and come back out the same
+func TestBlocklistImportExport(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	hosts := write("ads.hosts", "127.0.0.1 localhost\n0.0.0.0 ads.example.com\n0.0.0.0 bad_host.example.com\n0.0.0.0 YouTube.com\n")
+	invalid := write("bad.json", `{"version": 1, "sites": ["http://nope"]}`)
+	exported := filepath.Join(dir, "sites.txt")
+
+	env, stdout, stderr := newTestEnv(t)
+	run(env, []string{"sites", "add", "youtube.com"})
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdin    string
+		wantCode int
+		wantOut  []string
+		wantErr  []string
+	}{
+		{name: "hosts file", args: []string{"blocklist", "import", hosts},
+			wantOut: []string{"Imported 1 site and 0 apps (1 already blocked); 1 rejected"},
+			wantErr: []string{`Rejected line 3: "bad_host.example.com": invalid domain`}},
+		{name: "apps from stdin", args: []string{"blocklist", "import", "--apps", "-"}, stdin: "steam\nDiscord\nsteam\n",
+			wantOut: []string{"Imported 0 sites and 2 apps"}},
+		{name: "machine output", args: []string{"blocklist", "import", "-o", "json", hosts},
+			wantOut: []string{`"existing_sites": 2`, `"pos": "line 3"`}},
+		{name: "nothing valid", args: []string{"blocklist", "import", invalid}, wantCode: 1,
+			wantErr: []string{`Rejected sites[0]: "http://nope"`, "no valid entries"}},
+		{name: "apps need text", args: []string{"blocklist", "import", "--apps", hosts}, wantCode: 1,
+			wantErr: []string{"--apps only applies to text files"}},
+		{name: "export json", args: []string{"blocklist", "export"},
+			wantOut: []string{`"version": 1`, `"youtube.com",`, `"ads.example.com"`, `"Discord"`}},
+		{name: "export hosts", args: []string{"blocklist", "export", "--format", "hosts"},
+			wantOut: []string{"0.0.0.0 youtube.com\n0.0.0.0 ads.example.com\n"}},
+		{name: "export text to file", args: []string{"blocklist", "export", "--format", "text", "--file", exported},
+			wantOut: []string{"Exported 2 sites and 0 apps to " + exported}},
+		{name: "export apps as hosts", args: []string{"blocklist", "export", "--format", "hosts", "--apps"}, wantCode: 1,
+			wantErr: []string{"apps can't be written as a hosts file"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout.Reset()
+			stderr.Reset()
+			env.Stdin = strings.NewReader(tt.stdin)
+
+			code := run(env, tt.args)
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstderr: %s", code, tt.wantCode, stderr)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want it to contain %q", stdout, want)
+				}
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr = %q, want it to contain %q", stderr, want)
+				}
+			}
+		})
+	}
+
+	content, err := os.ReadFile(exported)
+	if err != nil || string(content) != "youtube.com\nads.example.com\n" {
+		t.Errorf("exported text = %q, %v", content, err)
+	}
+}
+
+// Test backup and restore - a backup file restores into another database,
+// merging by default, and replacing is refused while a session runs
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lockin.json")
+
+	src, stdout, stderr := newTestEnv(t)
+	run(src, []string{"sites", "add", "youtube.com", "reddit.com"})
+	run(src, []string{"apps", "add", "steam"})
+	run(src, []string{"start", "25m"})
+	stdout.Reset()
+
+	code := run(src, []string{"backup", file})
+	if code != 0 {
+		t.Fatalf("backup exit code = %d\nstderr: %s", code, stderr)
+	}
+	want := "Backed up 1 session, 2 sites, 1 app and 0 profiles to " + file
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("stdout = %q, want it to contain %q", stdout, want)
+	}
+	if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("backup file mode = %v, %v; want 0600", info, err)
+	}
+	data, _ := os.ReadFile(file)
+
+	env, _, _ := newTestEnv(t)
+	run(env, []string{"sites", "add", "youtube.com", "news.example.com"})
+
+	tests := []struct {
+		name     string
+		env      *Env
+		args     []string
+		stdin    string
+		wantCode int
+		wantOut  []string
+		wantErr  []string
+	}{
+		{name: "file exists", env: src, args: []string{"backup", file}, wantCode: 1,
+			wantErr: []string{"already exists (use --force to overwrite it)"}},
+		{name: "backup to stdout", env: src, args: []string{"backup", "-"},
+			wantOut: []string{`"format": "lockin-backup"`, `"checksum": "sha256:`}},
+		{name: "merge", env: env, args: []string{"restore", file},
+			wantOut: []string{"Merged the backup from", "added 1 session, 1 site, 1 app and 0 profiles (1 already there)"}},
+		{name: "merge again from stdin", env: env, args: []string{"restore", "-o", "json", "-"}, stdin: string(data),
+			wantOut: []string{`"mode": "merge"`, `"added_sessions": 0`, `"skipped": 4`}},
+		{name: "edited file", env: env, args: []string{"restore", "-"}, stdin: strings.Replace(string(data), "reddit.com", "reddit.org", 1),
+			wantCode: 1, wantErr: []string{"checksum doesn't match"}},
+		{name: "replace", env: env, args: []string{"restore", "--replace", file},
+			wantOut: []string{"Replaced everything with the backup", "added 1 session, 2 sites, 1 app",
+				"Removed 1 earlier session from history (still in the event log, marked deleted)"}},
+		{name: "replace during a session", env: src, args: []string{"restore", "--replace", file}, wantCode: 1,
+			wantErr: []string{"can't be replaced until it ends"}},
+		{name: "no file", env: env, args: []string{"restore"}, wantCode: 2,
+			wantErr: []string{"Usage: lockin restore"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := tt.env.Stdout.(*bytes.Buffer), tt.env.Stderr.(*bytes.Buffer)
+			stdout.Reset()
+			stderr.Reset()
+			tt.env.Stdin = strings.NewReader(tt.stdin)
+
+			code := run(tt.env, tt.args)
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d\nstderr: %s", code, tt.wantCode, stderr)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("stdout = %q, want it to contain %q", stdout, want)
+				}
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr = %q, want it to contain %q", stderr, want)
+				}
+			}
+		})
+	}
+
+	svc, _ := env.Connect()
+	sites, _ := svc.ListBlockedSites()
+	if len(sites) != 2 {
+		t.Errorf("after replacing, got sites %v, want the backup's 2", sites)
+	}
+}
+
+// Test audit verify - erasing an aborted session by hand is reported, in
+// JSON too, with a failing exit code
+func TestAuditVerify(t *testing.T) {
+	env, stdout, stderr := newTestEnv(t)
+	run(env, []string{"start", "25m"})
+	run(env, []string{"stop"})
+	svc, _ := env.Connect()
+	_, err := svc.(*service.Local).DB.Exec("DELETE FROM sessions")
+	if err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	stdout.Reset()
+
+	code := run(env, []string{"audit", "verify"})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if want := "audit log check failed: session 1 was removed outside LockIn"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to mention %q", stderr, want)
+	}
+
+	stdout.Reset()
+	code = run(env, []string{"audit", "verify", "-o", "json"})
+	var out AuditOutput
+	err = json.Unmarshal(stdout.Bytes(), &out)
+	if err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if code != 1 || out.OK || out.SessionID != 1 || out.Events != 2 {
+		t.Errorf("exit code %d, output %+v; want 1 and a report on session 1", code, out)
+	}
+}
+
+// Test report - printed, saved to the configured directory and piped to a
+// stand-in sendmail
+func TestReport(t *testing.T) {
+	dir := t.TempDir()
+	mailed := filepath.Join(dir, "mailed")
+	sendmail := filepath.Join(dir, "sendmail")
+	err := os.WriteFile(sendmail, []byte("#!/bin/sh\ncat > "+mailed+"\n"), 0755)
+	if err != nil {
+		t.Fatalf("Failed to write fake sendmail: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	err = os.WriteFile(configPath, []byte("[report]\nformat = \"text\"\ndir = \""+filepath.Join(dir, "reports")+
+		"\"\nto = \"sam@example.com\"\nsendmail = \""+sendmail+" -t\"\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	env, stdout, stderr := newTestEnv(t)
+	env.ConfigPath = configPath
+	run(env, []string{"start", "25m"})
+	run(env, []string{"stop"})
+	stdout.Reset()
+
+	code := run(env, []string{"report", "--week"})
+	if code != 0 {
+		t.Fatalf("report exit code = %d\nstderr: %s", code, stderr)
+	}
+	if out := stdout.String(); !strings.Contains(out, "Planned sessions:   1 (25m)") || !strings.Contains(out, "Ended early:        1") {
+		t.Errorf("report printed:\n%s", out)
+	}
+
+	// Deleting the session from history doesn't take it out of the report.
+	svc, _ := env.Connect()
+	history, _ := svc.History(1)
+	err = svc.DeleteSession(history[0].ID)
+	if err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	stdout.Reset()
+	run(env, []string{"report", "--week"})
+	if out := stdout.String(); !strings.Contains(out, "Ended early:        1") || !strings.Contains(out, "Deleted from history") {
+		t.Errorf("report after deleting the session:\n%s", out)
+	}
+
+	stdout.Reset()
+	code = run(env, []string{"report", "--week", "--format", "html", "--save", "--mail"})
+	if code != 0 {
+		t.Fatalf("report exit code = %d\nstderr: %s", code, stderr)
+	}
+	saved, err := filepath.Glob(filepath.Join(dir, "reports", "lockin-report-*.html"))
+	if err != nil || len(saved) != 1 {
+		t.Fatalf("saved reports = %v, want one HTML report", saved)
+	}
+	mail, _ := os.ReadFile(mailed)
+	if !strings.Contains(string(mail), "To: sam@example.com\n") || !strings.Contains(string(mail), "<h1>LockIn focus report") {
+		t.Errorf("mailed:\n%s", mail)
+	}
+	want := "Saved the report to " + saved[0] + "\nMailed the report to sam@example.com\n"
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+
+	code = run(env, []string{"report"})
+	if code != 2 || !strings.Contains(stderr.String(), "Usage: lockin report --week") {
+		t.Errorf("report without --week: exit code %d, stderr %q", code, stderr)
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/youssef28m/LockIn/internal/config"
+	"github.com/youssef28m/LockIn/internal/ui/pages"
+)
+
+func init() {
+	register(command{
+		name:    "config",
+		summary: "check the config file: config validate [path]",
+		run:     runConfig,
+	})
+}
+
+func runConfig(env *Env, args []string) error {
+	_, args, err := subcommand(env, "config", []string{"validate"}, args)
+	if err != nil {
+		return err
+	}
+
+	path := env.ConfigPath
+	switch len(args) {
+	case 0:
+	case 1:
+		path = args[0]
+	default:
+		fmt.Fprintln(env.Stderr, "Usage: lockin config validate [path]")
+		return errUsage
+	}
+
+	cfg, err := config.Load(path)
+	invalid := &config.ValidationError{Path: path}
+	if err != nil && !errors.As(err, &invalid) {
+		return err
+	}
+
+	// Key remaps are checked by the UI, which knows the actions.
+	_, err = pages.NewKeymap(cfg.Keys)
+	if err != nil {
+		invalid.Problems = append(invalid.Problems, config.Problem{Line: cfg.Line("keys"), Key: "keys", Message: err.Error()})
+	}
+
+	if len(invalid.Problems) > 0 {
+		fmt.Fprintln(env.Stderr, invalid.Error())
+		return fmt.Errorf("%s has %s", path, plural(len(invalid.Problems), "problem"))
+	}
+	fmt.Fprintf(env.Stdout, "%s is valid\n", path)
+	return nil
+}
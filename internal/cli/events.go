@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+func init() {
+	register(command{
+		name:    "events",
+		summary: "show the session event log, e.g. lockin events --session 12",
+		run:     runEvents,
+	})
+}
+
+func runEvents(env *Env, args []string) error {
+	fs := newFlagSet(env, "events")
+	session := fs.Int64("session", 0, "only show events of this session")
+	limit := fs.Int("limit", 50, "how many of the newest events to show")
+	format := outputFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *limit < 1 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	events, err := svc.Events(service.EventQuery{SessionID: *session, Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	out := EventsOutput{Version: OutputVersion, Events: []EventOutput{}}
+	for _, event := range events {
+		out.Events = append(out.Events, eventOutput(event))
+	}
+
+	return render(env.Stdout, *format, out, func() error {
+		if len(events) == 0 {
+			fmt.Fprintln(env.Stdout, "No events yet")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tSESSION\tEVENT\tDETAILS")
+		for _, event := range events {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+				time.Unix(event.At, 0).Format("2006-01-02 15:04:05"),
+				event.SessionID,
+				event.Type,
+				orDash(formatPayload(event.Payload)),
+			)
+		}
+		return w.Flush()
+	})
+}
+
+// formatPayload writes a payload as key=value pairs sorted by key. Values
+// other than strings are written as JSON.
+func formatPayload(payload map[string]any) string {
+	keys := make([]string, 0, len(payload))
+	for key := range payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		value, ok := payload[key].(string)
+		if !ok {
+			data, _ := json.Marshal(payload[key])
+			value = string(data)
+		}
+		pairs[i] = key + "=" + value
+	}
+	return strings.Join(pairs, " ")
+}
+
+func eventOutput(event models.SessionEvent) EventOutput {
+	payload := event.Payload
+	if payload == nil {
+		payload = map[string]any{}
+	}
+	return EventOutput{
+		ID:        event.ID,
+		SessionID: event.SessionID,
+		Type:      event.Type,
+		At:        time.Unix(event.At, 0).UTC().Format(time.RFC3339),
+		Payload:   payload,
+	}
+}
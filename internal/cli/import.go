@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+func init() {
+	register(command{
+		name:    "blocklist",
+		summary: "share block lists: blocklist import <file> | blocklist export",
+		run:     runBlocklist,
+	})
+}
+
+// exportRedirectIP is what hosts-format exports point domains at, as public
+// hosts lists do.
+const exportRedirectIP = "0.0.0.0"
+
+func runBlocklist(env *Env, args []string) error {
+	verb, args, err := subcommand(env, "blocklist", []string{"import", "export"}, args)
+	if err != nil {
+		return err
+	}
+	if verb == "import" {
+		return runImport(env, args)
+	}
+	return runExport(env, args)
+}
+
+func runImport(env *Env, args []string) error {
+	fs := newFlagSet(env, "blocklist import")
+	format := fs.String("format", blocklist.FormatAuto, "file format: auto, json, text or hosts")
+	apps := fs.Bool("apps", false, "read a text file as app names instead of domains")
+	output := outputFlag(fs)
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(env.Stderr, "Usage: lockin blocklist import [--format auto|json|text|hosts] [--apps] <file|->")
+		return errUsage
+	}
+
+	name := positional[0]
+	var data []byte
+	if name == "-" {
+		data, err = io.ReadAll(env.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *format == blocklist.FormatAuto {
+		*format = blocklist.Detect(name, data)
+	}
+	doc, err := blocklist.Parse(data, *format)
+	if err != nil {
+		return err
+	}
+	if *apps {
+		if *format != blocklist.FormatText {
+			return fmt.Errorf("--apps only applies to text files")
+		}
+		doc.Apps, doc.Sites = doc.Sites, nil
+	}
+
+	list, rejected := blocklist.Check(doc)
+	if len(list.Sites) == 0 && len(list.Apps) == 0 && len(rejected) > 0 {
+		printRejected(env, rejected)
+		return fmt.Errorf("no valid entries in %s", name)
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	result, err := svc.ImportBlockList(list)
+	if err != nil {
+		return err
+	}
+
+	return render(env.Stdout, *output, importOutput(result, rejected), func() error {
+		printRejected(env, rejected)
+		fmt.Fprintf(env.Stdout, "Imported %s and %s", plural(result.AddedSites, "site"), plural(result.AddedApps, "app"))
+		if existing := result.ExistingSites + result.ExistingApps; existing > 0 {
+			fmt.Fprintf(env.Stdout, " (%d already blocked)", existing)
+		}
+		if len(rejected) > 0 {
+			fmt.Fprintf(env.Stdout, "; %d rejected", len(rejected))
+		}
+		fmt.Fprintln(env.Stdout)
+		return nil
+	})
+}
+
+func printRejected(env *Env, rejected []blocklist.Rejected) {
+	for _, r := range rejected {
+		fmt.Fprintf(env.Stderr, "Rejected %s\n", r)
+	}
+}
+
+func runExport(env *Env, args []string) error {
+	fs := newFlagSet(env, "blocklist export")
+	format := fs.String("format", blocklist.FormatJSON, "file format: json, text or hosts")
+	apps := fs.Bool("apps", false, "write app names instead of domains (text format)")
+	file := fs.String("file", "", "write to this file instead of standard output")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	list, err := currentList(svc)
+	if err != nil {
+		return err
+	}
+
+	// The text and hosts formats hold one kind of entry.
+	var b bytes.Buffer
+	switch {
+	case *format == blocklist.FormatJSON:
+		err = blocklist.WriteJSON(&b, list)
+	case *format == blocklist.FormatText && *apps:
+		list.Sites = nil
+		err = blocklist.WriteText(&b, list.Apps)
+	case *format == blocklist.FormatText:
+		list.Apps = nil
+		err = blocklist.WriteText(&b, list.Sites)
+	case *format == blocklist.FormatHosts && *apps:
+		return fmt.Errorf("apps can't be written as a hosts file")
+	case *format == blocklist.FormatHosts:
+		list.Apps = nil
+		err = blocklist.WriteHosts(&b, list.Sites, exportRedirectIP)
+	default:
+		return fmt.Errorf("unknown format %q (want json, text or hosts)", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *file == "" {
+		_, err = env.Stdout.Write(b.Bytes())
+		return err
+	}
+	err = os.WriteFile(*file, b.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stdout, "Exported %s and %s to %s\n", plural(len(list.Sites), "site"), plural(len(list.Apps), "app"), *file)
+	return nil
+}
+
+func currentList(svc service.Service) (blocklist.List, error) {
+	sites, err := svc.ListBlockedSites()
+	if err != nil {
+		return blocklist.List{}, err
+	}
+	apps, err := svc.ListBlockedApps()
+	if err != nil {
+		return blocklist.List{}, err
+	}
+
+	list := blocklist.List{Version: blocklist.Version}
+	for _, site := range sites {
+		list.Sites = append(list.Sites, site.Domain)
+	}
+	for _, app := range apps {
+		list.Apps = append(list.Apps, app.ProcessName)
+	}
+	return list, nil
+}
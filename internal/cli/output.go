@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/youssef28m/LockIn/internal/backup"
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+// OutputVersion is bumped whenever a field in the JSON/YAML output is
+// renamed, removed or changes meaning. Adding fields doesn't bump it.
+const OutputVersion = 1
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+// outputFlag registers --output (and -o) on fs.
+func outputFlag(fs *flag.FlagSet) *string {
+	format := fs.String("output", formatTable, "output format: table, json or yaml")
+	fs.StringVar(format, "o", formatTable, "shorthand for --output")
+	return format
+}
+
+// render writes v as JSON or YAML, or calls table for the human format.
+func render(w io.Writer, format string, v any, table func() error) error {
+	switch format {
+	case formatTable:
+		return table()
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		err := enc.Encode(v)
+		if err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unknown output format %q (want table, json or yaml)", format)
+}
+
+//***********************************************************//
+// Output schema
+//***********************************************************//
+
+// SessionOutput is how a session appears in machine-readable output.
+// Times are RFC 3339 in UTC.
+type SessionOutput struct {
+	ID               int64  `json:"id" yaml:"id"`
+	Phase            string `json:"phase" yaml:"phase"`
+	Profile          string `json:"profile" yaml:"profile"`
+	Strict           bool   `json:"strict" yaml:"strict"`
+	StartedAt        string `json:"started_at" yaml:"started_at"`
+	EndsAt           string `json:"ends_at" yaml:"ends_at"`
+	DurationSeconds  int64  `json:"duration_seconds" yaml:"duration_seconds"`
+	RemainingSeconds int64  `json:"remaining_seconds" yaml:"remaining_seconds"`
+}
+
+type StatusOutput struct {
+	Version int            `json:"version" yaml:"version"`
+	Active  bool           `json:"active" yaml:"active"`
+	Session *SessionOutput `json:"session" yaml:"session"`
+}
+
+type HistoryOutput struct {
+	Version  int             `json:"version" yaml:"version"`
+	Sessions []SessionOutput `json:"sessions" yaml:"sessions"`
+}
+
+// BlockedItemOutput covers both sites and apps; Kind tells them apart.
+type BlockedItemOutput struct {
+	ID   int64  `json:"id" yaml:"id"`
+	Kind string `json:"kind" yaml:"kind"`
+	Name string `json:"name" yaml:"name"`
+}
+
+type BlockedListOutput struct {
+	Version int                 `json:"version" yaml:"version"`
+	Items   []BlockedItemOutput `json:"items" yaml:"items"`
+}
+
+// PeriodOutput is the focused time in one day, week or month. Start is the
+// first local day of the period as YYYY-MM-DD.
+// ImportOutput reports a block list import. Rejected entries are listed
+// with where they were in the file.
+type ImportOutput struct {
+	Version       int                  `json:"version" yaml:"version"`
+	AddedSites    int                  `json:"added_sites" yaml:"added_sites"`
+	AddedApps     int                  `json:"added_apps" yaml:"added_apps"`
+	ExistingSites int                  `json:"existing_sites" yaml:"existing_sites"`
+	ExistingApps  int                  `json:"existing_apps" yaml:"existing_apps"`
+	Rejected      []blocklist.Rejected `json:"rejected" yaml:"rejected"`
+}
+
+// RestoreOutput reports a restore: what it added and how many rows it
+// skipped because they were already there. BackupCreatedAt is RFC 3339 in
+// UTC.
+type RestoreOutput struct {
+	Version         int    `json:"version" yaml:"version"`
+	Mode            string `json:"mode" yaml:"mode"`
+	BackupCreatedAt string `json:"backup_created_at" yaml:"backup_created_at"`
+	SchemaVersion   int    `json:"schema_version" yaml:"schema_version"`
+	AddedSessions   int    `json:"added_sessions" yaml:"added_sessions"`
+	AddedSites      int    `json:"added_sites" yaml:"added_sites"`
+	AddedApps       int    `json:"added_apps" yaml:"added_apps"`
+	AddedProfiles   int    `json:"added_profiles" yaml:"added_profiles"`
+	AddedEvents     int    `json:"added_events" yaml:"added_events"`
+	Skipped         int    `json:"skipped" yaml:"skipped"`
+	// ReplacedSessions is how many sessions a replace removed; they stay
+	// in the event log, logged as deleted.
+	ReplacedSessions int `json:"replaced_sessions" yaml:"replaced_sessions"`
+}
+
+// EventOutput is one entry of the session event log. At is RFC 3339 in UTC;
+// Payload's keys depend on Type.
+type EventOutput struct {
+	ID        int64          `json:"id" yaml:"id"`
+	SessionID int64          `json:"session_id" yaml:"session_id"`
+	Type      string         `json:"type" yaml:"type"`
+	At        string         `json:"at" yaml:"at"`
+	Payload   map[string]any `json:"payload" yaml:"payload"`
+}
+
+// EventsOutput lists events oldest first.
+type EventsOutput struct {
+	Version int           `json:"version" yaml:"version"`
+	Events  []EventOutput `json:"events" yaml:"events"`
+}
+
+// AuditOutput is the outcome of lockin audit verify. Problem, EventID and
+// SessionID are only set when OK is false.
+type AuditOutput struct {
+	Version   int    `json:"version" yaml:"version"`
+	OK        bool   `json:"ok" yaml:"ok"`
+	Events    int    `json:"events" yaml:"events"`
+	Problem   string `json:"problem,omitempty" yaml:"problem,omitempty"`
+	EventID   int64  `json:"event_id,omitempty" yaml:"event_id,omitempty"`
+	SessionID int64  `json:"session_id,omitempty" yaml:"session_id,omitempty"`
+}
+
+type PeriodOutput struct {
+	Start    string `json:"start" yaml:"start"`
+	Seconds  int64  `json:"seconds" yaml:"seconds"`
+	Sessions int    `json:"sessions" yaml:"sessions"`
+}
+
+// StatsOutput omits periods without any focus from daily, weekly and
+// monthly. Weeks start on Monday.
+type StatsOutput struct {
+	Version        int            `json:"version" yaml:"version"`
+	TodaySeconds   int64          `json:"today_seconds" yaml:"today_seconds"`
+	WeekSeconds    int64          `json:"week_seconds" yaml:"week_seconds"`
+	MonthSeconds   int64          `json:"month_seconds" yaml:"month_seconds"`
+	TotalSeconds   int64          `json:"total_seconds" yaml:"total_seconds"`
+	Sessions       int            `json:"sessions" yaml:"sessions"`
+	CurrentStreak  int            `json:"current_streak_days" yaml:"current_streak_days"`
+	LongestStreak  int            `json:"longest_streak_days" yaml:"longest_streak_days"`
+	Completed      int            `json:"completed" yaml:"completed"`
+	Aborted        int            `json:"aborted" yaml:"aborted"`
+	CompletionRate float64        `json:"completion_rate" yaml:"completion_rate"`
+	Daily          []PeriodOutput `json:"daily" yaml:"daily"`
+	Weekly         []PeriodOutput `json:"weekly" yaml:"weekly"`
+	Monthly        []PeriodOutput `json:"monthly" yaml:"monthly"`
+}
+
+func sessionOutput(session models.Session, now time.Time) SessionOutput {
+	return SessionOutput{
+		ID:               session.ID,
+		Phase:            session.Phase(now),
+		Profile:          session.Profile,
+		Strict:           session.Strict,
+		StartedAt:        time.Unix(session.StartTime, 0).UTC().Format(time.RFC3339),
+		EndsAt:           session.EndTime().UTC().Format(time.RFC3339),
+		DurationSeconds:  session.DurationSeconds,
+		RemainingSeconds: session.RemainingAt(now),
+	}
+}
+
+func sitesOutput(sites []models.BlockedSite) BlockedListOutput {
+	out := BlockedListOutput{Version: OutputVersion, Items: []BlockedItemOutput{}}
+	for _, site := range sites {
+		out.Items = append(out.Items, BlockedItemOutput{ID: site.ID, Kind: "site", Name: site.Domain})
+	}
+	return out
+}
+
+func importOutput(result *service.ImportResult, rejected []blocklist.Rejected) ImportOutput {
+	if rejected == nil {
+		rejected = []blocklist.Rejected{}
+	}
+	return ImportOutput{
+		Version:       OutputVersion,
+		AddedSites:    result.AddedSites,
+		AddedApps:     result.AddedApps,
+		ExistingSites: result.ExistingSites,
+		ExistingApps:  result.ExistingApps,
+		Rejected:      rejected,
+	}
+}
+
+func restoreOutput(result *backup.Result, archive *backup.Archive) RestoreOutput {
+	return RestoreOutput{
+		Version:          OutputVersion,
+		Mode:             result.Mode,
+		BackupCreatedAt:  archive.CreatedAt.UTC().Format(time.RFC3339),
+		SchemaVersion:    archive.SchemaVersion,
+		AddedSessions:    result.Sessions,
+		AddedSites:       result.Sites,
+		AddedApps:        result.Apps,
+		AddedProfiles:    result.Profiles,
+		AddedEvents:      result.Events,
+		Skipped:          result.Skipped,
+		ReplacedSessions: result.Replaced,
+	}
+}
+
+func appsOutput(apps []models.BlockedApp) BlockedListOutput {
+	out := BlockedListOutput{Version: OutputVersion, Items: []BlockedItemOutput{}}
+	for _, app := range apps {
+		out.Items = append(out.Items, BlockedItemOutput{ID: app.ID, Kind: "app", Name: app.ProcessName})
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestMain(m *testing.M) {
+	// Table output prints local times; pin them so goldens match anywhere.
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+// fixedNow is 2026-03-02 09:30:00 UTC
+var fixedNow = time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+// fakeService returns canned data so output is identical on every run
+type fakeService struct {
+	service.Service
+	active   *models.Session
+	sessions []models.Session
+	sites    []models.BlockedSite
+	apps     []models.BlockedApp
+	events   []models.SessionEvent
+	stats    *service.Stats
+}
+
+func (f *fakeService) ActiveSession() (*models.Session, error)         { return f.active, nil }
+func (f *fakeService) History(limit int) ([]models.Session, error)     { return f.sessions, nil }
+func (f *fakeService) ListBlockedSites() ([]models.BlockedSite, error) { return f.sites, nil }
+func (f *fakeService) ListBlockedApps() ([]models.BlockedApp, error)   { return f.apps, nil }
+
+func (f *fakeService) Events(q service.EventQuery) ([]models.SessionEvent, error) {
+	return f.events, nil
+}
+
+func (f *fakeService) VerifyAudit() (*storage.AuditReport, error) {
+	return &storage.AuditReport{Events: len(f.events)}, nil
+}
+
+func (f *fakeService) Stats(now time.Time) (*service.Stats, error) {
+	if f.stats == nil {
+		return &service.Stats{}, nil
+	}
+	return f.stats, nil
+}
+
+func seededFake() *fakeService {
+	running := models.Session{
+		ID:              3,
+		StartTime:       fixedNow.Add(-20 * time.Minute).Unix(),
+		DurationSeconds: 3000,
+		Active:          true,
+		Profile:         "work",
+		Strict:          true,
+	}
+	return &fakeService{
+		active: &running,
+		sessions: []models.Session{
+			running,
+			{ID: 2, StartTime: fixedNow.Add(-26 * time.Hour).Unix(), DurationSeconds: 1500, Profile: "study"},
+			{ID: 1, StartTime: fixedNow.Add(-50 * time.Hour).Unix(), DurationSeconds: 5400},
+		},
+		sites: []models.BlockedSite{{ID: 1, Domain: "youtube.com"}, {ID: 4, Domain: "reddit.com"}},
+		apps:  []models.BlockedApp{{ID: 2, ProcessName: "steam"}},
+		events: []models.SessionEvent{
+			{ID: 4, SessionID: 2, Type: models.EventStarted, At: fixedNow.Add(-26 * time.Hour).Unix(),
+				Payload: map[string]any{"duration_seconds": 1500, "profile": "study", "strict": false}},
+			{ID: 5, SessionID: 2, Type: models.EventCompleted, At: fixedNow.Add(-26*time.Hour + 25*time.Minute).Unix()},
+			{ID: 6, SessionID: 3, Type: models.EventStarted, At: fixedNow.Add(-20 * time.Minute).Unix(),
+				Payload: map[string]any{"duration_seconds": 3000, "profile": "work", "strict": true}},
+			{ID: 7, SessionID: 3, Type: models.EventTamper, At: fixedNow.Add(-5 * time.Minute).Unix(),
+				Payload: map[string]any{"missing": []string{"youtube.com"}}},
+		},
+		stats: &service.Stats{
+			TodaySeconds:  1500,
+			WeekSeconds:   1500,
+			MonthSeconds:  4500,
+			TotalSeconds:  45900,
+			Sessions:      21,
+			CurrentStreak: 3,
+			LongestStreak: 9,
+			Completed:     17,
+			Aborted:       3,
+			Daily: []storage.FocusTotal{
+				{Start: "2026-02-28", Seconds: 5400, Sessions: 2},
+				{Start: "2026-03-01", Seconds: 3000, Sessions: 1},
+				{Start: "2026-03-02", Seconds: 1500, Sessions: 1},
+			},
+			Weekly: []storage.FocusTotal{
+				{Start: "2026-02-23", Seconds: 10800, Sessions: 4},
+				{Start: "2026-03-02", Seconds: 1500, Sessions: 1},
+			},
+			Monthly: []storage.FocusTotal{
+				{Start: "2026-02-01", Seconds: 33600, Sessions: 16},
+				{Start: "2026-03-01", Seconds: 1500, Sessions: 1},
+			},
+		},
+	}
+}
+
+// checkGolden compares got with testdata/name, or rewrites it under -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		err := os.WriteFile(path, got, 0644)
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s.\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestOutputGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		fake   *fakeService
+		args   []string
+	}{
+		{"status.table.golden", seededFake(), []string{"status"}},
+		{"status.json.golden", seededFake(), []string{"status", "--output", "json"}},
+		{"status.yaml.golden", seededFake(), []string{"status", "-o", "yaml"}},
+		{"status_idle.json.golden", &fakeService{}, []string{"status", "--output", "json"}},
+		{"status_idle.yaml.golden", &fakeService{}, []string{"status", "--output", "yaml"}},
+		{"history.table.golden", seededFake(), []string{"history"}},
+		{"history.json.golden", seededFake(), []string{"history", "--output", "json"}},
+		{"history.yaml.golden", seededFake(), []string{"history", "--output", "yaml"}},
+		{"history_empty.json.golden", &fakeService{}, []string{"history", "--output", "json"}},
+		{"sites.table.golden", seededFake(), []string{"sites", "ls"}},
+		{"sites.json.golden", seededFake(), []string{"sites", "ls", "--output", "json"}},
+		{"sites.yaml.golden", seededFake(), []string{"sites", "ls", "--output", "yaml"}},
+		{"sites_empty.json.golden", &fakeService{}, []string{"sites", "ls", "--output", "json"}},
+		{"apps.json.golden", seededFake(), []string{"apps", "ls", "--output", "json"}},
+		{"stats.table.golden", seededFake(), []string{"stats"}},
+		{"stats.json.golden", seededFake(), []string{"stats", "--output", "json"}},
+		{"stats.yaml.golden", seededFake(), []string{"stats", "--output", "yaml"}},
+		{"stats_empty.json.golden", &fakeService{}, []string{"stats", "--output", "json"}},
+		{"events.table.golden", seededFake(), []string{"events"}},
+		{"events.json.golden", seededFake(), []string{"events", "--output", "json"}},
+		{"events.yaml.golden", seededFake(), []string{"events", "--output", "yaml"}},
+		{"events_empty.json.golden", &fakeService{}, []string{"events", "--output", "json"}},
+		{"audit.json.golden", seededFake(), []string{"audit", "verify", "--output", "json"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			env := &Env{
+				Stdout:  stdout,
+				Stderr:  stderr,
+				Connect: func() (service.Service, error) { return test.fake, nil },
+				Now:     func() time.Time { return fixedNow },
+			}
+
+			if code := run(env, test.args); code != 0 {
+				t.Fatalf("exit code %d: %s", code, stderr)
+			}
+			checkGolden(t, test.golden, stdout.Bytes())
+		})
+	}
+}
+
+func TestUnknownOutputFormat(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	env := &Env{
+		Stdout:  stdout,
+		Stderr:  stderr,
+		Connect: func() (service.Service, error) { return &fakeService{}, nil },
+		Now:     func() time.Time { return fixedNow },
+	}
+
+	if code := run(env, []string{"status", "--output", "xml"}); code != 1 {
+		t.Errorf("exit code = %d, expected 1", code)
+	}
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/youssef28m/LockIn/internal/config"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/report"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+func init() {
+	register(command{
+		name:    "report",
+		summary: "write or mail a focus report, e.g. lockin report --week --last --mail",
+		run:     runReport,
+	})
+}
+
+// reportPage is how many sessions a report asks for at a time.
+const reportPage = 500
+
+func runReport(env *Env, args []string) error {
+	cfg, err := config.Load(env.ConfigPath)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Using defaults for settings that aren't valid (see lockin config validate):\n%v\n", err)
+	}
+
+	fs := newFlagSet(env, "report")
+	week := fs.Bool("week", false, "report on a week, Monday to Sunday")
+	last := fs.Bool("last", false, "report on last week instead of this one")
+	format := fs.String("format", cfg.Report.Format, "report format: "+strings.Join(report.Formats, ", "))
+	save := fs.Bool("save", false, "write the report to the directory set in the config")
+	dir := fs.String("dir", "", "write the report to this directory")
+	mail := fs.Bool("mail", false, "mail the report to the address set in the config")
+	to := fs.String("to", "", "mail the report to this address")
+	err = fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if !*week || fs.NArg() > 0 {
+		fmt.Fprintln(env.Stderr, "Usage: lockin report --week [--last] [--format f] [--save | --dir d] [--mail | --to addr]")
+		return errUsage
+	}
+
+	if *dir == "" && *save {
+		*dir = cfg.Report.Dir
+		if *dir == "" {
+			return fmt.Errorf("no report directory set; add dir to [report] in %s or pass --dir", env.ConfigPath)
+		}
+	}
+	if *to == "" && *mail {
+		*to = cfg.Report.To
+		if *to == "" {
+			return fmt.Errorf("no one to mail the report to; add to to [report] in %s or pass --to", env.ConfigPath)
+		}
+	}
+
+	from := service.WeekStart(env.Now())
+	if *last {
+		from = from.AddDate(0, 0, -7)
+	}
+	until := from.AddDate(0, 0, 7)
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	sessions, err := reportSessions(svc, service.HistoryQuery{From: from, To: until})
+	if err != nil {
+		return err
+	}
+	// Events run to now, so sessions of the week are seen ending and being
+	// deleted after it.
+	events, err := svc.Events(service.EventQuery{From: from})
+	if err != nil {
+		return err
+	}
+
+	r := report.New(from, until, sessions, events)
+	var body bytes.Buffer
+	err = report.Render(&body, r, *format)
+	if err != nil {
+		return err
+	}
+
+	if *dir == "" && *to == "" {
+		_, err = env.Stdout.Write(body.Bytes())
+		return err
+	}
+
+	if *dir != "" {
+		path := filepath.Join(*dir, "lockin-report-"+from.Format("2006-01-02")+report.Extension(*format))
+		err := os.MkdirAll(*dir, 0700)
+		if err == nil {
+			err = os.WriteFile(path, body.Bytes(), 0600)
+		}
+		if err != nil {
+			return fmt.Errorf("error saving the report: %w", err)
+		}
+		fmt.Fprintf(env.Stdout, "Saved the report to %s\n", path)
+	}
+
+	if *to != "" {
+		err := report.Send(cfg.Report.Sendmail, report.Message{
+			From:        cfg.Report.From,
+			To:          *to,
+			Subject:     "LockIn focus report: " + r.Title(),
+			Date:        env.Now(),
+			ContentType: report.ContentType(*format),
+			Body:        body.Bytes(),
+		})
+		if err != nil {
+			return fmt.Errorf("error mailing the report: %w", err)
+		}
+		fmt.Fprintf(env.Stdout, "Mailed the report to %s\n", *to)
+	}
+	return nil
+}
+
+// reportSessions returns every session q matches, however many pages that
+// takes.
+func reportSessions(svc service.Service, q service.HistoryQuery) ([]models.Session, error) {
+	var sessions []models.Session
+	q.Limit = reportPage
+	for {
+		page, err := svc.QueryHistory(q)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, page.Sessions...)
+		if len(page.Sessions) < q.Limit || len(sessions) >= page.Total {
+			return sessions, nil
+		}
+		q.Offset += q.Limit
+	}
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/youssef28m/LockIn/internal/daemon"
+	"github.com/youssef28m/LockIn/internal/helper"
+	"github.com/youssef28m/LockIn/internal/systemd"
+)
+
+func init() {
+	register(command{
+		name:    "install-service",
+		summary: "install systemd units so blocking survives reboots",
+		run:     runInstallService,
+	})
+	register(command{
+		name:    "uninstall-service",
+		summary: "disable and remove the systemd units",
+		run:     runUninstallService,
+	})
+}
+
+// scopeFlags parses --system/--user. With neither, printing covers both
+// units, while installing picks the one the current user can manage.
+func scopeFlags(system, user, print bool) []systemd.Scope {
+	var scopes []systemd.Scope
+	if system {
+		scopes = append(scopes, systemd.SystemScope)
+	}
+	if user {
+		scopes = append(scopes, systemd.UserScope)
+	}
+	switch {
+	case len(scopes) > 0:
+		return scopes
+	case print:
+		return []systemd.Scope{systemd.SystemScope, systemd.UserScope}
+	case os.Geteuid() == 0:
+		return []systemd.Scope{systemd.SystemScope}
+	}
+	return []systemd.Scope{systemd.UserScope}
+}
+
+func runInstallService(env *Env, args []string) error {
+	fs := newFlagSet(env, "install-service")
+	print := fs.Bool("print", false, "print the units instead of installing them")
+	system := fs.Bool("system", false, "only the root helper's system unit")
+	user := fs.Bool("user", false, "only the daemon's user unit")
+	helperPath := fs.String("helper-path", daemon.FindBinary("lockin-helper"), "path to lockin-helper")
+	daemonPath := fs.String("daemon-path", daemon.FindBinary("lockind"), "path to lockind")
+	allow := fs.String("allow-uid", defaultAllowUID(), "user id the helper accepts requests from")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	opts := systemd.Options{
+		HelperPath:   *helperPath,
+		DaemonPath:   *daemonPath,
+		HelperSocket: helper.SocketPath(),
+	}
+	for _, field := range strings.Split(*allow, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		uid, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("invalid uid %q", field)
+		}
+		opts.AllowUIDs = append(opts.AllowUIDs, uid)
+	}
+
+	scopes := scopeFlags(*system, *user, *print)
+	if *print {
+		for i, scope := range scopes {
+			if i > 0 {
+				fmt.Fprintln(env.Stdout)
+			}
+			fmt.Fprint(env.Stdout, systemd.Render(scope, opts))
+		}
+		return nil
+	}
+
+	installer, err := systemd.NewInstaller()
+	if err != nil {
+		return err
+	}
+	for _, scope := range scopes {
+		err := checkScopePrivileges(scope)
+		if err != nil {
+			return err
+		}
+		err = installer.Install(scope, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Stdout, "Installed and started %s\n", unitName(scope))
+	}
+
+	if !installer.Installed(systemd.SystemScope) {
+		fmt.Fprintln(env.Stdout, "Blocking also needs the root helper: sudo lockin install-service --system")
+	}
+	return nil
+}
+
+func runUninstallService(env *Env, args []string) error {
+	fs := newFlagSet(env, "uninstall-service")
+	system := fs.Bool("system", false, "only the root helper's system unit")
+	user := fs.Bool("user", false, "only the daemon's user unit")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	installer, err := systemd.NewInstaller()
+	if err != nil {
+		return err
+	}
+	for _, scope := range scopeFlags(*system, *user, false) {
+		err := checkScopePrivileges(scope)
+		if err != nil {
+			return err
+		}
+		err = installer.Uninstall(scope)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(env.Stdout, "Removed %s\n", unitName(scope))
+	}
+	return nil
+}
+
+// The system unit needs root, and the user unit belongs to the desktop
+// user, so each half is managed from its own run.
+func checkScopePrivileges(scope systemd.Scope) error {
+	root := os.Geteuid() == 0
+	if scope == systemd.SystemScope && !root {
+		return errors.New("the system unit needs root: rerun with sudo and --system")
+	}
+	if scope == systemd.UserScope && root {
+		return errors.New("the user unit must be managed by the desktop user: rerun without sudo")
+	}
+	return nil
+}
+
+func unitName(scope systemd.Scope) string {
+	if scope == systemd.SystemScope {
+		return systemd.HelperUnitName
+	}
+	return systemd.DaemonUnitName
+}
+
+// defaultAllowUID is the user who invoked sudo, or the current user.
+func defaultAllowUID() string {
+	if uid := os.Getenv("SUDO_UID"); uid != "" {
+		return uid
+	}
+	if uid := os.Getuid(); uid != 0 {
+		return strconv.Itoa(uid)
+	}
+	return ""
+}
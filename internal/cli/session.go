@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+func init() {
+	register(command{
+		name:    "start",
+		summary: "start a focus session, e.g. lockin start 50m --profile work",
+		run:     runStart,
+	})
+	register(command{
+		name:    "status",
+		summary: "show the running session",
+		run:     runStatus,
+	})
+	register(command{
+		name:    "stop",
+		summary: "end the running session early (not allowed for strict sessions)",
+		run:     runStop,
+	})
+	register(command{
+		name:    "extend",
+		summary: "add time to the running session, e.g. lockin extend 20m",
+		run:     runExtend,
+	})
+	register(command{
+		name:    "history",
+		summary: "list recent sessions",
+		run:     runHistory,
+	})
+}
+
+func runStart(env *Env, args []string) error {
+	fs := newFlagSet(env, "start")
+	profile := fs.String("profile", "", "profile to file the session under")
+	strict := fs.Bool("strict", false, "refuse to stop the session early")
+	breakFlag := fs.String("break", "", "take a break this long once the session completes, e.g. 5m")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var opts service.StartOptions
+	opts.Profile = *profile
+	opts.Strict = *strict
+	if *breakFlag != "" {
+		opts.Break, err = service.ParseBreak(*breakFlag)
+		if err != nil {
+			return err
+		}
+	}
+	switch len(positional) {
+	case 0:
+		if *profile == "" {
+			fmt.Fprintln(env.Stderr, "Usage: lockin start <duration> [--profile name] [--strict] [--break length]")
+			return errUsage
+		}
+		// The profile's default duration is used.
+	case 1:
+		opts.Duration, err = service.ParseDuration(positional[0])
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("expected one duration, got %d arguments", len(positional))
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	session, err := svc.StartSession(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "Started %s session until %s\n",
+		models.FormatDurationExact(time.Duration(session.DurationSeconds)*time.Second), session.EndTime().Format("15:04"))
+	if session.BreakSeconds > 0 {
+		fmt.Fprintf(env.Stdout, "Then a %s break until %s\n",
+			models.FormatDurationExact(time.Duration(session.BreakSeconds)*time.Second), session.BreakEndTime().Format("15:04"))
+	}
+	if _, local := svc.(*service.Local); local {
+		fmt.Fprintln(env.Stdout, "lockind isn't running, so nothing is blocked until it starts.")
+	}
+	return nil
+}
+
+func runStatus(env *Env, args []string) error {
+	fs := newFlagSet(env, "status")
+	format := outputFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	session, err := svc.ActiveSession()
+	if err != nil {
+		return err
+	}
+
+	now := env.Now()
+	out := StatusOutput{Version: OutputVersion, Active: session != nil}
+	if session != nil {
+		s := sessionOutput(*session, now)
+		out.Session = &s
+	}
+
+	return render(env.Stdout, *format, out, func() error {
+		if session == nil {
+			fmt.Fprintln(env.Stdout, "No active session")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Session running")
+		fmt.Fprintf(w, "  Remaining:\t%s\n", models.FormatDurationExact(time.Duration(session.RemainingAt(now))*time.Second))
+		fmt.Fprintf(w, "  Ends at:\t%s\n", session.EndTime().Format("15:04"))
+		fmt.Fprintf(w, "  Profile:\t%s\n", orDash(session.Profile))
+		fmt.Fprintf(w, "  Strict:\t%s\n", yesNo(session.Strict))
+		return w.Flush()
+	})
+}
+
+func runStop(env *Env, args []string) error {
+	fs := newFlagSet(env, "stop")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	err = svc.StopSession()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(env.Stdout, "Session stopped")
+	return nil
+}
+
+func runExtend(env *Env, args []string) error {
+	fs := newFlagSet(env, "extend")
+	shorten := fs.Bool("shorten", false, "take the time off instead (not allowed for strict sessions)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(env.Stderr, "Usage: lockin extend <duration> [--shorten]")
+		return errUsage
+	}
+	by, err := service.ParseExtension(positional[0])
+	if err != nil {
+		return err
+	}
+	if *shorten {
+		by = -by
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	session, err := svc.ExtendSession(by)
+	if err != nil {
+		return err
+	}
+
+	verb := "Added"
+	if *shorten {
+		verb = "Took off"
+	}
+	fmt.Fprintf(env.Stdout, "%s %s; the session now ends at %s\n",
+		verb, models.FormatDurationExact(by.Abs()), session.EndTime().Format("15:04"))
+	return nil
+}
+
+func runHistory(env *Env, args []string) error {
+	fs := newFlagSet(env, "history")
+	limit := fs.Int("limit", 20, "how many sessions to show")
+	format := outputFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *limit < 1 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	sessions, err := svc.History(*limit)
+	if err != nil {
+		return err
+	}
+
+	now := env.Now()
+	out := HistoryOutput{Version: OutputVersion, Sessions: []SessionOutput{}}
+	for _, session := range sessions {
+		out.Sessions = append(out.Sessions, sessionOutput(session, now))
+	}
+
+	return render(env.Stdout, *format, out, func() error {
+		if len(sessions) == 0 {
+			fmt.Fprintln(env.Stdout, "No sessions yet")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tPROFILE\tSTRICT\tSTATUS")
+		for _, session := range sessions {
+			status := "ended"
+			if session.Phase(now) == models.PhaseFocus {
+				status = "running"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				session.ID,
+				time.Unix(session.StartTime, 0).Format("2006-01-02 15:04"),
+				models.FormatDurationExact(time.Duration(session.DurationSeconds)*time.Second),
+				orDash(session.Profile),
+				yesNo(session.Strict),
+				status,
+			)
+		}
+		return w.Flush()
+	})
+}
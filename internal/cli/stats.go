@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+func init() {
+	register(command{
+		name:    "stats",
+		summary: "show focus totals, streaks and completion rate",
+		run:     runStats,
+	})
+}
+
+func runStats(env *Env, args []string) error {
+	fs := newFlagSet(env, "stats")
+	format := outputFlag(fs)
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	svc, err := env.Connect()
+	if err != nil {
+		return err
+	}
+	now := env.Now()
+	stats, err := svc.Stats(now)
+	if err != nil {
+		return err
+	}
+
+	return render(env.Stdout, *format, statsOutput(stats), func() error {
+		w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Focus")
+		fmt.Fprintf(w, "  Today:\t%s\n", formatSeconds(stats.TodaySeconds))
+		fmt.Fprintf(w, "  This week:\t%s\n", formatSeconds(stats.WeekSeconds))
+		fmt.Fprintf(w, "  This month:\t%s\n", formatSeconds(stats.MonthSeconds))
+		fmt.Fprintf(w, "  All time:\t%s over %s\n", formatSeconds(stats.TotalSeconds), plural(stats.Sessions, "session"))
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Streaks")
+		fmt.Fprintf(w, "  Current:\t%s\n", plural(stats.CurrentStreak, "day"))
+		fmt.Fprintf(w, "  Longest:\t%s\n", plural(stats.LongestStreak, "day"))
+		fmt.Fprintln(w)
+		if known := stats.Completed + stats.Aborted; known > 0 {
+			fmt.Fprintf(w, "Completion rate:\t%.0f%% (%d of %d)\n", stats.CompletionRate()*100, stats.Completed, known)
+		} else {
+			fmt.Fprintln(w, "Completion rate:\t-")
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, "Last 7 days")
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		for i := 6; i >= 0; i-- {
+			day := today.AddDate(0, 0, -i)
+			var seconds int64
+			for _, total := range stats.Daily {
+				if total.Start == day.Format(time.DateOnly) {
+					seconds = total.Seconds
+				}
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", day.Format("Mon 02 Jan"), formatSeconds(seconds))
+		}
+		return w.Flush()
+	})
+}
+
+func formatSeconds(seconds int64) string {
+	return models.FormatDurationExact(time.Duration(seconds) * time.Second)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func statsOutput(stats *service.Stats) StatsOutput {
+	return StatsOutput{
+		Version:        OutputVersion,
+		TodaySeconds:   stats.TodaySeconds,
+		WeekSeconds:    stats.WeekSeconds,
+		MonthSeconds:   stats.MonthSeconds,
+		TotalSeconds:   stats.TotalSeconds,
+		Sessions:       stats.Sessions,
+		CurrentStreak:  stats.CurrentStreak,
+		LongestStreak:  stats.LongestStreak,
+		Completed:      stats.Completed,
+		Aborted:        stats.Aborted,
+		CompletionRate: stats.CompletionRate(),
+		Daily:          statsPeriods(stats.Daily),
+		Weekly:         statsPeriods(stats.Weekly),
+		Monthly:        statsPeriods(stats.Monthly),
+	}
+}
+
+// statsPeriods copies storage totals into the output schema, never nil.
+func statsPeriods(totals []storage.FocusTotal) []PeriodOutput {
+	out := []PeriodOutput{}
+	for _, total := range totals {
+		out = append(out, PeriodOutput{Start: total.Start, Seconds: total.Seconds, Sessions: total.Sessions})
+	}
+	return out
+}
@@ -0,0 +1,457 @@
+// Package config reads LockIn's settings file, by default
+// ~/.config/lockin/config.toml. A missing file means all defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+type Config struct {
+	Session Session `toml:"session"`
+	Daemon  Daemon  `toml:"daemon"`
+	Blocker Blocker `toml:"blocker"`
+	UI      UI      `toml:"ui"`
+	Report  Report  `toml:"report"`
+	Notify  Notify  `toml:"notify"`
+	Hooks   Hooks   `toml:"hooks"`
+	// Keys remaps TUI actions, e.g. up = ["k", "up"]. Each entry replaces
+	// all of that action's default keys.
+	Keys map[string][]string `toml:"keys"`
+	// Profiles are created when lockind starts or reloads the file, unless
+	// a profile with the same name already exists.
+	Profiles []Profile `toml:"profiles"`
+
+	// lines remembers where each setting was written, for error messages.
+	lines locator
+}
+
+// Session holds defaults for new focus sessions.
+type Session struct {
+	// DefaultDuration is the length offered when none is given.
+	DefaultDuration Duration `toml:"default_duration"`
+}
+
+// Daemon holds settings for lockind.
+type Daemon struct {
+	// TickInterval is how often lockind re-checks the running session.
+	TickInterval Duration `toml:"tick_interval"`
+}
+
+// Blocker holds settings for how sites are blocked.
+type Blocker struct {
+	// Backend is "auto", "helper", "hosts" or "none". Auto writes the hosts
+	// file directly when lockind runs as root and goes through
+	// lockin-helper otherwise; none blocks nothing.
+	Backend string `toml:"backend"`
+	// RedirectIP is where blocked domains point in the hosts file.
+	RedirectIP string `toml:"redirect_ip"`
+	// HostsPath overrides the hosts file lockind writes when it writes the
+	// file itself. The helper always writes the system hosts file.
+	HostsPath string `toml:"hosts_path"`
+}
+
+// UI holds settings for the terminal interface.
+type UI struct {
+	// Theme is "auto", "dark", "light" or "high-contrast". Auto picks dark
+	// or light from the terminal background.
+	Theme string `toml:"theme"`
+	// Emoji turns the emoji in headings and markers on or off.
+	Emoji *bool `toml:"emoji"`
+}
+
+// Report holds settings for lockin report.
+type Report struct {
+	// Format is "markdown", "html" or "text".
+	Format string `toml:"format"`
+	// Dir is where lockin report --save writes reports.
+	Dir string `toml:"dir"`
+	// To is who lockin report --mail sends reports to, and From who they
+	// come from; an empty From leaves it to the mail command.
+	To   string `toml:"to"`
+	From string `toml:"from"`
+	// Sendmail is the command a mailed report is piped to, headers and
+	// all. Anything that reads a message on stdin like sendmail -t does
+	// will do.
+	Sendmail string `toml:"sendmail"`
+}
+
+// Notify holds settings for the desktop notifications lockind shows.
+type Notify struct {
+	// Backend is "auto", "dbus", "notify-send" or "none". Auto uses D-Bus
+	// and falls back to notify-send.
+	Backend string `toml:"backend"`
+}
+
+// Hooks holds the commands lockind runs as sessions and their breaks start
+// and end.
+type Hooks struct {
+	// Dir holds executables run on every event; empty means the hooks
+	// directory next to the config file.
+	Dir string `toml:"dir"`
+	// Timeout is how long each hook gets before it is killed.
+	Timeout Duration `toml:"timeout"`
+	// Start and End are shell commands run when a session starts and
+	// ends, e.g. start = ["playerctl play"], and BreakStart and BreakEnd
+	// when the break after it starts and ends.
+	Start      []string `toml:"start"`
+	End        []string `toml:"end"`
+	BreakStart []string `toml:"break_start"`
+	BreakEnd   []string `toml:"break_end"`
+}
+
+// Profile is a profile to create if it doesn't exist yet.
+type Profile struct {
+	Name     string   `toml:"name"`
+	Duration Duration `toml:"duration"`
+}
+
+// EmojiEnabled reports whether emoji should be shown; they are on unless
+// turned off.
+func (u UI) EmojiEnabled() bool {
+	return u.Emoji == nil || *u.Emoji
+}
+
+// Blocking backends.
+const (
+	BackendAuto   = "auto"
+	BackendHelper = "helper"
+	BackendHosts  = "hosts"
+	BackendNone   = "none"
+)
+
+var backends = []string{BackendAuto, BackendHelper, BackendHosts, BackendNone}
+
+// reportFormats are the formats lockin report renders.
+var reportFormats = []string{"markdown", "html", "text"}
+
+// notifiers are the backends notify.New accepts.
+var notifiers = []string{"auto", "dbus", "notify-send", "none"}
+
+// themes are the names theme.Resolve accepts.
+var themes = []string{"auto", "dark", "light", "high-contrast"}
+
+// Session lengths outside these bounds are refused when a session starts,
+// so they are refused here too.
+const (
+	minSession = time.Minute
+	maxSession = 12 * time.Hour
+)
+
+func Default() Config {
+	return Config{
+		Session: Session{DefaultDuration: Duration{25 * time.Minute}},
+		Daemon:  Daemon{TickInterval: Duration{5 * time.Second}},
+		Blocker: Blocker{Backend: BackendAuto, RedirectIP: "127.0.0.1"},
+		UI:      UI{Theme: "auto"},
+		Report:  Report{Format: "markdown", Sendmail: "sendmail -t -i"},
+		Notify:  Notify{Backend: "auto"},
+		Hooks:   Hooks{Timeout: Duration{10 * time.Second}},
+	}
+}
+
+// Path returns where the config file lives: $LOCKIN_CONFIG if set, else
+// lockin/config.toml under the XDG config directory.
+func Path() string {
+	if path := os.Getenv("LOCKIN_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "lockin", "config.toml")
+}
+
+// Load reads the config at path over the defaults. A file that doesn't
+// exist isn't an error. A file that can't be parsed gives the defaults;
+// settings that parse but aren't valid fall back to their defaults one by
+// one. Either way the error is a *ValidationError listing every problem
+// with its line.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), err
+	}
+	return Parse(path, string(data))
+}
+
+// Parse reads a config file's contents; path is only used in errors.
+func Parse(path, data string) (Config, error) {
+	cfg := Default()
+
+	lines := newLocator(data)
+	md, err := toml.Decode(data, &cfg)
+	if err != nil {
+		problem := decodeProblem(err)
+		// Syntax errors name the last key read, which may be lines away.
+		if lines.line(problem.Key) != problem.Line {
+			problem.Key = ""
+		}
+		return Default(), &ValidationError{Path: path, Problems: []Problem{problem}}
+	}
+	cfg.lines = lines
+
+	var problems []Problem
+	for _, key := range md.Undecoded() {
+		problems = append(problems, cfg.problem(key.String(), "unknown setting"))
+	}
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Path: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Line returns the line key was set on, or 0 if it wasn't set. Keys are
+// dotted, as in "blocker.backend" or "profiles.2.name".
+func (c Config) Line(key string) int {
+	return c.lines.line(key)
+}
+
+// validate checks each setting, putting invalid ones back to their default.
+func (c *Config) validate() []Problem {
+	var problems []Problem
+	def := Default()
+
+	if d := c.Session.DefaultDuration.Duration; d < minSession || d > maxSession {
+		problems = append(problems, c.problem("session.default_duration",
+			fmt.Sprintf("%s is out of range (%s to %s)", d, minSession, maxSession)))
+		c.Session.DefaultDuration = def.Session.DefaultDuration
+	}
+
+	if d := c.Daemon.TickInterval.Duration; d < time.Second || d > time.Minute {
+		problems = append(problems, c.problem("daemon.tick_interval",
+			fmt.Sprintf("%s is out of range (1s to 1m)", d)))
+		c.Daemon.TickInterval = def.Daemon.TickInterval
+	}
+
+	if !contains(backends, c.Blocker.Backend) {
+		problems = append(problems, c.problem("blocker.backend",
+			fmt.Sprintf("unknown backend %q (want %s)", c.Blocker.Backend, strings.Join(backends, ", "))))
+		c.Blocker.Backend = def.Blocker.Backend
+	}
+
+	if err := ValidateRedirectIP(c.Blocker.RedirectIP); err != nil {
+		problems = append(problems, c.problem("blocker.redirect_ip", err.Error()))
+		c.Blocker.RedirectIP = def.Blocker.RedirectIP
+	}
+
+	if c.Blocker.HostsPath != "" && !filepath.IsAbs(c.Blocker.HostsPath) {
+		problems = append(problems, c.problem("blocker.hosts_path",
+			fmt.Sprintf("%q isn't an absolute path", c.Blocker.HostsPath)))
+		c.Blocker.HostsPath = def.Blocker.HostsPath
+	}
+
+	if c.UI.Theme == "" {
+		c.UI.Theme = def.UI.Theme
+	}
+	if !contains(themes, c.UI.Theme) {
+		problems = append(problems, c.problem("ui.theme",
+			fmt.Sprintf("unknown theme %q (want %s)", c.UI.Theme, strings.Join(themes, ", "))))
+		c.UI.Theme = def.UI.Theme
+	}
+
+	if !contains(reportFormats, c.Report.Format) {
+		problems = append(problems, c.problem("report.format",
+			fmt.Sprintf("unknown format %q (want %s)", c.Report.Format, strings.Join(reportFormats, ", "))))
+		c.Report.Format = def.Report.Format
+	}
+
+	if c.Report.Dir != "" && !filepath.IsAbs(c.Report.Dir) {
+		problems = append(problems, c.problem("report.dir",
+			fmt.Sprintf("%q isn't an absolute path", c.Report.Dir)))
+		c.Report.Dir = def.Report.Dir
+	}
+
+	for _, address := range []struct {
+		key   string
+		value *string
+	}{{"report.to", &c.Report.To}, {"report.from", &c.Report.From}} {
+		if *address.value == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(*address.value); err != nil {
+			problems = append(problems, c.problem(address.key, fmt.Sprintf("%q isn't an email address", *address.value)))
+			*address.value = ""
+		}
+	}
+
+	if strings.TrimSpace(c.Report.Sendmail) == "" {
+		problems = append(problems, c.problem("report.sendmail", "no command given"))
+		c.Report.Sendmail = def.Report.Sendmail
+	}
+
+	if !contains(notifiers, c.Notify.Backend) {
+		problems = append(problems, c.problem("notify.backend",
+			fmt.Sprintf("unknown backend %q (want %s)", c.Notify.Backend, strings.Join(notifiers, ", "))))
+		c.Notify.Backend = def.Notify.Backend
+	}
+
+	if c.Hooks.Dir != "" && !filepath.IsAbs(c.Hooks.Dir) {
+		problems = append(problems, c.problem("hooks.dir",
+			fmt.Sprintf("%q isn't an absolute path", c.Hooks.Dir)))
+		c.Hooks.Dir = def.Hooks.Dir
+	}
+
+	if d := c.Hooks.Timeout.Duration; d < time.Second || d > 5*time.Minute {
+		problems = append(problems, c.problem("hooks.timeout",
+			fmt.Sprintf("%s is out of range (1s to 5m)", d)))
+		c.Hooks.Timeout = def.Hooks.Timeout
+	}
+
+	for _, hook := range []struct {
+		key      string
+		commands *[]string
+	}{
+		{"hooks.start", &c.Hooks.Start},
+		{"hooks.end", &c.Hooks.End},
+		{"hooks.break_start", &c.Hooks.BreakStart},
+		{"hooks.break_end", &c.Hooks.BreakEnd},
+	} {
+		var commands []string
+		for _, command := range *hook.commands {
+			if strings.TrimSpace(command) == "" {
+				problems = append(problems, c.problem(hook.key, "empty command"))
+				continue
+			}
+			commands = append(commands, command)
+		}
+		*hook.commands = commands
+	}
+
+	var profiles []Profile
+	seen := make(map[string]bool)
+	for i, profile := range c.Profiles {
+		key := fmt.Sprintf("profiles.%d", i+1)
+		profile.Name = strings.TrimSpace(profile.Name)
+
+		switch d := profile.Duration.Duration; {
+		case profile.Name == "":
+			problems = append(problems, c.problem(key, "profile has no name"))
+		case seen[profile.Name]:
+			problems = append(problems, c.problem(key+".name", fmt.Sprintf("profile %q is defined twice", profile.Name)))
+		case d == 0:
+			problems = append(problems, c.problem(key, fmt.Sprintf("profile %q has no duration", profile.Name)))
+		case d < minSession || d > maxSession:
+			problems = append(problems, c.problem(key+".duration",
+				fmt.Sprintf("%s is out of range (%s to %s)", d, minSession, maxSession)))
+		default:
+			seen[profile.Name] = true
+			profiles = append(profiles, profile)
+		}
+	}
+	c.Profiles = profiles
+
+	return problems
+}
+
+// ValidateRedirectIP accepts only addresses that lead nowhere: loopback or
+// unspecified, like 127.0.0.1 or 0.0.0.0. The hosts file is shared by every
+// user, so pointing sites at a real host isn't allowed.
+func ValidateRedirectIP(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return fmt.Errorf("%q isn't an IP address", s)
+	}
+	if !ip.IsLoopback() && !ip.IsUnspecified() {
+		return fmt.Errorf("%s isn't a loopback or unspecified address", s)
+	}
+	return nil
+}
+
+func (c Config) problem(key, message string) Problem {
+	return Problem{Line: c.Line(key), Key: key, Message: message}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+//***********************************************************//
+// Errors
+//***********************************************************//
+
+// Problem is one thing wrong with a config file.
+type Problem struct {
+	// Line is 0 when the line isn't known.
+	Line    int
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Key != "" {
+		b.WriteString(p.Key + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidationError lists everything wrong with a config file.
+type ValidationError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = e.Path + ": " + p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// decodeProblem turns a TOML error into a Problem, keeping its line.
+func decodeProblem(err error) Problem {
+	var pe toml.ParseError
+	if errors.As(err, &pe) {
+		return Problem{Line: pe.Position.Line, Key: pe.LastKey, Message: pe.Message}
+	}
+	return Problem{Message: strings.TrimPrefix(err.Error(), "toml: ")}
+}
+
+//***********************************************************//
+// Durations
+//***********************************************************//
+
+// Duration is a time.Duration written as a string such as "25m" or "1h30m".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (try 25m or 1h30m)", text)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test Load - missing files fall back to defaults, present ones override them
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantTheme string
+		wantEmoji bool
+		wantErr   bool
+	}{
+		{"missing file", "", "auto", true, false},
+		{"theme set", "[ui]\ntheme = \"light\"\n", "light", true, false},
+		{"emoji off", "[ui]\nemoji = false\n", "auto", false, false},
+		{"empty theme", "[ui]\ntheme = \"\"\n", "auto", true, false},
+		{"bad toml", "[ui\n", "auto", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if tt.content != "" {
+				err := os.WriteFile(path, []byte(tt.content), 0644)
+				if err != nil {
+					t.Fatalf("Failed to write config: %v", err)
+				}
+			}
+
+			cfg, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cfg.UI.Theme != tt.wantTheme {
+				t.Errorf("Theme = %q, want %q", cfg.UI.Theme, tt.wantTheme)
+			}
+			if cfg.UI.EmojiEnabled() != tt.wantEmoji {
+				t.Errorf("EmojiEnabled() = %v, want %v", cfg.UI.EmojiEnabled(), tt.wantEmoji)
+			}
+		})
+	}
+}
+
+// Test Load keys - remapped actions are read from the [keys] table
+func TestLoadKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte("[keys]\nup = [\"w\", \"up\"]\nquit = [\"Q\"]\n"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.Keys["up"]; len(got) != 2 || got[0] != "w" || got[1] != "up" {
+		t.Errorf("Keys[up] = %q, want [w up]", got)
+	}
+	if got := cfg.Keys["quit"]; len(got) != 1 || got[0] != "Q" {
+		t.Errorf("Keys[quit] = %q, want [Q]", got)
+	}
+}
+
+// Test Load settings - every section is read over the defaults
+func TestLoadSettings(t *testing.T) {
+	content := `[session]
+default_duration = "50m"
+
+[daemon]
+tick_interval = "10s"
+
+[blocker]
+backend = "hosts"
+redirect_ip = "0.0.0.0"
+hosts_path = "/tmp/hosts"
+
+[report]
+format = "html"
+dir = "/tmp/reports"
+to = "Sam <sam@example.com>"
+
+[notify]
+backend = "notify-send"
+
+[hooks]
+timeout = "30s"
+start = ["slack-status focus", "playerctl play"]
+break_start = ["playerctl pause"]
+
+[[profiles]]
+name = "work"
+duration = "1h30m"
+
+[[profiles]]
+name = "study"
+duration = "45m"
+`
+	cfg, err := Parse("config.toml", content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := cfg.Session.DefaultDuration.Duration; got != 50*time.Minute {
+		t.Errorf("DefaultDuration = %v, want 50m", got)
+	}
+	if got := cfg.Daemon.TickInterval.Duration; got != 10*time.Second {
+		t.Errorf("TickInterval = %v, want 10s", got)
+	}
+	want := Blocker{Backend: BackendHosts, RedirectIP: "0.0.0.0", HostsPath: "/tmp/hosts"}
+	if cfg.Blocker != want {
+		t.Errorf("Blocker = %+v, want %+v", cfg.Blocker, want)
+	}
+	wantReport := Report{Format: "html", Dir: "/tmp/reports", To: "Sam <sam@example.com>", Sendmail: "sendmail -t -i"}
+	if cfg.Report != wantReport {
+		t.Errorf("Report = %+v, want %+v", cfg.Report, wantReport)
+	}
+	if cfg.Notify.Backend != "notify-send" {
+		t.Errorf("Notify.Backend = %q, want notify-send", cfg.Notify.Backend)
+	}
+	if cfg.Hooks.Timeout.Duration != 30*time.Second || len(cfg.Hooks.Start) != 2 || cfg.Hooks.Start[1] != "playerctl play" || cfg.Hooks.Dir != "" {
+		t.Errorf("Hooks = %+v, want a 30s timeout and two start commands", cfg.Hooks)
+	}
+	if len(cfg.Hooks.BreakStart) != 1 || cfg.Hooks.BreakStart[0] != "playerctl pause" {
+		t.Errorf("Hooks.BreakStart = %q, want [playerctl pause]", cfg.Hooks.BreakStart)
+	}
+	if len(cfg.Profiles) != 2 || cfg.Profiles[0].Name != "work" || cfg.Profiles[0].Duration.Duration != 90*time.Minute {
+		t.Errorf("Profiles = %+v, want work 1h30m and study 45m", cfg.Profiles)
+	}
+}
+
+// Test Parse problems - each problem is reported with its line, and the
+// setting falls back to its default
+func TestParseProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		check   func(cfg Config) bool
+	}{
+		{
+			name:    "syntax error",
+			content: "[ui]\ntheme = \"dark\"\n[blocker\n",
+			want:    []string{"config.toml: line 4: expected"},
+			check:   func(cfg Config) bool { return cfg.UI.Theme == "auto" },
+		},
+		{
+			name:    "bad duration",
+			content: "\n[session]\ndefault_duration = \"soon\"\n",
+			want:    []string{`config.toml: line 3: session.default_duration: invalid duration "soon" (try 25m or 1h30m)`},
+		},
+		{
+			name:    "wrong type",
+			content: "[daemon]\ntick_interval = 5\n",
+			want:    []string{"line 2"},
+		},
+		{
+			name:    "unknown setting",
+			content: "[ui]\ntheme = \"dark\"\ncolour = \"red\"\n",
+			want:    []string{"config.toml: line 3: ui.colour: unknown setting"},
+			check:   func(cfg Config) bool { return cfg.UI.Theme == "dark" },
+		},
+		{
+			name:    "out of range",
+			content: "[session]\ndefault_duration = \"13h\"\n[daemon]\ntick_interval = \"100ms\"\n",
+			want: []string{
+				"config.toml: line 2: session.default_duration: 13h0m0s is out of range (1m0s to 12h0m0s)",
+				"config.toml: line 4: daemon.tick_interval: 100ms is out of range (1s to 1m)",
+			},
+			check: func(cfg Config) bool { return cfg.Session.DefaultDuration.Duration == 25*time.Minute },
+		},
+		{
+			name:    "bad blocker",
+			content: "[blocker]\nbackend = \"iptables\"\nredirect_ip = \"93.184.216.34\"\nhosts_path = \"hosts\"\n",
+			want: []string{
+				`line 2: blocker.backend: unknown backend "iptables" (want auto, helper, hosts, none)`,
+				"line 3: blocker.redirect_ip: 93.184.216.34 isn't a loopback or unspecified address",
+				`line 4: blocker.hosts_path: "hosts" isn't an absolute path`,
+			},
+			check: func(cfg Config) bool { return cfg.Blocker == Default().Blocker },
+		},
+		{
+			name:    "bad report",
+			content: "[report]\nformat = \"pdf\"\ndir = \"reports\"\nto = \"my partner\"\nsendmail = \" \"\n",
+			want: []string{
+				`line 2: report.format: unknown format "pdf" (want markdown, html, text)`,
+				`line 3: report.dir: "reports" isn't an absolute path`,
+				`line 4: report.to: "my partner" isn't an email address`,
+				"line 5: report.sendmail: no command given",
+			},
+			check: func(cfg Config) bool { return cfg.Report == Default().Report },
+		},
+		{
+			name:    "unknown notifier",
+			content: "[notify]\nbackend = \"growl\"\n",
+			want:    []string{`line 2: notify.backend: unknown backend "growl" (want auto, dbus, notify-send, none)`},
+			check:   func(cfg Config) bool { return cfg.Notify == Default().Notify },
+		},
+		{
+			name:    "bad hooks",
+			content: "[hooks]\ndir = \"hooks\"\ntimeout = \"1h\"\nend = [\"slack-status clear\", \" \"]\nbreak_end = [\"\"]\n",
+			want: []string{
+				`line 2: hooks.dir: "hooks" isn't an absolute path`,
+				"line 3: hooks.timeout: 1h0m0s is out of range (1s to 5m)",
+				"line 4: hooks.end: empty command",
+				"line 5: hooks.break_end: empty command",
+			},
+			check: func(cfg Config) bool {
+				return cfg.Hooks.Dir == "" && cfg.Hooks.Timeout == Default().Hooks.Timeout && len(cfg.Hooks.End) == 1
+			},
+		},
+		{
+			name:    "unknown theme",
+			content: "[ui]\ntheme = \"solarized\"\n",
+			want:    []string{`line 2: ui.theme: unknown theme "solarized"`},
+		},
+		{
+			name: "bad profiles",
+			content: "[[profiles]]\nname = \"work\"\nduration = \"50m\"\n\n" +
+				"[[profiles]]\nduration = \"50m\"\n\n" +
+				"[[profiles]]\nname = \"work\"\nduration = \"1h\"\n\n" +
+				"[[profiles]]\nname = \"study\"\n\n" +
+				"[[profiles]]\nname = \"nap\"\nduration = \"20s\"\nsnooze = true\n",
+			want: []string{
+				"line 18: profiles.snooze: unknown setting",
+				"line 5: profiles.2: profile has no name",
+				`line 9: profiles.3.name: profile "work" is defined twice`,
+				`line 12: profiles.4: profile "study" has no duration`,
+				"line 17: profiles.5.duration: 20s is out of range",
+			},
+			check: func(cfg Config) bool { return len(cfg.Profiles) == 1 && cfg.Profiles[0].Name == "work" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse("config.toml", tt.content)
+
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Parse() error = %v, want a *ValidationError", err)
+			}
+			if len(invalid.Problems) != len(tt.want) {
+				t.Fatalf("got %d problems, want %d:\n%v", len(invalid.Problems), len(tt.want), err)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, lines[i], want)
+				}
+			}
+			if tt.check != nil && !tt.check(cfg) {
+				t.Errorf("unexpected config after problems: %+v", cfg)
+			}
+		})
+	}
+}
+
+// Test Watch - a change to the file is loaded and passed on
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	write := func(content string) {
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+	write("[ui]\ntheme = \"dark\"\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := make(chan Config, 1)
+	errs := make(chan error, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func(cfg Config, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		reloads <- cfg
+	})
+
+	time.Sleep(30 * time.Millisecond)
+	write("[ui]\ntheme = \"light\"\n# changed\n")
+	select {
+	case cfg := <-reloads:
+		if cfg.UI.Theme != "light" {
+			t.Errorf("reloaded theme = %q, want light", cfg.UI.Theme)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("change wasn't noticed")
+	}
+
+	write("[ui\n")
+	select {
+	case <-errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalid file wasn't reported")
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// locator maps dotted keys to the line they were set on. The TOML decoder
+// only reports lines for syntax errors, so settings that parse but aren't
+// valid are found again by scanning the file. Entries of arrays of tables
+// are numbered from 1, as in profiles.2.name; the unnumbered key gives the
+// first entry that sets it.
+type locator map[string]int
+
+func newLocator(data string) locator {
+	l := locator{}
+	counts := make(map[string]int)
+	// table is the current table with array entries numbered, plain the
+	// same without the numbers.
+	table, plain := "", ""
+
+	for i, raw := range strings.Split(data, "\n") {
+		n := i + 1
+		line := strings.TrimSpace(raw)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, "[["):
+			end := strings.Index(line, "]]")
+			if end < 0 {
+				continue
+			}
+			plain = keyPath(line[2:end])
+			counts[plain]++
+			table = fmt.Sprintf("%s.%d", plain, counts[plain])
+			l.add(table, n)
+			l.add(plain, n)
+
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			table = keyPath(line[1:end])
+			plain = table
+			l.add(table, n)
+
+		default:
+			eq := strings.Index(line, "=")
+			if eq <= 0 {
+				continue
+			}
+			key := keyPath(line[:eq])
+			l.add(joinKey(table, key), n)
+			l.add(joinKey(plain, key), n)
+		}
+	}
+	return l
+}
+
+func (l locator) add(key string, line int) {
+	if _, ok := l[key]; !ok {
+		l[key] = line
+	}
+}
+
+// line returns where key was set, or 0 if it wasn't.
+func (l locator) line(key string) int {
+	return l[key]
+}
+
+// keyPath normalizes a possibly dotted and quoted TOML key.
+func keyPath(s string) string {
+	parts := strings.Split(s, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+func joinKey(table, key string) string {
+	if table == "" {
+		return key
+	}
+	return table + "." + key
+}
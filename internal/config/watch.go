@@ -0,0 +1,47 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch checks the file at path every interval until ctx is cancelled and
+// calls reload with the freshly loaded config whenever it has changed,
+// including being created or removed. A file that doesn't load is passed
+// on with its error, so the caller decides whether to keep what it has.
+func Watch(ctx context.Context, path string, interval time.Duration, reload func(Config, error)) {
+	last := stamp(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := stamp(path)
+			if current == last {
+				continue
+			}
+			last = current
+			reload(Load(path))
+		}
+	}
+}
+
+// fileStamp is what Watch compares to notice a change.
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func stamp(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
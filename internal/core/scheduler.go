@@ -1,60 +1,412 @@
 package core
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"sync"
 	"time"
+
 	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/hooks"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/notify"
 	"github.com/youssef28m/LockIn/internal/storage"
 )
 
+// DefaultInterval is how often the scheduler re-checks the sessions table.
+const DefaultInterval = 5 * time.Second
 
+// WarnBefore is how long before a session ends its warning is shown.
+const WarnBefore = 5 * time.Minute
 
-func InitializeScheduler(db *sql.DB) {
-	
-	sessions, err := storage.GetAllSessions(db)
+// Enforcer applies and lifts the block on a set of domains.
+type Enforcer interface {
+	Apply(domains []string) error
+	Clear() error
+}
+
+// Inspector is implemented by enforcers that can read back the block in
+// place, so the scheduler notices when it was undone behind its back.
+type Inspector interface {
+	Blocked() ([]string, error)
+}
+
+// Scheduler keeps the enforcer in line with the sessions table: it blocks
+// while a session is running and unblocks once it expires or is stopped.
+// It also tells the desktop when a session starts, is about to end and
+// completes, and when the break after it starts and ends, and runs the
+// user's hooks on each of those but the warning.
+type Scheduler struct {
+	db *sql.DB
+
+	mu       sync.Mutex
+	enforcer Enforcer
+	notifier notify.Notifier
+	hooks    hooks.Hooks
+	interval time.Duration
+	blocking bool
+	// applied is the block set last applied while blocking.
+	applied []string
+
+	// synced is set after the first sync. Sessions already running then
+	// were announced by an earlier lockind, if at all.
+	synced bool
+	// current is the running session's ID, and warned whether its warning
+	// has been shown.
+	current int64
+	warned  bool
+	// resting is the ID of the session whose break is running.
+	resting int64
+	// hooksDone is closed once the hooks last queued have run. Each batch
+	// waits for the one before it, so a session's end hooks never overtake
+	// its start hooks.
+	hooksDone chan struct{}
+}
+
+func NewScheduler(db *sql.DB, enforcer Enforcer) *Scheduler {
+	return &Scheduler{db: db, enforcer: enforcer, notifier: notify.Nop{}, interval: DefaultInterval}
+}
+
+// Blocking reports whether the enforcer currently holds a block.
+func (s *Scheduler) Blocking() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blocking
+}
+
+// SetInterval changes how often Run syncs, from the next tick on.
+func (s *Scheduler) SetInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = d
+}
+
+// SetEnforcer swaps the enforcer, lifting any block the old one holds. The
+// new one takes over at the next sync.
+func (s *Scheduler) SetEnforcer(enforcer Enforcer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.blocking {
+		err := s.enforcer.Clear()
+		if err != nil {
+			log.Println("Error unblocking websites:", err)
+		}
+		s.blocking = false
+		s.applied = nil
+	}
+	s.enforcer = enforcer
+}
+
+// SetNotifier swaps the notifier used from the next sync on.
+func (s *Scheduler) SetNotifier(notifier notify.Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifier = notifier
+}
+
+// SetHooks swaps the hooks run from the next session or break start or end
+// on.
+func (s *Scheduler) SetHooks(h hooks.Hooks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = h
+}
+
+// Run syncs once, then on every tick until ctx is cancelled. Any block still
+// in place is left alone on exit so restarting the daemon doesn't open a gap.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.Sync()
+
+	for {
+		s.mu.Lock()
+		interval := s.interval
+		s.mu.Unlock()
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.Sync()
+		}
+	}
+}
+
+// Sync expires finished sessions and applies or clears the block to match
+// whether a session is still running. Notifications are sent once the
+// scheduler is unlocked, so a slow desktop doesn't hold it up.
+func (s *Scheduler) Sync() {
+	s.mu.Lock()
+	notifier := s.notifier
+	notes := s.sync()
+	s.mu.Unlock()
+
+	for _, n := range notes {
+		err := notifier.Notify(n)
+		if err != nil {
+			log.Println("Error showing notification:", err)
+		}
+	}
+}
+
+func (s *Scheduler) sync() []notify.Notification {
+	sessions, err := storage.GetAllSessions(s.db)
 	if err != nil {
 		log.Println("Error fetching sessions:", err)
-		return
+		return nil
 	}
-	for _, session := range sessions {
-		if session.Active && !session.Expired() {
-			// block websites/apps
-			err := blocker.BlockWebsites(db)
+	now := time.Now()
+	var notes []notify.Notification
+
+	var running *models.Session
+	completed := make(map[int64]bool)
+	for i := range sessions {
+		session := &sessions[i]
+		if !session.Active {
+			continue
+		}
+		if !session.Expired() {
+			running = session
+			continue
+		}
+
+		ended := *session
+		ended.Complete()
+		_, err = storage.RecordSession(s.db, ended, models.NewEvent(models.EventCompleted, time.Unix(ended.EndedAt, 0), nil))
+		if err != nil {
+			log.Println("Error updating session:", err)
+			continue
+		}
+		*session = ended
+		completed[session.ID] = true
+		// A session going straight into its break is announced with it.
+		if session.Phase(now) != models.PhaseBreak {
+			notes = append(notes, notify.Notification{
+				Summary: "Focus session complete",
+				Body:    fmt.Sprintf("You focused for %s. Sites are unblocked.", focused(*session)),
+			})
+		}
+	}
+
+	// A break cut short by a new session ends before the session starts,
+	// and a break starts after the session before it has ended.
+	resting := onBreak(sessions, running, now)
+	notes = append(notes, s.endBreak(resting, running)...)
+	notes = append(notes, s.progress(running, now)...)
+	notes = append(notes, s.startBreak(resting, completed, now)...)
+	s.synced = true
+
+	if running == nil {
+		if s.blocking {
+			err := s.enforcer.Clear()
 			if err != nil {
-				log.Println("Error blocking websites:", err)
+				log.Println("Error unblocking websites:", err)
+				return notes
 			}
+			s.blocking = false
+			s.applied = nil
 		}
+		return notes
 	}
 
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	if s.blocking {
+		s.checkTamper(*running)
+	}
 
-	for range ticker.C {
-		sessions, err := storage.GetAllSessions(db)
-		if err != nil {
-			log.Println("Error fetching sessions:", err)
-			return
+	// Re-apply on every tick so sites added mid-session are picked up.
+	sites, err := storage.GetAllBlockedSites(s.db)
+	if err != nil {
+		log.Println("Error fetching blocked sites:", err)
+		return notes
+	}
+	domains := make([]string, 0, len(sites))
+	for _, site := range sites {
+		domains = append(domains, site.Domain)
+	}
+
+	err = s.enforcer.Apply(domains)
+	if err != nil {
+		log.Println("Error blocking websites:", err)
+		return notes
+	}
+	s.blocking = true
+	s.applied = domains
+	return notes
+}
+
+// progress follows the running session from sync to sync. It runs the
+// hooks when a session starts or ends, and returns what to announce: that
+// a session started, if it is new since the last sync, and that it is about
+// to end once it gets within WarnBefore. A session extended past that gets
+// warned again.
+func (s *Scheduler) progress(running *models.Session, now time.Time) []notify.Notification {
+	var notes []notify.Notification
+	if s.current != 0 && (running == nil || running.ID != s.current) {
+		ended, err := storage.GetSessionByID(s.db, s.current)
+		if err == nil && ended != nil {
+			s.queueHooks(hooks.EventEnd, *ended)
 		}
+	}
+	if running == nil {
+		s.current, s.warned = 0, false
+		return nil
+	}
+
+	if running.ID != s.current {
+		s.current, s.warned = running.ID, false
+		if s.synced {
+			body := fmt.Sprintf("%s until %s.", models.FormatDuration(time.Duration(running.DurationSeconds)*time.Second), running.EndTime().In(now.Location()).Format("15:04"))
+			if running.Strict {
+				body += " It's strict, so it can't be stopped early."
+			}
+			notes = append(notes, notify.Notification{Summary: "Focus session started", Body: body})
+			s.queueHooks(hooks.EventStart, *running)
+		}
+	}
+
+	remaining := time.Duration(running.RemainingAt(now)) * time.Second
+	switch {
+	case remaining > WarnBefore:
+		s.warned = false
+	case !s.warned && time.Duration(running.DurationSeconds)*time.Second > WarnBefore:
+		s.warned = true
+		notes = append(notes, notify.Notification{
+			Summary: fmt.Sprintf("%d minutes left", WarnBefore/time.Minute),
+			Body:    fmt.Sprintf("The focus session ends at %s.", running.EndTime().In(now.Location()).Format("15:04")),
+		})
+	}
+	return notes
+}
+
+// onBreak returns the session whose break is running, if no session is.
+func onBreak(sessions []models.Session, running *models.Session, now time.Time) *models.Session {
+	if running != nil {
+		return nil
+	}
+	var resting *models.Session
+	for i := range sessions {
+		if sessions[i].Phase(now) == models.PhaseBreak && (resting == nil || sessions[i].EndedAt > resting.EndedAt) {
+			resting = &sessions[i]
+		}
+	}
+	return resting
+}
+
+// endBreak runs the break end hooks once the break being followed is over,
+// and returns a notification saying so if it ran its length. A session
+// started during a break ends it without a word.
+func (s *Scheduler) endBreak(resting, running *models.Session) []notify.Notification {
+	if s.resting == 0 || resting != nil && resting.ID == s.resting {
+		return nil
+	}
+	over, err := storage.GetSessionByID(s.db, s.resting)
+	s.resting = 0
+	if err != nil || over == nil {
+		return nil
+	}
+
+	s.queueHooks(hooks.EventBreakEnd, *over)
+	if running != nil {
+		return nil
+	}
+	return []notify.Notification{{Summary: "Break over", Body: "Time to start the next session."}}
+}
 
-		for _, session := range sessions {
-			if session.Active && session.Expired() {
-				session.Stop()
-				
-				// unblock websites/apps
-				err := blocker.UnblockWebsites(db)
-				if err != nil {
-					log.Println("Error unblocking websites:", err)
-				}
-				
-				err = storage.UpdateSession(db, session)
-				if err != nil {
-					log.Println("Error updating session:", err)
-                    continue
-				} 
+// startBreak follows a new break, running the break start hooks and
+// announcing it for sessions completed since the last sync.
+func (s *Scheduler) startBreak(resting *models.Session, completed map[int64]bool, now time.Time) []notify.Notification {
+	if resting == nil || resting.ID == s.resting {
+		return nil
+	}
+	s.resting = resting.ID
+	if !s.synced && !completed[resting.ID] {
+		return nil
+	}
+
+	s.queueHooks(hooks.EventBreakStart, *resting)
+	return []notify.Notification{{
+		Summary: "Focus session complete, break started",
+		Body: fmt.Sprintf("You focused for %s. Sites are unblocked for your break until %s.",
+			focused(*resting), resting.BreakEndTime().In(now.Location()).Format("15:04")),
+	}}
+}
+
+// focused is how long session was meant to run, for notifications.
+func focused(session models.Session) string {
+	return models.FormatDuration(time.Duration(session.DurationSeconds) * time.Second)
+}
+
+// queueHooks runs the hooks for event in the background, once the hooks
+// queued before them have finished, and logs each run against session.
+func (s *Scheduler) queueHooks(event string, session models.Session) {
+	h := s.hooks
+	prev, done := s.hooksDone, make(chan struct{})
+	s.hooksDone = done
+
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		for _, result := range h.Run(event, session, time.Now()) {
+			if result.Err != "" {
+				log.Printf("Hook %s failed on %s: %s", result.Hook, event, result.Err)
+			}
+			e := models.NewEvent(models.EventHook, time.Now(), result.Payload(event))
+			e.SessionID = session.ID
+			_, err := storage.AppendSessionEvent(s.db, e)
+			if err != nil {
+				log.Println("Error recording hook:", err)
 			}
 		}
+	}()
+}
 
+// checkTamper compares the block in place with the one last applied. Domains
+// missing from it were removed by hand, which counts against the session;
+// the re-apply that follows puts them back.
+func (s *Scheduler) checkTamper(session models.Session) {
+	inspector, ok := s.enforcer.(Inspector)
+	if !ok {
+		return
+	}
+	blocked, err := inspector.Blocked()
+	if err != nil {
+		log.Println("Error checking the block:", err)
+		return
+	}
+
+	present := make(map[string]bool, len(blocked))
+	for _, domain := range blocked {
+		present[domain] = true
+	}
+	var missing []string
+	for _, domain := range s.applied {
+		if !present[domain] {
+			missing = append(missing, domain)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	log.Printf("Block was tampered with; restoring %d domains", len(missing))
+	event := models.NewEvent(models.EventTamper, time.Now(), map[string]any{
+		"missing": missing,
+	})
+	event.SessionID = session.ID
+	err = storage.RecordTamper(s.db, event)
+	if err != nil {
+		log.Println("Error recording tampering:", err)
 	}
 }
 
+// InitializeScheduler runs a scheduler that edits the hosts file directly.
+// It blocks forever; lockind is the supported way to run it.
+func InitializeScheduler(db *sql.DB) {
+	NewScheduler(db, blocker.HostsEnforcer{}).Run(context.Background())
+}
@@ -2,12 +2,17 @@ package core
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/hooks"
 	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/notify"
 	"github.com/youssef28m/LockIn/internal/storage"
 )
 
@@ -20,32 +25,16 @@ func setupSchedulerTestDB(t *testing.T) *sql.DB {
 	os.Remove(testDbPath)
 
 	// Create new test database
-	db, err := sql.Open("sqlite3", testDbPath)
+	db, err := storage.Open(testDbPath)
 	if err != nil {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	// Create tables
-	sessionsSQL := `CREATE TABLE IF NOT EXISTS sessions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		start_time INTEGER NOT NULL,
-		duration_seconds INTEGER NOT NULL,
-		active INTEGER NOT NULL
-	);`
-
-	blockedSitesSQL := `CREATE TABLE IF NOT EXISTS blocked_sites (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		domain TEXT NOT NULL
-	);`
-
-	blockedAppsSQL := `CREATE TABLE IF NOT EXISTS blocked_apps (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		process_name TEXT NOT NULL
-	);`
-
-	db.Exec(sessionsSQL)
-	db.Exec(blockedSitesSQL)
-	db.Exec(blockedAppsSQL)
+	// Create tables through the same migrations the app uses
+	err = storage.InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
 
 	return db
 }
@@ -400,3 +389,311 @@ func TestSchedulerInitialization(t *testing.T) {
 		t.Error("Should have at least 1 blocked site")
 	}
 }
+
+// recordingEnforcer remembers the last block set and how often it was cleared
+type recordingEnforcer struct {
+	domains []string
+	clears  int
+}
+
+func (r *recordingEnforcer) Apply(domains []string) error {
+	r.domains = domains
+	return nil
+}
+
+func (r *recordingEnforcer) Clear() error {
+	r.domains = nil
+	r.clears++
+	return nil
+}
+
+// TestSchedulerSetEnforcer tests that swapping the enforcer mid-session lifts
+// the old block and applies the new one on the next sync
+func TestSchedulerSetEnforcer(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	defer cleanupSchedulerTestDB(t, db)
+
+	storage.CreateSession(db, time.Now().Unix(), 3600, true)
+	storage.CreateBlockedSite(db, "distraction.com")
+
+	old, next := &recordingEnforcer{}, &recordingEnforcer{}
+	scheduler := NewScheduler(db, old)
+	scheduler.Sync()
+	if len(old.domains) != 1 {
+		t.Fatalf("old enforcer applied %v, want [distraction.com]", old.domains)
+	}
+
+	scheduler.SetEnforcer(next)
+	if old.clears != 1 || scheduler.Blocking() {
+		t.Errorf("old enforcer cleared %d times, blocking = %v; want 1, false", old.clears, scheduler.Blocking())
+	}
+
+	scheduler.Sync()
+	if len(next.domains) != 1 || !scheduler.Blocking() {
+		t.Errorf("new enforcer applied %v, blocking = %v", next.domains, scheduler.Blocking())
+	}
+}
+
+// TestSchedulerRecordsEvents tests that an expired session gets a completed
+// event and that undoing the hosts block mid-session counts as tampering
+func TestSchedulerRecordsEvents(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	defer cleanupSchedulerTestDB(t, db)
+
+	expired, _ := storage.CreateSession(db, time.Now().Unix()-120, 60, true)
+	running, _ := storage.CreateSession(db, time.Now().Unix(), 3600, true)
+	storage.CreateBlockedSite(db, "distraction.com")
+	storage.CreateBlockedSite(db, "news.example.com")
+
+	path := filepath.Join(t.TempDir(), "hosts")
+	os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0644)
+	scheduler := NewScheduler(db, blocker.HostsEnforcer{Path: path})
+	scheduler.Sync()
+
+	events, _ := storage.GetSessionEvents(db, storage.EventFilter{SessionID: expired})
+	if len(events) != 1 || events[0].Type != models.EventCompleted {
+		t.Errorf("expired session events = %+v, want one completed", events)
+	}
+
+	// A sync with the block intact records nothing.
+	scheduler.Sync()
+	events, _ = storage.GetSessionEvents(db, storage.EventFilter{SessionID: running})
+	if len(events) != 0 {
+		t.Fatalf("running session events = %+v, want none", events)
+	}
+
+	os.WriteFile(path, []byte("127.0.0.1 localhost\n# BEGIN LockIn\n127.0.0.1    news.example.com\n# END LockIn\n"), 0644)
+	scheduler.Sync()
+
+	events, _ = storage.GetSessionEvents(db, storage.EventFilter{SessionID: running})
+	if len(events) != 1 || events[0].Type != models.EventTamper {
+		t.Fatalf("running session events = %+v, want one tamper", events)
+	}
+	missing, _ := events[0].Payload["missing"].([]any)
+	if len(missing) != 1 || missing[0] != "distraction.com" {
+		t.Errorf("tamper payload = %v, want distraction.com missing", events[0].Payload)
+	}
+	session, _ := storage.GetSessionByID(db, running)
+	if session.TamperCount != 1 {
+		t.Errorf("TamperCount = %d, want 1", session.TamperCount)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "distraction.com") {
+		t.Errorf("block wasn't restored:\n%s", content)
+	}
+}
+
+// recordingNotifier remembers the summary of every notification shown
+type recordingNotifier struct {
+	summaries []string
+}
+
+func (r *recordingNotifier) Notify(n notify.Notification) error {
+	r.summaries = append(r.summaries, n.Summary)
+	return nil
+}
+
+// take returns the summaries shown since the last call
+func (r *recordingNotifier) take() []string {
+	got := r.summaries
+	r.summaries = nil
+	return got
+}
+
+// TestSchedulerNotifies tests that a session is announced when it starts,
+// warned about once five minutes before it ends, and announced again when
+// it completes
+func TestSchedulerNotifies(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	defer cleanupSchedulerTestDB(t, db)
+
+	// A session already running when lockind starts was announced before.
+	storage.CreateSession(db, time.Now().Unix(), 3600, true)
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(db, &recordingEnforcer{})
+	scheduler.SetNotifier(notifier)
+	scheduler.Sync()
+	if got := notifier.take(); len(got) != 0 {
+		t.Errorf("first sync notified %v, want nothing", got)
+	}
+	storage.DeleteSession(db, 1)
+	scheduler.Sync()
+
+	// Each step moves the session so it has remaining seconds left.
+	id, _ := storage.CreateSession(db, time.Now().Unix(), 3600, true)
+	session, _ := storage.GetSessionByID(db, id)
+	steps := []struct {
+		name      string
+		remaining int64
+		want      string
+	}{
+		{"started", 3600, "Focus session started"},
+		{"still running", 3000, ""},
+		{"five minutes left", 240, "5 minutes left"},
+		{"warned already", 200, ""},
+		{"extended", 900, ""},
+		{"five minutes left again", 240, "5 minutes left"},
+		{"completed", -60, "Focus session complete"},
+	}
+	for _, step := range steps {
+		session.StartTime = time.Now().Unix() - (session.DurationSeconds - step.remaining)
+		storage.UpdateSession(db, *session)
+		scheduler.Sync()
+
+		got := notifier.take()
+		if step.want == "" && len(got) != 0 || step.want != "" && (len(got) != 1 || got[0] != step.want) {
+			t.Errorf("%s: notified %v, want %q", step.name, got, step.want)
+		}
+	}
+
+	scheduler.Sync()
+	if got := notifier.take(); len(got) != 0 {
+		t.Errorf("sync after completion notified %v, want nothing", got)
+	}
+}
+
+// TestSchedulerNotifiesBreaks tests that a session with a break announces
+// the break as it completes and again once the break is over, and that a
+// session started during a break cuts it short without a word
+func TestSchedulerNotifiesBreaks(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	defer cleanupSchedulerTestDB(t, db)
+
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(db, &recordingEnforcer{})
+	scheduler.SetNotifier(notifier)
+	scheduler.Sync()
+
+	// Each step moves the session so it has remaining seconds of focus
+	// left; its break is five minutes.
+	id, _ := storage.InsertSession(db, models.Session{StartTime: time.Now().Unix(), DurationSeconds: 3600, Active: true, BreakSeconds: 300})
+	steps := []struct {
+		name      string
+		remaining int64
+		want      string
+	}{
+		{"started", 3600, "Focus session started"},
+		{"completed", -60, "Focus session complete, break started"},
+		{"on break", -120, ""},
+		{"break over", -360, "Break over"},
+		{"after the break", -600, ""},
+	}
+	for _, step := range steps {
+		session, _ := storage.GetSessionByID(db, id)
+		session.StartTime = time.Now().Unix() - (session.DurationSeconds - step.remaining)
+		storage.UpdateSession(db, *session)
+		scheduler.Sync()
+
+		got := notifier.take()
+		if step.want == "" && len(got) != 0 || step.want != "" && (len(got) != 1 || got[0] != step.want) {
+			t.Errorf("%s: notified %v, want %q", step.name, got, step.want)
+		}
+	}
+
+	resting, _ := storage.InsertSession(db, models.Session{StartTime: time.Now().Unix() - 3660, DurationSeconds: 3600, Active: true, BreakSeconds: 300})
+	scheduler.Sync()
+	if got := notifier.take(); len(got) != 1 || got[0] != "Focus session complete, break started" {
+		t.Errorf("completed session notified %v, want its break started", got)
+	}
+	storage.CreateSession(db, time.Now().Unix(), 1500, true)
+	scheduler.Sync()
+	if got := notifier.take(); len(got) != 1 || got[0] != "Focus session started" {
+		t.Errorf("session started during the break of %d notified %v, want only its start", resting, got)
+	}
+}
+
+// waitHooks waits for the hooks queued so far to finish
+func waitHooks(s *Scheduler) {
+	s.mu.Lock()
+	done := s.hooksDone
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// TestSchedulerRunsHooks tests that hooks run when a session starts and
+// when it is stopped, and that each run is logged against the session
+func TestSchedulerRunsHooks(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	defer cleanupSchedulerTestDB(t, db)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "dnd"), []byte("#!/bin/sh\necho dnd $LOCKIN_EVENT $LOCKIN_SESSION_ID\n"), 0755)
+	scheduler := NewScheduler(db, &recordingEnforcer{})
+	scheduler.SetHooks(hooks.Hooks{Dir: dir, End: []string{"echo bye; exit 1"}})
+	scheduler.Sync()
+
+	id, _ := storage.CreateSession(db, time.Now().Unix(), 3600, true)
+	scheduler.Sync()
+	scheduler.Sync()
+	session, _ := storage.GetSessionByID(db, id)
+	session.Abort(time.Now())
+	storage.UpdateSession(db, *session)
+	scheduler.Sync()
+	waitHooks(scheduler)
+
+	events, _ := storage.GetSessionEvents(db, storage.EventFilter{SessionID: id})
+	var got []string
+	for _, e := range events {
+		if e.Type == models.EventHook {
+			got = append(got, fmt.Sprintf("%v %v %v %q", e.Payload["event"], filepath.Base(e.Payload["hook"].(string)), e.Payload["exit_code"], e.Payload["output"]))
+		}
+	}
+	want := []string{
+		fmt.Sprintf("start dnd 0 \"dnd start %d\\n\"", id),
+		fmt.Sprintf("end dnd 0 \"dnd end %d\\n\"", id),
+		"end echo bye; exit 1 1 \"bye\\n\"",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hook events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// TestSchedulerRunsBreakHooks tests that the break after a session runs its
+// hooks after the session's end hooks, and that a break cut short by a new
+// session ends before that session's start hooks run
+func TestSchedulerRunsBreakHooks(t *testing.T) {
+	db := setupSchedulerTestDB(t)
+	defer cleanupSchedulerTestDB(t, db)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "phase"), []byte("#!/bin/sh\necho $LOCKIN_EVENT $LOCKIN_PHASE $LOCKIN_BREAK_SECONDS\n"), 0755)
+	scheduler := NewScheduler(db, &recordingEnforcer{})
+	scheduler.SetHooks(hooks.Hooks{Dir: dir})
+	// Hooks are waited for after each sync so their writes don't race the
+	// test's own.
+	sync := func() {
+		scheduler.Sync()
+		waitHooks(scheduler)
+	}
+	sync()
+
+	first, _ := storage.InsertSession(db, models.Session{StartTime: time.Now().Unix(), DurationSeconds: 3600, Active: true, BreakSeconds: 300})
+	sync()
+	session, _ := storage.GetSessionByID(db, first)
+	session.StartTime -= 3660
+	storage.UpdateSession(db, *session)
+	sync()
+	sync()
+	second, _ := storage.CreateSession(db, time.Now().Unix(), 1500, true)
+	sync()
+
+	events, _ := storage.GetSessionEvents(db, storage.EventFilter{})
+	var got []string
+	for _, e := range events {
+		if e.Type == models.EventHook {
+			got = append(got, fmt.Sprintf("%d %v", e.SessionID, e.Payload["output"]))
+		}
+	}
+	want := []string{
+		fmt.Sprintf("%d start focus 300\n", first),
+		fmt.Sprintf("%d end break 300\n", first),
+		fmt.Sprintf("%d break_start break 300\n", first),
+		fmt.Sprintf("%d break_end ended 300\n", first),
+		fmt.Sprintf("%d start focus 0\n", second),
+	}
+	if strings.Join(got, "") != strings.Join(want, "") {
+		t.Errorf("hook events:\n%s\nwant:\n%s", strings.Join(got, ""), strings.Join(want, ""))
+	}
+}
@@ -0,0 +1,246 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/backup"
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// Client talks to a running lockind. It implements service.Service so the
+// TUI and CLI can use it in place of service.Local.
+type Client struct {
+	Path    string
+	Timeout time.Duration
+}
+
+var _ service.Service = (*Client)(nil)
+
+func NewClient(path string) *Client {
+	return &Client{Path: path, Timeout: requestTimeout}
+}
+
+// Connect returns a client for the running daemon so changes are enforced
+// immediately, or a service.Local over the user's database when no daemon
+// is listening.
+func Connect() (service.Service, error) {
+	client := NewClient(SocketPath())
+	if client.Available() {
+		return client, nil
+	}
+
+	db := storage.Connect()
+	err := storage.InitSchema(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return service.NewLocal(db), nil
+}
+
+// Available reports whether a daemon is accepting connections.
+func (c *Client) Available() bool {
+	conn, err := net.DialTimeout("unix", c.Path, c.Timeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Call sends one request and decodes its result into out, which may be nil.
+func (c *Client) Call(method string, params any, out any) error {
+	req := Request{Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = raw
+	}
+
+	conn, err := net.DialTimeout("unix", c.Path, c.Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return err
+	}
+
+	var resp Response
+	err = json.NewDecoder(conn).Decode(&resp)
+	if err != nil {
+		return err
+	}
+
+	if !resp.OK {
+		if target, ok := errorCodes[resp.Code]; ok {
+			return target
+		}
+		return errors.New(resp.Error)
+	}
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, out)
+}
+
+func (c *Client) StartSession(opts service.StartOptions) (*models.Session, error) {
+	var session models.Session
+	params := StartParams{
+		DurationSeconds: int64(opts.Duration / time.Second),
+		Profile:         opts.Profile,
+		Strict:          opts.Strict,
+		BreakSeconds:    int64(opts.Break / time.Second),
+	}
+	err := c.Call(MethodStart, params, &session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (c *Client) ActiveSession() (*models.Session, error) {
+	var session *models.Session
+	err := c.Call(MethodStatus, nil, &session)
+	return session, err
+}
+
+func (c *Client) StopSession() error {
+	return c.Call(MethodStop, nil, nil)
+}
+
+func (c *Client) ExtendSession(by time.Duration) (*models.Session, error) {
+	var session models.Session
+	err := c.Call(MethodExtend, ExtendParams{BySeconds: int64(by / time.Second)}, &session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (c *Client) AddBlockedSite(domain string) error {
+	return c.Call(MethodAddSite, SiteParams{Domain: domain}, nil)
+}
+
+func (c *Client) UpdateBlockedSite(site models.BlockedSite) error {
+	return c.Call(MethodUpdateSite, SiteParams{ID: site.ID, Domain: site.Domain}, nil)
+}
+
+func (c *Client) RemoveBlockedSite(domain string) error {
+	return c.Call(MethodRemoveSite, SiteParams{Domain: domain}, nil)
+}
+
+func (c *Client) ListBlockedSites() ([]models.BlockedSite, error) {
+	var sites []models.BlockedSite
+	err := c.Call(MethodListSites, nil, &sites)
+	return sites, err
+}
+
+func (c *Client) History(limit int) ([]models.Session, error) {
+	var sessions []models.Session
+	err := c.Call(MethodHistory, HistoryParams{Limit: limit}, &sessions)
+	return sessions, err
+}
+
+func (c *Client) QueryHistory(q service.HistoryQuery) (*service.HistoryPage, error) {
+	var page service.HistoryPage
+	err := c.Call(MethodQueryHist, q, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *Client) DeleteSession(id int64) error {
+	return c.Call(MethodDeleteHist, SessionParams{ID: id}, nil)
+}
+
+func (c *Client) AnnotateSession(id int64, note string) error {
+	return c.Call(MethodNoteHist, SessionParams{ID: id, Note: note}, nil)
+}
+
+func (c *Client) Profiles() ([]models.Profile, error) {
+	var profiles []models.Profile
+	err := c.Call(MethodProfiles, nil, &profiles)
+	return profiles, err
+}
+
+func (c *Client) Stats(now time.Time) (*service.Stats, error) {
+	var stats service.Stats
+	err := c.Call(MethodStats, StatsParams{Now: now}, &stats)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (c *Client) Events(q service.EventQuery) ([]models.SessionEvent, error) {
+	var events []models.SessionEvent
+	err := c.Call(MethodEvents, q, &events)
+	return events, err
+}
+
+func (c *Client) VerifyAudit() (*storage.AuditReport, error) {
+	var report storage.AuditReport
+	err := c.Call(MethodAudit, nil, &report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *Client) AddBlockedApp(name string) error {
+	return c.Call(MethodAddApp, AppParams{Name: name}, nil)
+}
+
+func (c *Client) UpdateBlockedApp(app models.BlockedApp) error {
+	return c.Call(MethodUpdateApp, AppParams{ID: app.ID, Name: app.ProcessName}, nil)
+}
+
+func (c *Client) RemoveBlockedApp(name string) error {
+	return c.Call(MethodRemoveApp, AppParams{Name: name}, nil)
+}
+
+func (c *Client) ListBlockedApps() ([]models.BlockedApp, error) {
+	var apps []models.BlockedApp
+	err := c.Call(MethodListApps, nil, &apps)
+	return apps, err
+}
+
+func (c *Client) ImportBlockList(list blocklist.List) (*service.ImportResult, error) {
+	var result service.ImportResult
+	err := c.Call(MethodImport, list, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) Backup() (*backup.Archive, error) {
+	var archive backup.Archive
+	err := c.Call(MethodBackup, nil, &archive)
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+func (c *Client) Restore(archive *backup.Archive, mode string) (*backup.Result, error) {
+	var result backup.Result
+	err := c.Call(MethodRestore, RestoreParams{Archive: archive, Mode: mode}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,118 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/backup"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+// Methods understood by the control socket. Each connection carries one
+// newline-terminated JSON Request and gets one JSON Response back.
+const (
+	MethodStart      = "start"
+	MethodStatus     = "status"
+	MethodStop       = "stop"
+	MethodExtend     = "extend"
+	MethodAddSite    = "sites.add"
+	MethodUpdateSite = "sites.update"
+	MethodRemoveSite = "sites.remove"
+	MethodListSites  = "sites.list"
+	MethodAddApp     = "apps.add"
+	MethodUpdateApp  = "apps.update"
+	MethodRemoveApp  = "apps.remove"
+	MethodListApps   = "apps.list"
+	MethodImport     = "blocklist.import"
+	MethodBackup     = "backup.create"
+	MethodRestore    = "backup.restore"
+	MethodHistory    = "history"
+	MethodQueryHist  = "history.query"
+	MethodDeleteHist = "history.delete"
+	MethodNoteHist   = "history.note"
+	MethodProfiles   = "profiles.list"
+	MethodStats      = "stats"
+	MethodEvents     = "events"
+	MethodAudit      = "audit.verify"
+)
+
+type Request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	OK     bool            `json:"ok"`
+	Error  string          `json:"error,omitempty"`
+	Code   string          `json:"code,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+type StartParams struct {
+	DurationSeconds int64  `json:"duration_seconds"`
+	Profile         string `json:"profile,omitempty"`
+	Strict          bool   `json:"strict,omitempty"`
+	BreakSeconds    int64  `json:"break_seconds,omitempty"`
+}
+
+func (p StartParams) options() service.StartOptions {
+	return service.StartOptions{
+		Duration: time.Duration(p.DurationSeconds) * time.Second,
+		Profile:  p.Profile,
+		Strict:   p.Strict,
+		Break:    time.Duration(p.BreakSeconds) * time.Second,
+	}
+}
+
+type ExtendParams struct {
+	BySeconds int64 `json:"by_seconds"`
+}
+
+type SiteParams struct {
+	ID     int64  `json:"id,omitempty"`
+	Domain string `json:"domain"`
+}
+
+type AppParams struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+type HistoryParams struct {
+	Limit int `json:"limit"`
+}
+
+// StatsParams carries the caller's clock so days line up with its time zone.
+type StatsParams struct {
+	Now time.Time `json:"now"`
+}
+
+type SessionParams struct {
+	ID   int64  `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+type RestoreParams struct {
+	Archive *backup.Archive `json:"archive"`
+	Mode    string          `json:"mode"`
+}
+
+// Error codes let the client hand back the same sentinel errors the service
+// package returns locally.
+var errorCodes = map[string]error{
+	"session_active":    service.ErrSessionActive,
+	"no_active_session": service.ErrNoActiveSession,
+	"strict_session":    service.ErrStrictSession,
+	"strict_shorten":    service.ErrStrictShorten,
+	"restore_session":   service.ErrRestoreSession,
+}
+
+func codeFor(err error) string {
+	for code, target := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return ""
+}
@@ -0,0 +1,275 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/core"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+const requestTimeout = 10 * time.Second
+
+// Server answers control requests on the lockind socket. It owns the
+// scheduler so changes take effect immediately instead of on the next tick.
+type Server struct {
+	svc       *service.Local
+	scheduler *core.Scheduler
+}
+
+func NewServer(db *sql.DB, scheduler *core.Scheduler) *Server {
+	return &Server{svc: service.NewLocal(db), scheduler: scheduler}
+}
+
+// ListenAndServe listens on path and serves until ctx is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context, path string) error {
+	ln, err := listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	return s.Serve(ctx, ln)
+}
+
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	err := checkPeer(conn)
+	if err != nil {
+		log.Println("Rejected control connection:", err)
+		return
+	}
+
+	var req Request
+	err = json.NewDecoder(bufio.NewReader(conn)).Decode(&req)
+	if err != nil {
+		writeResponse(conn, Response{Error: "malformed request: " + err.Error()})
+		return
+	}
+
+	writeResponse(conn, s.handle(req))
+}
+
+func (s *Server) handle(req Request) Response {
+	result, err := s.dispatch(req)
+	if err != nil {
+		return Response{Error: err.Error(), Code: codeFor(err)}
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	return Response{OK: true, Result: raw}
+}
+
+func (s *Server) dispatch(req Request) (any, error) {
+	switch req.Method {
+	case MethodStatus:
+		return s.svc.ActiveSession()
+
+	case MethodStart:
+		var params StartParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		session, err := s.svc.StartSession(params.options())
+		if err != nil {
+			return nil, err
+		}
+		s.scheduler.Sync()
+		return session, nil
+
+	case MethodStop:
+		err := s.svc.StopSession()
+		if err != nil {
+			return nil, err
+		}
+		s.scheduler.Sync()
+		return nil, nil
+
+	case MethodExtend:
+		var params ExtendParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		session, err := s.svc.ExtendSession(time.Duration(params.BySeconds) * time.Second)
+		if err != nil {
+			return nil, err
+		}
+		s.scheduler.Sync()
+		return session, nil
+
+	case MethodAddSite, MethodUpdateSite, MethodRemoveSite:
+		var params SiteParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		switch req.Method {
+		case MethodAddSite:
+			err = s.svc.AddBlockedSite(params.Domain)
+		case MethodUpdateSite:
+			err = s.svc.UpdateBlockedSite(models.BlockedSite{ID: params.ID, Domain: params.Domain})
+		default:
+			err = s.svc.RemoveBlockedSite(params.Domain)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.scheduler.Sync()
+		return nil, nil
+
+	case MethodListSites:
+		return s.svc.ListBlockedSites()
+
+	case MethodAddApp, MethodUpdateApp, MethodRemoveApp:
+		var params AppParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		switch req.Method {
+		case MethodAddApp:
+			err = s.svc.AddBlockedApp(params.Name)
+		case MethodUpdateApp:
+			err = s.svc.UpdateBlockedApp(models.BlockedApp{ID: params.ID, ProcessName: params.Name})
+		default:
+			err = s.svc.RemoveBlockedApp(params.Name)
+		}
+		return nil, err
+
+	case MethodListApps:
+		return s.svc.ListBlockedApps()
+
+	case MethodImport:
+		var list blocklist.List
+		err := decodeParams(req, &list)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.ImportBlockList(list)
+
+	case MethodBackup:
+		return s.svc.Backup()
+
+	case MethodRestore:
+		var params RestoreParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		if params.Archive == nil {
+			return nil, errors.New("missing archive")
+		}
+		result, err := s.svc.Restore(params.Archive, params.Mode)
+		if err != nil {
+			return nil, err
+		}
+		s.scheduler.Sync()
+		return result, nil
+
+	case MethodHistory:
+		var params HistoryParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.History(params.Limit)
+
+	case MethodQueryHist:
+		var params service.HistoryQuery
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.QueryHistory(params)
+
+	case MethodDeleteHist, MethodNoteHist:
+		var params SessionParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		if req.Method == MethodDeleteHist {
+			err = s.svc.DeleteSession(params.ID)
+		} else {
+			err = s.svc.AnnotateSession(params.ID, params.Note)
+		}
+		return nil, err
+
+	case MethodProfiles:
+		return s.svc.Profiles()
+
+	case MethodStats:
+		var params StatsParams
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.Stats(params.Now)
+
+	case MethodEvents:
+		var params service.EventQuery
+		err := decodeParams(req, &params)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.Events(params)
+
+	case MethodAudit:
+		return s.svc.VerifyAudit()
+	}
+
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
+
+func decodeParams(req Request, v any) error {
+	if len(req.Params) == 0 {
+		return errors.New("missing params")
+	}
+	err := json.Unmarshal(req.Params, v)
+	if err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
+
+func writeResponse(conn net.Conn, resp Response) {
+	err := json.NewEncoder(conn).Encode(resp)
+	if err != nil {
+		log.Println("Error writing control response:", err)
+	}
+}
@@ -0,0 +1,291 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/backup"
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/core"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// fakeEnforcer records the last block set instead of touching the hosts file
+type fakeEnforcer struct {
+	domains []string
+	applied bool
+}
+
+func (f *fakeEnforcer) Apply(domains []string) error {
+	f.domains = domains
+	f.applied = true
+	return nil
+}
+
+func (f *fakeEnforcer) Clear() error {
+	f.domains = nil
+	f.applied = false
+	return nil
+}
+
+// startTestDaemon serves a fresh database on a socket in a temp dir
+func startTestDaemon(t *testing.T) (*Client, *fakeEnforcer) {
+	dir := t.TempDir()
+
+	db, err := storage.Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	err = storage.InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	enforcer := &fakeEnforcer{}
+	server := NewServer(db, core.NewScheduler(db, enforcer))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	socket := filepath.Join(dir, "run", "lockind.sock")
+	done := make(chan error, 1)
+	go func() { done <- server.ListenAndServe(ctx, socket) }()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+		db.Close()
+	})
+
+	client := NewClient(socket)
+	for i := 0; i < 50 && !client.Available(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !client.Available() {
+		t.Fatal("daemon never started listening")
+	}
+	return client, enforcer
+}
+
+func TestSocketPermissions(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	info, err := os.Stat(client.Path)
+	if err != nil {
+		t.Fatalf("Failed to stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %o, expected 600", perm)
+	}
+
+	dirInfo, _ := os.Stat(filepath.Dir(client.Path))
+	if perm := dirInfo.Mode().Perm(); perm != 0700 {
+		t.Errorf("socket directory permissions = %o, expected 700", perm)
+	}
+}
+
+func TestRefusesSecondDaemon(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	_, err := listen(client.Path)
+	if err == nil {
+		t.Fatal("expected listen to fail while the first daemon is serving")
+	}
+}
+
+func TestSessionLifecycleOverSocket(t *testing.T) {
+	client, enforcer := startTestDaemon(t)
+
+	if err := client.AddBlockedSite("youtube.com"); err != nil {
+		t.Fatalf("add site: %v", err)
+	}
+
+	session, err := client.ActiveSession()
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if session != nil {
+		t.Fatalf("expected no active session, got %+v", session)
+	}
+
+	session, err = client.StartSession(service.StartOptions{Duration: 25 * time.Minute})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if session.DurationSeconds != 1500 || !session.Active {
+		t.Errorf("unexpected session %+v", session)
+	}
+	if !enforcer.applied || len(enforcer.domains) != 1 || enforcer.domains[0] != "youtube.com" {
+		t.Errorf("expected youtube.com to be blocked right away, got %v", enforcer.domains)
+	}
+
+	_, err = client.StartSession(service.StartOptions{Duration: 25 * time.Minute})
+	if !errors.Is(err, service.ErrSessionActive) {
+		t.Errorf("second start: expected ErrSessionActive, got %v", err)
+	}
+
+	// Sites added mid-session are enforced immediately too.
+	if err := client.AddBlockedSite("reddit.com"); err != nil {
+		t.Fatalf("add site: %v", err)
+	}
+	if len(enforcer.domains) != 2 {
+		t.Errorf("expected 2 blocked domains, got %v", enforcer.domains)
+	}
+
+	if err := client.StopSession(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if enforcer.applied {
+		t.Error("expected block to be cleared after stop")
+	}
+
+	err = client.StopSession()
+	if !errors.Is(err, service.ErrNoActiveSession) {
+		t.Errorf("second stop: expected ErrNoActiveSession, got %v", err)
+	}
+
+	report, err := client.VerifyAudit()
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if !report.OK() || report.Events != 2 {
+		t.Errorf("expected a clean audit of 2 events, got %+v", report)
+	}
+}
+
+func TestExtendOverSocket(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	_, err := client.StartSession(service.StartOptions{Duration: 25 * time.Minute, Strict: true})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	session, err := client.ExtendSession(10 * time.Minute)
+	if err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	if session.DurationSeconds != 2100 {
+		t.Errorf("expected a 35m session, got %ds", session.DurationSeconds)
+	}
+
+	_, err = client.ExtendSession(-5 * time.Minute)
+	if !errors.Is(err, service.ErrStrictShorten) {
+		t.Errorf("shorten: expected ErrStrictShorten, got %v", err)
+	}
+
+	events, err := client.Events(service.EventQuery{SessionID: session.ID})
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	if len(events) != 2 || events[1].Type != models.EventExtended || events[1].Payload["by_seconds"] != 600.0 {
+		t.Errorf("expected a started and an extended event, got %+v", events)
+	}
+}
+
+func TestSiteManagementOverSocket(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr bool
+	}{
+		{"add valid", func() error { return client.AddBlockedSite("example.com") }, false},
+		{"add duplicate", func() error { return client.AddBlockedSite("example.com") }, true},
+		{"add invalid", func() error { return client.AddBlockedSite("http://bad") }, true},
+		{"remove missing", func() error { return client.RemoveBlockedSite("missing.com") }, true},
+		{"remove existing", func() error { return client.RemoveBlockedSite("example.com") }, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call()
+			if (err != nil) != test.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+
+	sites, err := client.ListBlockedSites()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(sites) != 0 {
+		t.Errorf("expected empty block list, got %v", sites)
+	}
+}
+
+func TestImportOverSocket(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	if err := client.AddBlockedSite("youtube.com"); err != nil {
+		t.Fatalf("add site: %v", err)
+	}
+
+	result, err := client.ImportBlockList(blocklist.List{Sites: []string{"youtube.com", "reddit.com"}, Apps: []string{"steam"}})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	want := service.ImportResult{AddedSites: 1, AddedApps: 1, ExistingSites: 1}
+	if *result != want {
+		t.Errorf("import result = %+v, want %+v", *result, want)
+	}
+
+	// The daemon checks entries again rather than trusting the client.
+	_, err = client.ImportBlockList(blocklist.List{Sites: []string{"fine.com", "http://bad"}})
+	if err == nil {
+		t.Error("expected an import with an invalid entry to fail")
+	}
+	sites, _ := client.ListBlockedSites()
+	if len(sites) != 2 {
+		t.Errorf("expected 2 blocked sites, got %v", sites)
+	}
+}
+
+func TestBackupOverSocket(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	if err := client.AddBlockedSite("youtube.com"); err != nil {
+		t.Fatalf("add site: %v", err)
+	}
+	archive, err := client.Backup()
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	// The archive survives the trip as JSON with its checksum intact.
+	if err := archive.Verify(); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if err := client.RemoveBlockedSite("youtube.com"); err != nil {
+		t.Fatalf("remove site: %v", err)
+	}
+	result, err := client.Restore(archive, backup.ModeMerge)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Sites != 1 {
+		t.Errorf("restore result = %+v, want 1 site", *result)
+	}
+
+	if _, err := client.StartSession(service.StartOptions{Duration: 25 * time.Minute}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	_, err = client.Restore(archive, backup.ModeReplace)
+	if !errors.Is(err, service.ErrRestoreSession) {
+		t.Errorf("replace during a session: err = %v, want ErrRestoreSession", err)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	client, _ := startTestDaemon(t)
+
+	err := client.Call("bogus", nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for an unknown method")
+	}
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/youssef28m/LockIn/internal/peercred"
+)
+
+// SocketPath returns where lockind listens. LOCKIN_SOCKET overrides it;
+// otherwise it lives under XDG_RUNTIME_DIR, falling back to ~/.lockin.
+func SocketPath() string {
+	if path := os.Getenv("LOCKIN_SOCKET"); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "lockin", "lockind.sock")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lockin", "lockind.sock")
+}
+
+// listen creates the socket with owner-only permissions. It refuses to
+// replace a socket another daemon is still serving.
+func listen(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	err = checkSocketDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("lockind is already listening on %s", path)
+	}
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	return ln, nil
+}
+
+// checkSocketDir makes sure nobody else can swap the socket out from under us.
+func checkSocketDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("socket directory %s is writable by other users", dir)
+	}
+	return checkOwner(info)
+}
+
+// checkPeer rejects connections from any user other than the daemon's own
+// (root is always let through). Where peer credentials aren't available the
+// 0600 socket permissions are all that guard the API.
+func checkPeer(conn net.Conn) error {
+	uid, err := peercred.UID(conn)
+	if errors.Is(err, peercred.ErrUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if uid != 0 && uid != os.Getuid() {
+		return fmt.Errorf("connection from uid %d refused", uid)
+	}
+	return nil
+}
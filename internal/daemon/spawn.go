@@ -0,0 +1,55 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// startTimeout is how long StartBackground waits for a new lockind to
+// start listening.
+const startTimeout = 3 * time.Second
+
+// FindBinary looks for name next to the running executable, then on PATH.
+func FindBinary(name string) string {
+	if self, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(self), name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	return filepath.Join("/usr/local/bin", name)
+}
+
+// StartBackground launches lockind detached from the terminal and waits
+// until it answers on the control socket, so a session keeps being enforced
+// after the UI that started it exits.
+func StartBackground() error {
+	client := NewClient(SocketPath())
+	if client.Available() {
+		return nil
+	}
+
+	path := FindBinary("lockind")
+	cmd := exec.Command(path, "-socket", client.Path)
+	cmd.SysProcAttr = detached()
+	err := cmd.Start()
+	if err != nil {
+		return fmt.Errorf("starting %s: %w", path, err)
+	}
+	cmd.Process.Release()
+
+	deadline := time.Now().Add(startTimeout)
+	for time.Now().Before(deadline) {
+		if client.Available() {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("%s started but isn't listening on %s", path, client.Path)
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package daemon
+
+import "syscall"
+
+// detached puts lockind in its own session so closing the terminal doesn't
+// take it down with the UI.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
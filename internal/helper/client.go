@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/blocker"
+)
+
+// Client forwards block sets to the helper. It satisfies core.Enforcer.
+type Client struct {
+	Path    string
+	Timeout time.Duration
+	// RedirectIP is sent with every block set; empty leaves it to the helper.
+	RedirectIP string
+}
+
+func NewClient(path string) *Client {
+	return &Client{Path: path, Timeout: requestTimeout}
+}
+
+func (c *Client) Apply(domains []string) error {
+	return c.send(Request{Op: OpApply, Domains: domains, RedirectIP: c.RedirectIP})
+}
+
+func (c *Client) Clear() error {
+	return c.send(Request{Op: OpClear})
+}
+
+// Blocked reads the block back from the system hosts file, which anyone
+// may read, so it doesn't go through the helper.
+func (c *Client) Blocked() ([]string, error) {
+	return blocker.HostsEnforcer{}.Blocked()
+}
+
+func (c *Client) send(req Request) error {
+	conn, err := net.DialTimeout("unix", c.Path, c.Timeout)
+	if err != nil {
+		return fmt.Errorf("helper unavailable: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	err = json.NewEncoder(conn).Encode(req)
+	if err != nil {
+		return err
+	}
+
+	var resp Response
+	err = json.NewDecoder(conn).Decode(&resp)
+	if err != nil {
+		return err
+	}
+
+	if !resp.OK {
+		if len(resp.Rejected) > 0 {
+			return fmt.Errorf("helper: %s: %s", resp.Error, strings.Join(resp.Rejected, ", "))
+		}
+		return fmt.Errorf("helper: %s", resp.Error)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeApplier stands in for the hosts file so tests don't need root
+type fakeApplier struct {
+	mu      sync.Mutex
+	domains []string
+	ip      string
+	applies int
+	clears  int
+}
+
+func (f *fakeApplier) Apply(domains []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.domains = domains
+	f.applies++
+	return nil
+}
+
+func (f *fakeApplier) ApplyRedirect(ip string, domains []string) error {
+	f.mu.Lock()
+	f.ip = ip
+	f.mu.Unlock()
+	return f.Apply(domains)
+}
+
+func (f *fakeApplier) Clear() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.domains = nil
+	f.clears++
+	return nil
+}
+
+// startFakeHelper serves a fake applier on a socket in a temp dir
+func startFakeHelper(t *testing.T) (*Client, *fakeApplier) {
+	socket := filepath.Join(t.TempDir(), "helper.sock")
+	applier := &fakeApplier{}
+	server := NewServer(applier, []int{os.Getuid()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ListenAndServe(ctx, socket) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return NewClient(socket), applier
+}
+
+func TestApplyNormalizesAndDedupes(t *testing.T) {
+	client, applier := startFakeHelper(t)
+
+	err := client.Apply([]string{"YouTube.com", " reddit.com ", "youtube.com"})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	expected := []string{"youtube.com", "reddit.com"}
+	if !reflect.DeepEqual(applier.domains, expected) {
+		t.Errorf("applied %v, expected %v", applier.domains, expected)
+	}
+}
+
+func TestApplyRejectsInvalidDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []string
+	}{
+		{"protocol", []string{"good.com", "http://evil.com"}},
+		{"path", []string{"evil.com/path"}},
+		{"newline injection", []string{"evil.com\n0.0.0.0 bank.com"}},
+		{"no TLD", []string{"localhost"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, applier := startFakeHelper(t)
+
+			err := client.Apply(test.domains)
+			if err == nil {
+				t.Fatal("expected Apply to fail")
+			}
+			if applier.applies != 0 {
+				t.Error("applier should not be called when any domain is invalid")
+			}
+		})
+	}
+}
+
+func TestApplyRedirect(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		wantErr bool
+	}{
+		{"unspecified", "0.0.0.0", false},
+		{"ipv6 loopback", "::1", false},
+		{"public address", "93.184.216.34", true},
+		{"not an address", "0.0.0.0 bank.com", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, applier := startFakeHelper(t)
+			client.RedirectIP = test.ip
+
+			err := client.Apply([]string{"example.com"})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Apply error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				if applier.applies != 0 {
+					t.Error("applier should not be called with an invalid redirect address")
+				}
+				return
+			}
+			if applier.ip != test.ip {
+				t.Errorf("applied with redirect %q, expected %q", applier.ip, test.ip)
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	client, applier := startFakeHelper(t)
+
+	client.Apply([]string{"example.com"})
+	err := client.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if applier.clears != 1 || applier.domains != nil {
+		t.Errorf("expected one clear and no domains, got %d clears and %v", applier.clears, applier.domains)
+	}
+}
+
+func TestUnknownOp(t *testing.T) {
+	client, _ := startFakeHelper(t)
+
+	conn, err := net.Dial("unix", client.Path)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	json.NewEncoder(conn).Encode(Request{Op: "rm -rf"})
+	var resp Response
+	json.NewDecoder(conn).Decode(&resp)
+
+	if resp.OK || !strings.Contains(resp.Error, "unknown op") {
+		t.Errorf("expected unknown op error, got %+v", resp)
+	}
+}
+
+func TestUnavailableHelper(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "missing.sock"))
+
+	err := client.Apply([]string{"example.com"})
+	if err == nil || !strings.Contains(err.Error(), "helper unavailable") {
+		t.Errorf("expected helper unavailable error, got %v", err)
+	}
+}
+
+func TestAllowedUIDs(t *testing.T) {
+	server := NewServer(&fakeApplier{}, []int{1000})
+
+	tests := []struct {
+		uid      int
+		expected bool
+	}{
+		{0, true},
+		{1000, true},
+		{1001, false},
+	}
+
+	for _, test := range tests {
+		if server.allowed[test.uid] != test.expected {
+			t.Errorf("allowed[%d] = %v, expected %v", test.uid, server.allowed[test.uid], test.expected)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Package helper is the privileged half of LockIn. The helper runs as root
+// and does nothing but rewrite LockIn's section of the hosts file on request
+// from the unprivileged daemon, so the TUI and scheduler never need root.
+package helper
+
+import (
+	"os"
+	"strings"
+
+	"github.com/youssef28m/LockIn/internal/validator"
+)
+
+// Operations accepted by the helper. Each connection carries one
+// newline-terminated JSON Request and gets one JSON Response back.
+const (
+	OpApply = "apply"
+	OpClear = "clear"
+)
+
+// Large public blocklists run to well over a hundred thousand entries.
+const (
+	maxRequestBytes = 16 << 20
+	maxDomains      = 500000
+)
+
+type Request struct {
+	Op      string   `json:"op"`
+	Domains []string `json:"domains,omitempty"`
+	// RedirectIP is where the domains should point. Empty means the
+	// helper's default.
+	RedirectIP string `json:"redirect_ip,omitempty"`
+}
+
+type Response struct {
+	OK       bool     `json:"ok"`
+	Error    string   `json:"error,omitempty"`
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+// SocketPath returns where the helper listens. LOCKIN_HELPER_SOCKET
+// overrides the default.
+func SocketPath() string {
+	if path := os.Getenv("LOCKIN_HELPER_SOCKET"); path != "" {
+		return path
+	}
+	return "/run/lockin/helper.sock"
+}
+
+// normalize lowercases and dedupes domains, returning the ones that fail
+// validator.IsValidDomain separately.
+func normalize(domains []string) (valid []string, rejected []string) {
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if !validator.IsValidDomain(domain) {
+			rejected = append(rejected, domain)
+			continue
+		}
+		if seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		valid = append(valid, domain)
+	}
+	return valid, rejected
+}
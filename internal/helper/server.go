@@ -0,0 +1,168 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/config"
+	"github.com/youssef28m/LockIn/internal/peercred"
+)
+
+const requestTimeout = 30 * time.Second
+
+// Applier does the privileged work. blocker.HostsEnforcer is the real one.
+type Applier interface {
+	Apply(domains []string) error
+	Clear() error
+}
+
+// redirectApplier is an Applier that can point domains at an address other
+// than its default. blocker.HostsEnforcer is one.
+type redirectApplier interface {
+	ApplyRedirect(ip string, domains []string) error
+}
+
+type Server struct {
+	applier Applier
+	allowed map[int]bool
+}
+
+// NewServer returns a helper that accepts requests from root and from the
+// given user ids.
+func NewServer(applier Applier, allowedUIDs []int) *Server {
+	allowed := map[int]bool{0: true}
+	for _, uid := range allowedUIDs {
+		allowed[uid] = true
+	}
+	return &Server{applier: applier, allowed: allowed}
+}
+
+// ListenAndServe listens on path and serves until ctx is cancelled. The
+// socket is world-connectable; who may use it is decided per connection
+// from the peer's credentials.
+func (s *Server) ListenAndServe(ctx context.Context, path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	err = os.Chmod(path, 0666)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+
+	return s.Serve(ctx, ln)
+}
+
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	uid, err := peercred.UID(conn)
+	if err != nil {
+		log.Println("Rejected helper connection:", err)
+		return
+	}
+	if !s.allowed[uid] {
+		log.Printf("Rejected helper connection from uid %d", uid)
+		writeResponse(conn, Response{Error: fmt.Sprintf("uid %d is not allowed", uid)})
+		return
+	}
+
+	var req Request
+	err = json.NewDecoder(io.LimitReader(conn, maxRequestBytes)).Decode(&req)
+	if err != nil {
+		writeResponse(conn, Response{Error: "malformed request: " + err.Error()})
+		return
+	}
+
+	writeResponse(conn, s.handle(req))
+}
+
+func (s *Server) handle(req Request) Response {
+	switch req.Op {
+	case OpClear:
+		err := s.applier.Clear()
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true}
+
+	case OpApply:
+		if len(req.Domains) > maxDomains {
+			return Response{Error: fmt.Sprintf("too many domains (%d, limit %d)", len(req.Domains), maxDomains)}
+		}
+
+		// One bad entry rejects the whole set rather than applying part of it.
+		domains, rejected := normalize(req.Domains)
+		if len(rejected) > 0 {
+			return Response{Error: "invalid domains in block set", Rejected: rejected}
+		}
+
+		if req.RedirectIP == "" {
+			err := s.applier.Apply(domains)
+			if err != nil {
+				return Response{Error: err.Error()}
+			}
+			return Response{OK: true}
+		}
+
+		// The hosts file is shared by every user, so a block may only send
+		// domains nowhere, never to another host.
+		err := config.ValidateRedirectIP(req.RedirectIP)
+		if err != nil {
+			return Response{Error: "invalid redirect address: " + err.Error()}
+		}
+		redirector, ok := s.applier.(redirectApplier)
+		if !ok {
+			return Response{Error: "this helper can't change the redirect address"}
+		}
+		err = redirector.ApplyRedirect(req.RedirectIP, domains)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true}
+	}
+
+	return Response{Error: fmt.Sprintf("unknown op %q", req.Op)}
+}
+
+func writeResponse(conn net.Conn, resp Response) {
+	err := json.NewEncoder(conn).Encode(resp)
+	if err != nil {
+		log.Println("Error writing helper response:", err)
+	}
+}
@@ -0,0 +1,260 @@
+// Package hooks runs the user's own commands as sessions start and end, to
+// mute chat, set a status or start a playlist, and as the break after a
+// session starts and ends. Hooks are executables in the hooks directory,
+// which run on every event, and shell commands from the config, which run
+// on the event they are listed under.
+//
+// Each hook gets the session as LOCKIN_* environment variables and as JSON
+// on stdin. LOCKIN_EVENT says which event it is, and LOCKIN_PHASE whether
+// the session is in focus, on its break or ended as the hook runs.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+// Events hooks run on.
+const (
+	EventStart      = "start"
+	EventEnd        = "end"
+	EventBreakStart = "break_start"
+	EventBreakEnd   = "break_end"
+)
+
+// DefaultTimeout is how long a hook gets when Hooks.Timeout isn't set.
+const DefaultTimeout = 10 * time.Second
+
+// MaxOutput is how much of a hook's output is kept. The rest is dropped so
+// a chatty hook can't bloat the event log.
+const MaxOutput = 4096
+
+// waitDelay is how long a killed hook's children get to let go of its
+// output before it is closed on them.
+const waitDelay = time.Second
+
+// Hooks is what to run on each event.
+type Hooks struct {
+	// Dir holds executables run on every event. Files that aren't
+	// executable, and names starting with a dot, are skipped.
+	Dir string
+	// Start and End are shell commands run when a session starts and
+	// ends, and BreakStart and BreakEnd when the break after it starts and
+	// ends.
+	Start      []string
+	End        []string
+	BreakStart []string
+	BreakEnd   []string
+	// Timeout is how long each hook gets before it is killed.
+	Timeout time.Duration
+}
+
+// Result is how one hook went.
+type Result struct {
+	// Hook is the executable's path or the command line.
+	Hook     string
+	ExitCode int
+	// Output is what the hook wrote to stdout and stderr, cut to
+	// MaxOutput bytes.
+	Output string
+	// Err says why the hook didn't run to completion: it timed out or
+	// couldn't be started. A non-zero exit alone isn't an error.
+	Err      string
+	Duration time.Duration
+}
+
+// Payload is Result as recorded in the event log.
+func (r Result) Payload(event string) map[string]any {
+	payload := map[string]any{
+		"hook":        r.Hook,
+		"event":       event,
+		"exit_code":   r.ExitCode,
+		"output":      r.Output,
+		"duration_ms": r.Duration.Milliseconds(),
+	}
+	if r.Err != "" {
+		payload["error"] = r.Err
+	}
+	return payload
+}
+
+// Input is the JSON a hook reads on stdin.
+type Input struct {
+	Event   string       `json:"event"`
+	Session SessionInput `json:"session"`
+}
+
+// SessionInput has the fields of lockin status -o json, plus how the
+// session ended once it has and the break that follows it.
+type SessionInput struct {
+	ID               int64  `json:"id"`
+	Phase            string `json:"phase"`
+	Profile          string `json:"profile"`
+	Strict           bool   `json:"strict"`
+	StartedAt        string `json:"started_at"`
+	EndsAt           string `json:"ends_at"`
+	DurationSeconds  int64  `json:"duration_seconds"`
+	RemainingSeconds int64  `json:"remaining_seconds"`
+	Status           string `json:"status,omitempty"`
+	BreakSeconds     int64  `json:"break_seconds"`
+}
+
+func newInput(event string, session models.Session, now time.Time) Input {
+	phase := session.Phase(now)
+	// A break cut short by the next session is over before its time is.
+	if event == EventBreakEnd {
+		phase = models.PhaseEnded
+	}
+	return Input{
+		Event: event,
+		Session: SessionInput{
+			ID:               session.ID,
+			Phase:            phase,
+			Profile:          session.Profile,
+			Strict:           session.Strict,
+			StartedAt:        time.Unix(session.StartTime, 0).UTC().Format(time.RFC3339),
+			EndsAt:           session.EndTime().UTC().Format(time.RFC3339),
+			DurationSeconds:  session.DurationSeconds,
+			RemainingSeconds: session.RemainingAt(now),
+			Status:           session.Status,
+			BreakSeconds:     session.BreakSeconds,
+		},
+	}
+}
+
+// env returns in as LOCKIN_* variables.
+func (in Input) env() []string {
+	s := in.Session
+	return []string{
+		"LOCKIN_EVENT=" + in.Event,
+		"LOCKIN_SESSION_ID=" + strconv.FormatInt(s.ID, 10),
+		"LOCKIN_PHASE=" + s.Phase,
+		"LOCKIN_PROFILE=" + s.Profile,
+		"LOCKIN_STRICT=" + strconv.FormatBool(s.Strict),
+		"LOCKIN_STARTED_AT=" + s.StartedAt,
+		"LOCKIN_ENDS_AT=" + s.EndsAt,
+		"LOCKIN_DURATION_SECONDS=" + strconv.FormatInt(s.DurationSeconds, 10),
+		"LOCKIN_REMAINING_SECONDS=" + strconv.FormatInt(s.RemainingSeconds, 10),
+		"LOCKIN_STATUS=" + s.Status,
+		"LOCKIN_BREAK_SECONDS=" + strconv.FormatInt(s.BreakSeconds, 10),
+	}
+}
+
+// Run runs every hook for event, one after another: the hooks directory's
+// executables in name order, then the commands for event.
+func (h Hooks) Run(event string, session models.Session, now time.Time) []Result {
+	in := newInput(event, session, now)
+	stdin, err := json.Marshal(in)
+	if err != nil {
+		return []Result{{Hook: h.Dir, Err: err.Error()}}
+	}
+
+	var results []Result
+	executables, err := h.executables()
+	if err != nil {
+		results = append(results, Result{Hook: h.Dir, Err: err.Error()})
+	}
+	for _, path := range executables {
+		results = append(results, h.run(path, []string{path}, in, stdin))
+	}
+	for _, command := range h.commands(event) {
+		results = append(results, h.run(command, []string{"/bin/sh", "-c", command}, in, stdin))
+	}
+	return results
+}
+
+func (h Hooks) commands(event string) []string {
+	switch event {
+	case EventStart:
+		return h.Start
+	case EventEnd:
+		return h.End
+	case EventBreakStart:
+		return h.BreakStart
+	case EventBreakEnd:
+		return h.BreakEnd
+	}
+	return nil
+}
+
+// executables lists the hooks directory. A directory that doesn't exist
+// has no hooks in it.
+func (h Hooks) executables() ([]string, error) {
+	if h.Dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(h.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading hooks: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(h.Dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// run runs args as the hook named name, killing it once the timeout is up.
+func (h Hooks) run(name string, args []string, in Input, stdin []byte) Result {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), in.env()...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = waitDelay
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{Hook: name, Output: truncate(output.String()), Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.Err = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Err = err.Error()
+	}
+	return result
+}
+
+func truncate(output string) string {
+	if len(output) <= MaxOutput {
+		return output
+	}
+	return output[:MaxOutput] + "\n[output cut]"
+}
@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+var (
+	now     = time.Date(2026, 3, 2, 9, 10, 0, 0, time.UTC)
+	session = models.Session{ID: 7, StartTime: now.Add(-10 * time.Minute).Unix(), DurationSeconds: 1500,
+		Active: true, Strict: true, Profile: "work"}
+)
+
+// writeHook writes an executable script named name into dir
+func writeHook(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), mode)
+	if err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+}
+
+// Test Run - directory hooks run in name order before the event's
+// commands, and files that aren't hooks are skipped
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "20-playlist", "echo playlist $LOCKIN_EVENT\n", 0755)
+	writeHook(t, dir, "10-slack", "echo slack $LOCKIN_EVENT\nexit 3\n", 0755)
+	writeHook(t, dir, "notes.txt", "echo not a hook\n", 0644)
+	writeHook(t, dir, ".hidden", "echo hidden\n", 0755)
+	os.Mkdir(filepath.Join(dir, "old"), 0755)
+
+	h := Hooks{Dir: dir, Start: []string{"echo start command >&2"}, End: []string{"echo end command"}}
+	results := h.Run(EventStart, session, now)
+
+	want := []Result{
+		{Hook: filepath.Join(dir, "10-slack"), ExitCode: 3, Output: "slack start\n"},
+		{Hook: filepath.Join(dir, "20-playlist"), Output: "playlist start\n"},
+		{Hook: "echo start command >&2", Output: "start command\n"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Run() = %+v, want %d results", results, len(want))
+	}
+	for i, got := range results {
+		got.Duration = 0
+		if got != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	if results := (Hooks{Dir: filepath.Join(dir, "missing")}).Run(EventEnd, session, now); len(results) != 0 {
+		t.Errorf("Run() with no hooks directory = %+v, want nothing", results)
+	}
+
+	h = Hooks{Start: []string{"echo start"}, BreakStart: []string{"echo $LOCKIN_EVENT"}, BreakEnd: []string{"echo back"}}
+	for event, want := range map[string]string{EventBreakStart: "break_start\n", EventBreakEnd: "back\n"} {
+		if results := h.Run(event, session, now); len(results) != 1 || results[0].Output != want {
+			t.Errorf("Run(%s) = %+v, want its command to print %q", event, results, want)
+		}
+	}
+}
+
+// Test Run input - a hook gets the session in its environment and as JSON
+// on stdin
+func TestRunInput(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, "env", "env | grep ^LOCKIN_ | sort > "+filepath.Join(dir, "env.out")+"\n", 0755)
+	writeHook(t, dir, "stdin", "cat > "+filepath.Join(dir, "stdin.out")+"\n", 0755)
+
+	ended := session
+	ended.Complete()
+	results := Hooks{Dir: dir}.Run(EventEnd, ended, now)
+	for _, result := range results {
+		if result.Err != "" || result.ExitCode != 0 {
+			t.Fatalf("hook %s failed: %+v", result.Hook, result)
+		}
+	}
+
+	env, _ := os.ReadFile(filepath.Join(dir, "env.out"))
+	wantEnv := strings.Join([]string{
+		"LOCKIN_BREAK_SECONDS=0",
+		"LOCKIN_DURATION_SECONDS=1500",
+		"LOCKIN_ENDS_AT=2026-03-02T09:25:00Z",
+		"LOCKIN_EVENT=end",
+		"LOCKIN_PHASE=ended",
+		"LOCKIN_PROFILE=work",
+		"LOCKIN_REMAINING_SECONDS=900",
+		"LOCKIN_SESSION_ID=7",
+		"LOCKIN_STARTED_AT=2026-03-02T09:00:00Z",
+		"LOCKIN_STATUS=completed",
+		"LOCKIN_STRICT=true",
+	}, "\n") + "\n"
+	if string(env) != wantEnv {
+		t.Errorf("hook environment:\n%s\nwant:\n%s", env, wantEnv)
+	}
+
+	stdin, _ := os.ReadFile(filepath.Join(dir, "stdin.out"))
+	var in Input
+	err := json.Unmarshal(stdin, &in)
+	if err != nil {
+		t.Fatalf("hook stdin isn't JSON: %v\n%s", err, stdin)
+	}
+	if in.Event != EventEnd || in.Session.ID != 7 || in.Session.Status != models.StatusCompleted || !in.Session.Strict {
+		t.Errorf("hook stdin = %+v", in)
+	}
+}
+
+// Test Run timeout - a hook that runs too long is killed and the timeout
+// recorded, and long output is cut
+func TestRunLimits(t *testing.T) {
+	h := Hooks{
+		Start:   []string{"echo before; sleep 10", "head -c 10000 /dev/zero | tr '\\0' x"},
+		Timeout: 200 * time.Millisecond,
+	}
+	start := time.Now()
+	results := h.Run(EventStart, session, now)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s, want the slow hook killed", elapsed)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Run() = %+v, want 2 results", results)
+	}
+
+	slow := results[0]
+	if slow.Err != "timed out after 200ms" || slow.ExitCode != -1 || slow.Output != "before\n" {
+		t.Errorf("slow hook = %+v, want a timeout with its output", slow)
+	}
+	if got := results[1].Output; len(got) != MaxOutput+len("\n[output cut]") || !strings.HasSuffix(got, "[output cut]") {
+		t.Errorf("chatty hook output is %d bytes, want it cut to %d", len(got), MaxOutput)
+	}
+
+	payload := slow.Payload(EventStart)
+	if payload["error"] != slow.Err || payload["event"] != EventStart || payload["hook"] != "echo before; sleep 10" {
+		t.Errorf("Payload() = %v", payload)
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatDuration prints d to the minute as "1h30m", "2h" or "25m", the way
+// session lengths are shown everywhere.
+func FormatDuration(d time.Duration) string {
+	return FormatDurationExact(d.Round(time.Minute))
+}
+
+// FormatDurationExact is FormatDuration to the second. Seconds only show
+// when there are some, as in "42m13s" or "1h00m05s".
+func FormatDurationExact(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	s := int(d % time.Minute / time.Second)
+
+	switch {
+	case s != 0 && h > 0:
+		return fmt.Sprintf("%dh%02dm%02ds", h, m, s)
+	case s != 0:
+		return fmt.Sprintf("%dm%02ds", m, s)
+	case h > 0 && m == 0:
+		return fmt.Sprintf("%dh", h)
+	case h > 0:
+		return fmt.Sprintf("%dh%02dm", h, m)
+	}
+	return fmt.Sprintf("%dm", m)
+}
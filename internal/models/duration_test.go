@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in       time.Duration
+		expected string
+		exact    string
+	}{
+		{25 * time.Minute, "25m", "25m"},
+		{time.Hour, "1h", "1h"},
+		{90 * time.Minute, "1h30m", "1h30m"},
+		{42*time.Minute + 13*time.Second, "42m", "42m13s"},
+		{time.Hour + 5*time.Second, "1h", "1h00m05s"},
+		{2*time.Hour + 7*time.Minute + 40*time.Second, "2h08m", "2h07m40s"},
+		{0, "0m", "0m"},
+	}
+
+	for _, test := range tests {
+		if got := FormatDuration(test.in); got != test.expected {
+			t.Errorf("FormatDuration(%s) = %q, expected %q", test.in, got, test.expected)
+		}
+		if got := FormatDurationExact(test.in); got != test.exact {
+			t.Errorf("FormatDurationExact(%s) = %q, expected %q", test.in, got, test.exact)
+		}
+	}
+}
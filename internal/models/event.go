@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// SessionEvent is one entry in a session's event log. The log is only ever
+// appended to, so it is the record history, stats and accountability
+// features read from.
+type SessionEvent struct {
+	ID        int64
+	SessionID int64
+	Type      string
+	// At is when it happened, in Unix seconds.
+	At int64
+	// Payload holds details that depend on Type, such as the duration a
+	// session started with or the domains a tamper removed.
+	Payload map[string]any
+}
+
+// Event types. Extended is logged with negative by_seconds when a session
+// is shortened.
+const (
+	EventStarted   = "started"
+	EventExtended  = "extended"
+	EventTamper    = "tamper_detected"
+	EventCompleted = "completed"
+	EventAborted   = "aborted"
+	// EventDeleted marks a session removed from history. Its events stay,
+	// so the log still accounts for it.
+	EventDeleted = "deleted"
+	// EventHook records a user hook run for the session and what it
+	// printed.
+	EventHook = "hook_ran"
+	// EventRestored marks where a backup was restored. It is about the
+	// whole database, so it is logged under session 0; the backup's own
+	// events follow it, marked restored.
+	EventRestored = "restored"
+)
+
+// NewEvent returns an event of type typ that happened at at.
+func NewEvent(typ string, at time.Time, payload map[string]any) SessionEvent {
+	return SessionEvent{Type: typ, At: at.Unix(), Payload: payload}
+}
+
+// Time is At as a time.Time.
+func (e SessionEvent) Time() time.Time {
+	return time.Unix(e.At, 0)
+}
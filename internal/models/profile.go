@@ -0,0 +1,8 @@
+package models
+
+// Profile is a named kind of session, like "work" or "study".
+type Profile struct {
+	ID              int64
+	Name            string
+	DurationSeconds int64
+}
@@ -9,12 +9,35 @@ type Session struct {
 	StartTime       int64
 	DurationSeconds int64
 	Active          bool
+	Profile         string
+	// Strict sessions can't be stopped early.
+	Strict bool
+	// EndedAt is when the session finished, or 0 while it runs.
+	EndedAt int64
+	// Status is how the session finished: StatusCompleted, StatusAborted,
+	// or empty while it runs and for sessions from before it was recorded.
+	Status      string
+	TamperCount int64
+	Note        string
+	// BreakSeconds is the break that follows the session once it
+	// completes, or 0 for none. Sites stay unblocked through it.
+	BreakSeconds int64
 }
 
+// How a session finished.
+const (
+	StatusCompleted = "completed"
+	StatusAborted   = "aborted"
+)
+
 func (s *Session) Remaining() int64 {
-	now := time.Now().Unix()
+	return s.RemainingAt(time.Now())
+}
+
+// RemainingAt is Remaining as seen at now, for callers with their own clock.
+func (s *Session) RemainingAt(now time.Time) int64 {
 	end := s.StartTime + int64(s.DurationSeconds)
-	remaining := end - now
+	remaining := end - now.Unix()
 
 	if remaining < 0 {
 		remaining = 0
@@ -26,6 +49,36 @@ func (s *Session) Expired() bool {
 	return s.Remaining() == 0
 }
 
+// EndTime is when the session runs out.
+func (s *Session) EndTime() time.Time {
+	return time.Unix(s.StartTime+s.DurationSeconds, 0)
+}
+
+// BreakEndTime is when the break after the session runs out.
+func (s *Session) BreakEndTime() time.Time {
+	return s.EndTime().Add(time.Duration(s.BreakSeconds) * time.Second)
+}
+
+// Session phases as reported to the UI and to scripts.
+const (
+	PhaseFocus = "focus"
+	PhaseBreak = "break"
+	PhaseEnded = "ended"
+)
+
+// Phase reports whether the session is still focusing at now, on the
+// break that follows it, or over. Only a session that ran its full length
+// gets its break.
+func (s *Session) Phase(now time.Time) string {
+	if s.Active && s.RemainingAt(now) > 0 {
+		return PhaseFocus
+	}
+	if !s.Active && s.Status == StatusCompleted && s.BreakSeconds > 0 && now.Before(s.BreakEndTime()) {
+		return PhaseBreak
+	}
+	return PhaseEnded
+}
+
 func (s *Session) RemainingMinutes() int64 {
 	return s.Remaining() / 60
 }
@@ -42,3 +95,26 @@ func (s *Session) Start() {
 func (s *Session) Stop() {
 	s.Active = false
 }
+
+// Complete marks the session as having run its full length.
+func (s *Session) Complete() {
+	s.Stop()
+	s.Status = StatusCompleted
+	s.EndedAt = s.StartTime + s.DurationSeconds
+}
+
+// Abort marks the session as stopped early at now.
+func (s *Session) Abort(now time.Time) {
+	s.Stop()
+	s.Status = StatusAborted
+	s.EndedAt = now.Unix()
+}
+
+// ActualSeconds is how long the session really ran, or 0 if that isn't
+// known.
+func (s *Session) ActualSeconds() int64 {
+	if s.EndedAt == 0 {
+		return 0
+	}
+	return s.EndedAt - s.StartTime
+}
@@ -0,0 +1,161 @@
+// Package notify shows desktop notifications for session events. It talks
+// to the freedesktop notification service over D-Bus, runs notify-send, or
+// does nothing, depending on the backend configured.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// AppName is who notifications say they are from.
+const AppName = "LockIn"
+
+// Backends New accepts.
+const (
+	BackendAuto       = "auto"
+	BackendDBus       = "dbus"
+	BackendNotifySend = "notify-send"
+	BackendNone       = "none"
+)
+
+// Notification is one message for the desktop.
+type Notification struct {
+	Summary string
+	Body    string
+	// Urgent asks the desktop to keep it up until it is dismissed.
+	Urgent bool
+}
+
+// Notifier shows notifications.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// New returns the notifier for backend. Auto goes over D-Bus and falls
+// back to notify-send when there is no session bus to reach.
+func New(backend string) Notifier {
+	switch backend {
+	case BackendNone:
+		return Nop{}
+	case BackendDBus:
+		return DBus{}
+	case BackendNotifySend:
+		return Exec{}
+	}
+	return fallback{DBus{}, Exec{}}
+}
+
+// Nop drops every notification.
+type Nop struct{}
+
+func (Nop) Notify(n Notification) error { return nil }
+
+// fallback tries each notifier in turn until one works.
+type fallback []Notifier
+
+func (f fallback) Notify(n Notification) error {
+	var errs []error
+	for _, notifier := range f {
+		err := notifier.Notify(n)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+//***********************************************************//
+// D-Bus
+//***********************************************************//
+
+const (
+	dbusName   = "org.freedesktop.Notifications"
+	dbusPath   = "/org/freedesktop/Notifications"
+	dbusNotify = dbusName + ".Notify"
+)
+
+// Urgency levels from the notification spec.
+const (
+	urgencyNormal   byte = 1
+	urgencyCritical byte = 2
+)
+
+// DBus sends notifications to org.freedesktop.Notifications on the session
+// bus. It connects for each one, so a desktop session that starts after
+// lockind is still reached.
+type DBus struct{}
+
+func (DBus) Notify(n Notification) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("error connecting to the session bus: %w", err)
+	}
+	defer conn.Close()
+
+	urgency := urgencyNormal
+	if n.Urgent {
+		urgency = urgencyCritical
+	}
+	call := conn.Object(dbusName, dbusPath).Call(dbusNotify, 0,
+		AppName,
+		uint32(0), // replaces no earlier notification
+		"",        // no icon
+		n.Summary,
+		n.Body,
+		[]string{}, // no actions
+		map[string]dbus.Variant{"urgency": dbus.MakeVariant(urgency)},
+		int32(-1), // the desktop's default timeout
+	)
+	if call.Err != nil {
+		return fmt.Errorf("error sending notification: %w", call.Err)
+	}
+	return nil
+}
+
+//***********************************************************//
+// notify-send
+//***********************************************************//
+
+// execTimeout is how long notify-send gets to return.
+const execTimeout = 10 * time.Second
+
+// Exec runs notify-send, or Command if set, with the notification as
+// arguments.
+type Exec struct {
+	Command string
+}
+
+func (e Exec) Notify(n Notification) error {
+	command := e.Command
+	if command == "" {
+		command = "notify-send"
+	}
+	urgency := "normal"
+	if n.Urgent {
+		urgency = "critical"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command, "--app-name="+AppName, "--urgency="+urgency, "--", n.Summary, n.Body)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("%s failed: %v: %s", command, err, out)
+		}
+		return fmt.Errorf("%s failed: %v", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeNotifySend writes a script that saves its arguments, one per line,
+// and returns its path and the file they go to.
+func fakeNotifySend(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	path := filepath.Join(dir, "notify-send")
+	script := "#!/bin/sh\nfor arg in \"$@\"; do echo \"$arg\" >> " + out + "; done\n"
+	err := os.WriteFile(path, []byte(script), 0755)
+	if err != nil {
+		t.Fatalf("Failed to write fake notify-send: %v", err)
+	}
+	return path, out
+}
+
+// Test Exec - the notification is passed as notify-send arguments, and a
+// missing command is an error
+func TestExec(t *testing.T) {
+	command, out := fakeNotifySend(t)
+
+	err := Exec{Command: command}.Notify(Notification{Summary: "5 minutes left", Body: "-ends at 10:30", Urgent: true})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	want := "--app-name=LockIn\n--urgency=critical\n--\n5 minutes left\n-ends at 10:30\n"
+	if string(got) != want {
+		t.Errorf("notify-send got:\n%s\nwant:\n%s", got, want)
+	}
+
+	err = Exec{Command: filepath.Join(t.TempDir(), "missing")}.Notify(Notification{Summary: "hi"})
+	if err == nil {
+		t.Error("Notify() with a missing command succeeded")
+	}
+}
+
+// notificationServer stands in for the desktop's notification daemon.
+type notificationServer struct {
+	mu   sync.Mutex
+	got  []Notification
+	apps []string
+}
+
+func (s *notificationServer) Notify(app string, replaces uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urgency, _ := hints["urgency"].Value().(byte)
+	s.got = append(s.got, Notification{Summary: summary, Body: body, Urgent: urgency == urgencyCritical})
+	s.apps = append(s.apps, app)
+	return uint32(len(s.got)), nil
+}
+
+// privateBus starts a session bus of its own with a notification server on
+// it and points DBUS_SESSION_BUS_ADDRESS at it. It skips the test where
+// dbus-daemon isn't installed.
+func privateBus(t *testing.T) *notificationServer {
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon isn't installed")
+	}
+	cmd := exec.Command(path, "--session", "--nofork", "--print-address=1")
+	stdout, _ := cmd.StdoutPipe()
+	err = cmd.Start()
+	if err != nil {
+		t.Skipf("dbus-daemon didn't start: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Skipf("dbus-daemon didn't print its address: %v", err)
+	}
+	address = strings.TrimSpace(address)
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect to the private bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	server := &notificationServer{}
+	err = conn.Export(server, dbusPath, dbusName)
+	if err != nil {
+		t.Fatalf("Failed to export the notification server: %v", err)
+	}
+	reply, err := conn.RequestName(dbusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v", dbusName, err)
+	}
+
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", address)
+	return server
+}
+
+// Test DBus - notifications reach the notification service on a private
+// session bus
+func TestDBus(t *testing.T) {
+	server := privateBus(t)
+
+	err := DBus{}.Notify(Notification{Summary: "Focus session started", Body: "50m, until 10:30"})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	err = DBus{}.Notify(Notification{Summary: "Focus session complete", Urgent: true})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	want := []Notification{
+		{Summary: "Focus session started", Body: "50m, until 10:30"},
+		{Summary: "Focus session complete", Urgent: true},
+	}
+	if len(server.got) != len(want) || server.got[0] != want[0] || server.got[1] != want[1] {
+		t.Errorf("server got %+v, want %+v", server.got, want)
+	}
+	if server.apps[0] != AppName {
+		t.Errorf("app name = %q, want %q", server.apps[0], AppName)
+	}
+}
+
+// Test New - each backend name gives its notifier, and auto falls back to
+// notify-send without a session bus
+func TestNew(t *testing.T) {
+	if _, ok := New(BackendNone).(Nop); !ok {
+		t.Errorf("New(none) = %T, want Nop", New(BackendNone))
+	}
+	if _, ok := New(BackendDBus).(DBus); !ok {
+		t.Errorf("New(dbus) = %T, want DBus", New(BackendDBus))
+	}
+	if _, ok := New(BackendNotifySend).(Exec); !ok {
+		t.Errorf("New(notify-send) = %T, want Exec", New(BackendNotifySend))
+	}
+
+	command, out := fakeNotifySend(t)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+filepath.Join(t.TempDir(), "no-bus"))
+	t.Setenv("PATH", filepath.Dir(command))
+	err := New(BackendAuto).Notify(Notification{Summary: "hi"})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got, _ := os.ReadFile(out); !strings.Contains(string(got), "hi\n") {
+		t.Errorf("notify-send got %q, want the fallback to run it", got)
+	}
+}
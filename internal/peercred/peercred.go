@@ -0,0 +1,6 @@
+// Package peercred identifies the user on the other end of a Unix socket.
+package peercred
+
+import "errors"
+
+var ErrUnsupported = errors.New("peer credentials are not supported on this platform")
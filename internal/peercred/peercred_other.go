@@ -0,0 +1,10 @@
+//go:build !linux
+
+package peercred
+
+import "net"
+
+// UID is only implemented on Linux.
+func UID(conn net.Conn) (int, error) {
+	return 0, ErrUnsupported
+}
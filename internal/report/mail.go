@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// sendTimeout is how long the mail command gets to take the message.
+const sendTimeout = 30 * time.Second
+
+// Message is a rendered report addressed for mailing.
+type Message struct {
+	From        string
+	To          string
+	Subject     string
+	Date        time.Time
+	ContentType string
+	Body        []byte
+}
+
+// Bytes is the message as sendmail reads it: headers, a blank line and the
+// body. It fails if a header would carry a line break, which would let a
+// configured address or subject add headers of its own, or if an address
+// doesn't parse.
+func (m Message) Bytes() ([]byte, error) {
+	err := m.check()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if m.From != "" {
+		fmt.Fprintf(&b, "From: %s\n", m.From)
+	}
+	fmt.Fprintf(&b, "To: %s\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\n", m.Date.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\n")
+	fmt.Fprintf(&b, "Content-Type: %s\n", m.ContentType)
+	fmt.Fprintf(&b, "\n")
+	b.Write(m.Body)
+	return b.Bytes(), nil
+}
+
+func (m Message) check() error {
+	for _, header := range []struct{ name, value string }{
+		{"From", m.From},
+		{"To", m.To},
+		{"Subject", m.Subject},
+		{"Content-Type", m.ContentType},
+	} {
+		if strings.ContainsAny(header.value, "\r\n") {
+			return fmt.Errorf("%s header can't contain a line break", header.name)
+		}
+	}
+
+	if m.From != "" {
+		_, err := mail.ParseAddress(m.From)
+		if err != nil {
+			return fmt.Errorf("invalid sender %q: %w", m.From, err)
+		}
+	}
+	_, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", m.To, err)
+	}
+	return nil
+}
+
+// Send pipes msg to command, which is split on spaces and run without a
+// shell. The command has to read the recipients from the headers, as
+// sendmail -t does.
+func Send(command string, msg Message) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return fmt.Errorf("no mail command set")
+	}
+	if msg.To == "" {
+		return fmt.Errorf("no recipient set")
+	}
+	message, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(message)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s took longer than %s", args[0], sendTimeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("%s failed: %v: %s", args[0], err, out)
+		}
+		return fmt.Errorf("%s failed: %v", args[0], err)
+	}
+	return nil
+}
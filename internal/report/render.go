@@ -0,0 +1,269 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+// Formats a report renders in.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatText     = "text"
+)
+
+var Formats = []string{FormatMarkdown, FormatHTML, FormatText}
+
+// Render writes r in format.
+func Render(w io.Writer, r *Report, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return markdownTemplate.Execute(w, r)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, r)
+	case FormatText:
+		return textTemplate.Execute(w, r)
+	}
+	return fmt.Errorf("unknown report format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// Extension is the file extension for format.
+func Extension(format string) string {
+	switch format {
+	case FormatHTML:
+		return ".html"
+	case FormatText:
+		return ".txt"
+	}
+	return ".md"
+}
+
+// ContentType is the MIME type for format.
+func ContentType(format string) string {
+	switch format {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+//***********************************************************//
+// Templates
+//***********************************************************//
+
+var funcs = map[string]any{
+	"duration": models.FormatDuration,
+	"when":     func(t time.Time) string { return t.Format("Mon 2 Jan 15:04") },
+	"rate": func(rate int) string {
+		if rate < 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf("%d%%", rate)
+	},
+	"join":   func(list []string) string { return strings.Join(list, ", ") },
+	"orDash": orDash,
+	"cell":   markdownCell,
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// markdownCell keeps s inside one table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+const markdownSource = `# LockIn focus report: {{.Title}}
+
+{{.Period}}
+
+## Summary
+
+| | |
+|---|---|
+| Planned sessions | {{len .Sessions}} ({{duration .Planned}}) |
+| Completed | {{.Completed}} |
+| Ended early | {{len .EndedEarly}} |
+{{- with .Running}}
+| Still running | {{.}} |
+{{- end}}
+| Focus time | {{duration .Focused}} |
+| Completion rate | {{rate .CompletionRate}} |
+| Tamper events | {{len .Tampers}} |
+{{- with .DeletedSessions}}
+| Deleted from history | {{len .}} |
+{{- end}}
+{{- with .EndedEarly}}
+
+## Ended early
+{{range .}}
+- {{when .Start}}: {{duration .Ran}} of {{duration .Planned}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}{{if .Deleted}}, deleted{{end}}{{if .Note}}. {{cell .Note}}{{end}}
+{{- end}}
+{{- end}}
+{{- with .Tampers}}
+
+## Tamper events
+{{range .}}
+- {{when .At}}, session {{.SessionID}}: {{if .Missing}}removed {{join .Missing}}{{else}}the block was edited{{end}}
+{{- end}}
+{{- end}}
+{{- with .DeletedSessions}}
+
+## Deleted from history
+
+Deleted after the fact; the event log still has them.
+{{range .}}
+- {{when .Start}}: {{.Outcome}}, {{duration .Planned}} planned{{if .Ran}}, ran {{duration .Ran}}{{end}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}. Deleted {{when .DeletedAt}}
+{{- end}}
+{{- end}}
+
+## Sessions
+{{if .Sessions}}
+| Start | Profile | Planned | Ran | Outcome | Note |
+|---|---|---|---|---|---|
+{{- range .Sessions}}
+| {{when .Start}} | {{orDash (cell .Profile)}} | {{duration .Planned}} | {{if .Ran}}{{duration .Ran}}{{else}}-{{end}} | {{.Outcome}}{{if .Strict}} (strict){{end}}{{if .Deleted}} (deleted){{end}} | {{orDash (cell .Note)}} |
+{{- end}}
+{{else}}
+No sessions this week.
+{{end -}}
+`
+
+const textSource = `LockIn focus report: {{.Title}}
+{{.Period}}
+
+Planned sessions:   {{len .Sessions}} ({{duration .Planned}})
+Completed:          {{.Completed}}
+Ended early:        {{len .EndedEarly}}
+{{- with .Running}}
+Still running:      {{.}}
+{{- end}}
+Focus time:         {{duration .Focused}}
+Completion rate:    {{rate .CompletionRate}}
+Tamper events:      {{len .Tampers}}
+{{- with .DeletedSessions}}
+Deleted:            {{len .}}
+{{- end}}
+{{- with .EndedEarly}}
+
+Ended early
+{{- range .}}
+  {{when .Start}}  {{duration .Ran}} of {{duration .Planned}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}{{if .Deleted}}, deleted{{end}}{{if .Note}}. {{.Note}}{{end}}
+{{- end}}
+{{- end}}
+{{- with .Tampers}}
+
+Tamper events
+{{- range .}}
+  {{when .At}}  session {{.SessionID}}: {{if .Missing}}removed {{join .Missing}}{{else}}the block was edited{{end}}
+{{- end}}
+{{- end}}
+{{- with .DeletedSessions}}
+
+Deleted from history (the event log still has them)
+{{- range .}}
+  {{when .Start}}  {{.Outcome}}, {{duration .Planned}} planned{{if .Ran}}, ran {{duration .Ran}}{{end}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}. Deleted {{when .DeletedAt}}
+{{- end}}
+{{- end}}
+
+Sessions
+{{- range .Sessions}}
+  {{when .Start}}  {{duration .Planned}}  {{.Outcome}}{{if .Ran}} after {{duration .Ran}}{{end}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}{{if .Deleted}}, deleted{{end}}
+{{- else}}
+  No sessions this week.
+{{- end}}
+`
+
+const htmlSource = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>LockIn focus report: {{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 46em; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 0.25em 0.75em; border-bottom: 1px solid #ddd; }
+.warning { color: #b00; }
+</style>
+</head>
+<body>
+<h1>LockIn focus report: {{.Title}}</h1>
+<p>{{.Period}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Planned sessions</th><td>{{len .Sessions}} ({{duration .Planned}})</td></tr>
+<tr><th>Completed</th><td>{{.Completed}}</td></tr>
+<tr><th>Ended early</th><td>{{len .EndedEarly}}</td></tr>
+{{- with .Running}}
+<tr><th>Still running</th><td>{{.}}</td></tr>
+{{- end}}
+<tr><th>Focus time</th><td>{{duration .Focused}}</td></tr>
+<tr><th>Completion rate</th><td>{{rate .CompletionRate}}</td></tr>
+<tr><th>Tamper events</th><td{{if .Tampers}} class="warning"{{end}}>{{len .Tampers}}</td></tr>
+{{- with .DeletedSessions}}
+<tr><th>Deleted from history</th><td class="warning">{{len .}}</td></tr>
+{{- end}}
+</table>
+{{- with .EndedEarly}}
+
+<h2>Ended early</h2>
+<ul>
+{{- range .}}
+<li>{{when .Start}}: {{duration .Ran}} of {{duration .Planned}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}{{if .Deleted}}, deleted{{end}}{{if .Note}}. {{.Note}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Tampers}}
+
+<h2>Tamper events</h2>
+<ul>
+{{- range .}}
+<li class="warning">{{when .At}}, session {{.SessionID}}: {{if .Missing}}removed {{join .Missing}}{{else}}the block was edited{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .DeletedSessions}}
+
+<h2>Deleted from history</h2>
+<p>Deleted after the fact; the event log still has them.</p>
+<ul>
+{{- range .}}
+<li class="warning">{{when .Start}}: {{.Outcome}}, {{duration .Planned}} planned{{if .Ran}}, ran {{duration .Ran}}{{end}}{{if .Profile}}, {{.Profile}}{{end}}{{if .Strict}}, strict{{end}}. Deleted {{when .DeletedAt}}</li>
+{{- end}}
+</ul>
+{{- end}}
+
+<h2>Sessions</h2>
+{{- if .Sessions}}
+<table>
+<tr><th>Start</th><th>Profile</th><th>Planned</th><th>Ran</th><th>Outcome</th><th>Note</th></tr>
+{{- range .Sessions}}
+<tr><td>{{when .Start}}</td><td>{{orDash .Profile}}</td><td>{{duration .Planned}}</td><td>{{if .Ran}}{{duration .Ran}}{{else}}-{{end}}</td><td>{{.Outcome}}{{if .Strict}} (strict){{end}}{{if .Deleted}} (deleted){{end}}</td><td>{{orDash .Note}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No sessions this week.</p>
+{{- end}}
+</body>
+</html>
+`
+
+var (
+	markdownTemplate = template.Must(template.New("markdown").Funcs(funcs).Parse(markdownSource))
+	textTemplate     = template.Must(template.New("text").Funcs(funcs).Parse(textSource))
+	htmlTemplate     = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlSource))
+)
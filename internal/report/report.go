@@ -0,0 +1,274 @@
+// Package report builds the focus report lockin report sends to an
+// accountability partner: what was planned, what was finished, what was
+// cut short and any sign of the block being worked around. It renders as
+// Markdown, HTML or plain text and can be handed to a sendmail-compatible
+// command.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+// Report covers the sessions started between From and To, To exclusive.
+type Report struct {
+	From time.Time
+	To   time.Time
+
+	Sessions []Session
+	// Tampers are the times the block was found edited, oldest first.
+	Tampers []Tamper
+}
+
+// Session is one session as the report shows it.
+type Session struct {
+	ID      int64
+	Start   time.Time
+	Profile string
+	Strict  bool
+	Planned time.Duration
+	// Ran is how long it really ran, or 0 if it hasn't ended or that isn't
+	// known.
+	Ran     time.Duration
+	Outcome string
+	Note    string
+	// Deleted is set when the session was deleted from history since; the
+	// event log still has it, so the report does too.
+	Deleted   bool
+	DeletedAt time.Time
+}
+
+// Outcomes a Session can have.
+const (
+	OutcomeCompleted = "completed"
+	OutcomeAborted   = "ended early"
+	OutcomeRunning   = "running"
+	OutcomeUnknown   = "unknown"
+)
+
+// Tamper is one time the block was found edited.
+type Tamper struct {
+	At        time.Time
+	SessionID int64
+	// Missing are the domains that had been taken out of the block.
+	Missing []string
+}
+
+// New builds the report from the event log, oldest first: every session
+// started between from and to, including those deleted from history since,
+// and the tampers logged in that time. events should run past
+// to, so sessions are seen ending and being deleted after it. sessions are
+// the rows still in history, which add the notes the log doesn't keep.
+// Times are shown in from's location.
+func New(from, to time.Time, sessions []models.Session, events []models.SessionEvent) *Report {
+	r := &Report{From: from, To: to}
+	loc := from.Location()
+	inPeriod := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+
+	logged := make(map[int64]*Session)
+	for _, event := range events {
+		at := event.Time().In(loc)
+		if event.Type == models.EventStarted {
+			if inPeriod(at) {
+				profile, _ := event.Payload["profile"].(string)
+				strict, _ := event.Payload["strict"].(bool)
+				logged[event.SessionID] = &Session{
+					ID:      event.SessionID,
+					Start:   at,
+					Profile: profile,
+					Strict:  strict,
+					Planned: seconds(event.Payload["duration_seconds"]),
+					Outcome: OutcomeRunning,
+				}
+			}
+			continue
+		}
+
+		if s := logged[event.SessionID]; s != nil {
+			switch event.Type {
+			case models.EventExtended:
+				s.Planned = seconds(event.Payload["duration_seconds"])
+			case models.EventCompleted:
+				s.Outcome, s.Ran = OutcomeCompleted, at.Sub(s.Start)
+			case models.EventAborted:
+				s.Outcome, s.Ran = OutcomeAborted, at.Sub(s.Start)
+			case models.EventDeleted:
+				s.Deleted, s.DeletedAt = true, at
+			}
+		}
+		if !inPeriod(at) {
+			continue
+		}
+		if event.Type == models.EventTamper {
+			r.Tampers = append(r.Tampers, Tamper{
+				At:        at,
+				SessionID: event.SessionID,
+				Missing:   stringList(event.Payload["missing"]),
+			})
+		}
+	}
+
+	for _, row := range sessions {
+		s := logged[row.ID]
+		if s == nil {
+			// Only a damaged log misses a session; show it as its row has
+			// it rather than leave it out.
+			s = fromRow(row, loc)
+		}
+		s.Note = row.Note
+		if s.Outcome == OutcomeRunning && !row.Active {
+			s.Outcome = OutcomeUnknown
+		}
+		logged[row.ID] = s
+	}
+
+	for _, s := range logged {
+		r.Sessions = append(r.Sessions, *s)
+	}
+	sort.Slice(r.Sessions, func(i, j int) bool {
+		a, b := r.Sessions[i], r.Sessions[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return a.ID < b.ID
+	})
+	return r
+}
+
+// fromRow is a session as its row in history has it.
+func fromRow(s models.Session, loc *time.Location) *Session {
+	session := &Session{
+		ID:      s.ID,
+		Start:   time.Unix(s.StartTime, 0).In(loc),
+		Profile: s.Profile,
+		Strict:  s.Strict,
+		Planned: time.Duration(s.DurationSeconds) * time.Second,
+		Ran:     time.Duration(s.ActualSeconds()) * time.Second,
+	}
+	switch {
+	case s.Active:
+		session.Outcome = OutcomeRunning
+	case s.Status == models.StatusCompleted:
+		session.Outcome = OutcomeCompleted
+	case s.Status == models.StatusAborted:
+		session.Outcome = OutcomeAborted
+	default:
+		session.Outcome = OutcomeUnknown
+	}
+	return session
+}
+
+// seconds reads a number of seconds from an event payload, where it is a
+// float64 once it has been through JSON.
+func seconds(v any) time.Duration {
+	var n int64
+	switch v := v.(type) {
+	case float64:
+		n = int64(v)
+	case int64:
+		n = v
+	case int:
+		n = int64(v)
+	case json.Number:
+		n, _ = v.Int64()
+	}
+	return time.Duration(n) * time.Second
+}
+
+// stringList reads a list of strings from an event payload, which holds
+// []any once it has been through JSON.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	}
+	return nil
+}
+
+// count returns how many sessions had outcome.
+func (r *Report) count(outcome string) int {
+	n := 0
+	for _, s := range r.Sessions {
+		if s.Outcome == outcome {
+			n++
+		}
+	}
+	return n
+}
+
+// Completed is how many sessions ran to the end.
+func (r *Report) Completed() int { return r.count(OutcomeCompleted) }
+
+// Running is how many sessions haven't ended yet.
+func (r *Report) Running() int { return r.count(OutcomeRunning) }
+
+// EndedEarly returns the sessions that were stopped before their end.
+func (r *Report) EndedEarly() []Session {
+	var sessions []Session
+	for _, s := range r.Sessions {
+		if s.Outcome == OutcomeAborted {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// DeletedSessions returns the sessions deleted from history since.
+func (r *Report) DeletedSessions() []Session {
+	var sessions []Session
+	for _, s := range r.Sessions {
+		if s.Deleted {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// Planned is the total length the sessions were started with.
+func (r *Report) Planned() time.Duration {
+	var total time.Duration
+	for _, s := range r.Sessions {
+		total += s.Planned
+	}
+	return total
+}
+
+// Focused is the total time the ended sessions ran.
+func (r *Report) Focused() time.Duration {
+	var total time.Duration
+	for _, s := range r.Sessions {
+		total += s.Ran
+	}
+	return total
+}
+
+// CompletionRate is the share of ended sessions that ran to the end, as a
+// whole percentage, or -1 when none have ended.
+func (r *Report) CompletionRate() int {
+	completed, aborted := r.Completed(), r.count(OutcomeAborted)
+	if completed+aborted == 0 {
+		return -1
+	}
+	return completed * 100 / (completed + aborted)
+}
+
+// Title names the period the report covers, e.g. "Week of 2 Mar 2026".
+func (r *Report) Title() string {
+	return "Week of " + r.From.Format("2 Jan 2006")
+}
+
+// Period spells out the days covered, e.g. "Mon 2 Mar to Sun 8 Mar 2026".
+func (r *Report) Period() string {
+	last := r.To.Add(-time.Second)
+	return r.From.Format("Mon 2 Jan") + " to " + last.Format("Mon 2 Jan 2006")
+}
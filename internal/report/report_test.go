@@ -0,0 +1,299 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// The week of Monday 2 March 2026.
+var (
+	weekStart = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	weekEnd   = weekStart.AddDate(0, 0, 7)
+)
+
+// checkGolden compares got with testdata/name, or rewrites it under -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		err := os.MkdirAll("testdata", 0755)
+		if err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		err = os.WriteFile(path, got, 0644)
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match golden file.\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func at(day, hour, minute int) time.Time {
+	return weekStart.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+// started is the event a session is logged starting with.
+func started(id int64, start time.Time, minutes int, profile string, strict bool) models.SessionEvent {
+	return models.SessionEvent{SessionID: id, Type: models.EventStarted, At: start.Unix(), Payload: map[string]any{
+		"duration_seconds": float64(minutes * 60), "profile": profile, "strict": strict,
+	}}
+}
+
+// seededWeek is a week with one of each outcome, a tamper and an
+// ended-early session deleted since. Session 2 started the week before.
+func seededWeek() *Report {
+	sessions := []models.Session{
+		{ID: 4, StartTime: at(2, 14, 0).Unix(), DurationSeconds: 3000, Strict: true, Profile: "work",
+			Status: models.StatusAborted, EndedAt: at(2, 14, 12).Unix(), Note: "fire drill | left early"},
+		{ID: 3, StartTime: at(0, 9, 0).Unix(), DurationSeconds: 1500, Profile: "work",
+			Status: models.StatusCompleted, EndedAt: at(0, 9, 25).Unix(), Note: "draft <intro> & outline"},
+		{ID: 5, StartTime: at(3, 10, 0).Unix(), DurationSeconds: 5400, Status: models.StatusCompleted, EndedAt: at(3, 11, 30).Unix()},
+		{ID: 6, StartTime: at(6, 20, 0).Unix(), DurationSeconds: 1500, Active: true},
+	}
+	events := []models.SessionEvent{
+		started(2, at(-1, 9, 0), 25, "", false),
+		started(3, at(0, 9, 0), 25, "work", false),
+		{SessionID: 3, Type: models.EventCompleted, At: at(0, 9, 25).Unix()},
+		started(4, at(2, 14, 0), 40, "work", true),
+		{SessionID: 4, Type: models.EventTamper, At: at(2, 14, 5).Unix(), Payload: map[string]any{"missing": []any{"youtube.com", "reddit.com"}}},
+		{SessionID: 4, Type: models.EventExtended, At: at(2, 14, 6).Unix(), Payload: map[string]any{"by_seconds": float64(600), "duration_seconds": float64(3000)}},
+		{SessionID: 4, Type: models.EventAborted, At: at(2, 14, 12).Unix()},
+		started(5, at(3, 10, 0), 90, "", false),
+		{SessionID: 5, Type: models.EventCompleted, At: at(3, 11, 30).Unix()},
+		started(7, at(4, 16, 0), 45, "study", false),
+		{SessionID: 7, Type: models.EventAborted, At: at(4, 16, 5).Unix()},
+		{SessionID: 7, Type: models.EventDeleted, At: at(5, 10, 0).Unix()},
+		started(6, at(6, 20, 0), 25, "", false),
+	}
+	return New(weekStart, weekEnd, sessions, events)
+}
+
+// Test New - sessions come from the event log, deleted ones included, and
+// are sorted into outcomes, and the summary adds up
+func TestNew(t *testing.T) {
+	r := seededWeek()
+
+	var ids []int64
+	for _, s := range r.Sessions {
+		ids = append(ids, s.ID)
+	}
+	if !slices.Equal(ids, []int64{3, 4, 5, 7, 6}) {
+		t.Errorf("sessions in order %v, want 3, 4, 5, 7, 6", ids)
+	}
+
+	if r.Completed() != 2 || len(r.EndedEarly()) != 2 || r.Running() != 1 {
+		t.Errorf("completed %d, ended early %d, running %d; want 2, 2, 1", r.Completed(), len(r.EndedEarly()), r.Running())
+	}
+	if r.CompletionRate() != 50 {
+		t.Errorf("CompletionRate() = %d, want 50", r.CompletionRate())
+	}
+	if got := r.Planned(); got != 235*time.Minute {
+		t.Errorf("Planned() = %v, want 3h55m", got)
+	}
+	if got := r.Focused(); got != 132*time.Minute {
+		t.Errorf("Focused() = %v, want 2h12m", got)
+	}
+	if len(r.Tampers) != 1 || strings.Join(r.Tampers[0].Missing, ",") != "youtube.com,reddit.com" {
+		t.Errorf("tampers %+v", r.Tampers)
+	}
+	if r.Sessions[0].Note != "draft <intro> & outline" {
+		t.Errorf("session 3 note = %q, want it from its row", r.Sessions[0].Note)
+	}
+	if r.Period() != "Mon 2 Mar to Sun 8 Mar 2026" {
+		t.Errorf("Period() = %q", r.Period())
+	}
+
+	empty := New(weekStart, weekEnd, nil, nil)
+	if empty.CompletionRate() != -1 {
+		t.Errorf("empty CompletionRate() = %d, want -1", empty.CompletionRate())
+	}
+}
+
+// Test New with deleted sessions - a session ended early and deleted from
+// history afterwards is still reported, as ended early and deleted
+func TestNewDeleted(t *testing.T) {
+	events := []models.SessionEvent{
+		started(9, at(1, 8, 0), 60, "work", true),
+		{SessionID: 9, Type: models.EventAborted, At: at(1, 8, 20).Unix()},
+		{SessionID: 9, Type: models.EventDeleted, At: at(1, 8, 21).Unix()},
+	}
+	r := New(weekStart, weekEnd, nil, events)
+
+	deleted := r.DeletedSessions()
+	if len(r.Sessions) != 1 || len(deleted) != 1 {
+		t.Fatalf("sessions = %+v, want the deleted one", r.Sessions)
+	}
+	s := deleted[0]
+	if s.ID != 9 || s.Outcome != OutcomeAborted || s.Ran != 20*time.Minute || !s.DeletedAt.Equal(at(1, 8, 21)) {
+		t.Errorf("deleted session = %+v, want session 9 ended early after 20m", s)
+	}
+	if len(r.EndedEarly()) != 1 {
+		t.Errorf("EndedEarly() = %+v, want the deleted session counted", r.EndedEarly())
+	}
+
+	for _, format := range Formats {
+		var b bytes.Buffer
+		err := Render(&b, r, format)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.Contains(b.String(), "Deleted from history") || !strings.Contains(b.String(), "Deleted Tue 3 Mar 08:21") {
+			t.Errorf("%s report doesn't list the deleted session:\n%s", format, b.String())
+		}
+	}
+}
+
+// Test Render - each format, for a busy week and an empty one
+func TestRender(t *testing.T) {
+	tests := []struct {
+		golden string
+		report *Report
+		format string
+	}{
+		{"week.md.golden", seededWeek(), FormatMarkdown},
+		{"week.html.golden", seededWeek(), FormatHTML},
+		{"week.txt.golden", seededWeek(), FormatText},
+		{"empty.md.golden", New(weekStart, weekEnd, nil, nil), FormatMarkdown},
+		{"empty.txt.golden", New(weekStart, weekEnd, nil, nil), FormatText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var b bytes.Buffer
+			err := Render(&b, tt.report, tt.format)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			checkGolden(t, tt.golden, b.Bytes())
+		})
+	}
+
+	err := Render(&bytes.Buffer{}, seededWeek(), "pdf")
+	if err == nil || !strings.Contains(err.Error(), `unknown report format "pdf"`) {
+		t.Errorf("Render() error = %v, want an unknown format error", err)
+	}
+}
+
+// fakeSendmail writes a script that saves its stdin to a file, or fails
+// with output, and returns its command line and the file.
+func fakeSendmail(t *testing.T, fail bool) (string, string) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "message")
+	script := "#!/bin/sh\ncat > " + out + "\n"
+	if fail {
+		script = "#!/bin/sh\necho 'no route to relay' >&2\nexit 75\n"
+	}
+	path := filepath.Join(dir, "sendmail")
+	err := os.WriteFile(path, []byte(script), 0755)
+	if err != nil {
+		t.Fatalf("Failed to write fake sendmail: %v", err)
+	}
+	return path + " -t -i", out
+}
+
+// Test Send - the message reaches the command with its headers, and a
+// failing command's output comes back in the error
+func TestSend(t *testing.T) {
+	msg := Message{
+		From:        "me@example.com",
+		To:          "Sam <sam@example.com>",
+		Subject:     "LockIn focus report: Week of 2 Mar 2026 ✓",
+		Date:        weekEnd,
+		ContentType: ContentType(FormatHTML),
+		Body:        []byte("<p>hello</p>\n"),
+	}
+
+	command, out := fakeSendmail(t, false)
+	err := Send(command, msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	want := "From: me@example.com\n" +
+		"To: Sam <sam@example.com>\n" +
+		"Subject: =?utf-8?q?LockIn_focus_report:_Week_of_2_Mar_2026_=E2=9C=93?=\n" +
+		"Date: Mon, 09 Mar 2026 00:00:00 +0000\n" +
+		"MIME-Version: 1.0\n" +
+		"Content-Type: text/html; charset=utf-8\n" +
+		"\n" +
+		"<p>hello</p>\n"
+	if string(got) != want {
+		t.Errorf("sendmail got:\n%s\nwant:\n%s", got, want)
+	}
+
+	command, _ = fakeSendmail(t, true)
+	err = Send(command, msg)
+	if err == nil || !strings.Contains(err.Error(), "no route to relay") {
+		t.Errorf("Send() error = %v, want the command's output", err)
+	}
+
+	err = Send(" ", msg)
+	if err == nil {
+		t.Error("Send() with no command succeeded")
+	}
+}
+
+// Test Message headers - a line break in a header, or an address that
+// doesn't parse, is refused before anything is sent
+func TestMessageHeaders(t *testing.T) {
+	valid := Message{From: "me@example.com", To: "Sam <sam@example.com>", Subject: "report", ContentType: ContentType(FormatText)}
+
+	tests := []struct {
+		name    string
+		edit    func(m *Message)
+		wantErr string
+	}{
+		{"valid", func(m *Message) {}, ""},
+		{"no sender", func(m *Message) { m.From = "" }, ""},
+		{"recipient injects a header", func(m *Message) { m.To = "sam@example.com\nBcc: eve@example.com" }, "To header can't contain a line break"},
+		{"sender injects a header", func(m *Message) { m.From = "me@example.com\r\nBcc: eve@example.com" }, "From header can't contain a line break"},
+		{"subject injects a header", func(m *Message) { m.Subject = "report\nBcc: eve@example.com" }, "Subject header can't contain a line break"},
+		{"recipient isn't an address", func(m *Message) { m.To = "sam at example" }, "invalid recipient"},
+		{"two recipients", func(m *Message) { m.To = "sam@example.com, eve@example.com" }, "invalid recipient"},
+		{"sender isn't an address", func(m *Message) { m.From = "me" }, "invalid sender"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid
+			tt.edit(&m)
+			_, err := m.Bytes()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Bytes() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Bytes() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	command, out := fakeSendmail(t, false)
+	err := Send(command, Message{To: "sam@example.com\nBcc: eve@example.com"})
+	if err == nil {
+		t.Error("Send() with a header injected succeeded")
+	}
+	if _, statErr := os.Stat(out); statErr == nil {
+		t.Error("Send() ran the mail command for a refused message")
+	}
+}
@@ -2,26 +2,686 @@ package service
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/youssef28m/LockIn/internal/backup"
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/models"
 	"github.com/youssef28m/LockIn/internal/storage"
 	"github.com/youssef28m/LockIn/internal/validator"
 )
 
+var (
+	ErrSessionActive   = errors.New("a session is already active")
+	ErrNoActiveSession = errors.New("no active session")
+	ErrStrictSession   = errors.New("a strict session is running; this can't be changed until it ends")
+	ErrStrictShorten   = errors.New("a strict session can be extended but not shortened")
+	ErrRestoreSession  = errors.New("a session is running; the database can't be replaced until it ends")
+)
+
+// Sessions shorter or longer than this are almost certainly typos.
+const (
+	MinDuration = time.Minute
+	MaxDuration = 12 * time.Hour
+)
+
+// MaxBreak is the longest break a session can be followed by.
+const MaxBreak = 2 * time.Hour
+
+// StartOptions describes a focus session to start. A zero Duration falls
+// back to the profile's default. Break is the break that follows the
+// session once it completes; zero means none.
+type StartOptions struct {
+	Duration time.Duration
+	Profile  string
+	Strict   bool
+	Break    time.Duration
+}
+
+// Service is the set of operations the TUI and CLI need. Local talks to the
+// database directly; the daemon client implements it over the control socket.
+type Service interface {
+	StartSession(opts StartOptions) (*models.Session, error)
+	ActiveSession() (*models.Session, error)
+	StopSession() error
+	ExtendSession(by time.Duration) (*models.Session, error)
+	History(limit int) ([]models.Session, error)
+	QueryHistory(q HistoryQuery) (*HistoryPage, error)
+	DeleteSession(id int64) error
+	AnnotateSession(id int64, note string) error
+	Profiles() ([]models.Profile, error)
+	Stats(now time.Time) (*Stats, error)
+	Events(q EventQuery) ([]models.SessionEvent, error)
+	VerifyAudit() (*storage.AuditReport, error)
+
+	AddBlockedSite(domain string) error
+	UpdateBlockedSite(site models.BlockedSite) error
+	RemoveBlockedSite(domain string) error
+	ListBlockedSites() ([]models.BlockedSite, error)
+
+	AddBlockedApp(name string) error
+	UpdateBlockedApp(app models.BlockedApp) error
+	RemoveBlockedApp(name string) error
+	ListBlockedApps() ([]models.BlockedApp, error)
+
+	ImportBlockList(list blocklist.List) (*ImportResult, error)
+
+	Backup() (*backup.Archive, error)
+	Restore(archive *backup.Archive, mode string) (*backup.Result, error)
+}
+
+// HistoryQuery selects a page of past sessions. Zero From, To and Profile
+// don't filter; To is exclusive.
+type HistoryQuery struct {
+	From    time.Time `json:"from,omitempty"`
+	To      time.Time `json:"to,omitempty"`
+	Profile string    `json:"profile,omitempty"`
+	Limit   int       `json:"limit"`
+	Offset  int       `json:"offset,omitempty"`
+}
+
+// HistoryPage is one page of a history query and how many sessions match
+// in total.
+type HistoryPage struct {
+	Sessions []models.Session `json:"sessions"`
+	Total    int              `json:"total"`
+}
+
+// EventQuery selects session events. A zero SessionID means every session
+// and a zero Limit means all of them; with a limit the newest are kept.
+// Zero From and To don't filter; To is exclusive.
+type EventQuery struct {
+	SessionID int64     `json:"session_id,omitempty"`
+	From      time.Time `json:"from,omitempty"`
+	To        time.Time `json:"to,omitempty"`
+	Limit     int       `json:"limit,omitempty"`
+}
+
+// Local implements Service on top of a database handle.
+type Local struct {
+	DB *sql.DB
+}
+
+var _ Service = (*Local)(nil)
+
+func NewLocal(db *sql.DB) *Local { return &Local{DB: db} }
+
+func (l *Local) StartSession(opts StartOptions) (*models.Session, error) {
+	return StartSession(l.DB, opts)
+}
+
+func (l *Local) ActiveSession() (*models.Session, error) { return ActiveSession(l.DB) }
+
+func (l *Local) StopSession() error { return StopSession(l.DB) }
+
+func (l *Local) ExtendSession(by time.Duration) (*models.Session, error) {
+	return ExtendSession(l.DB, by)
+}
+
+func (l *Local) History(limit int) ([]models.Session, error) {
+	return storage.GetRecentSessions(l.DB, limit)
+}
+
+func (l *Local) QueryHistory(q HistoryQuery) (*HistoryPage, error) {
+	return QueryHistory(l.DB, q)
+}
+
+func (l *Local) DeleteSession(id int64) error { return DeleteSession(l.DB, id) }
+
+func (l *Local) AnnotateSession(id int64, note string) error {
+	return AnnotateSession(l.DB, id, note)
+}
+
+func (l *Local) Profiles() ([]models.Profile, error) { return storage.GetAllProfiles(l.DB) }
+
+func (l *Local) Stats(now time.Time) (*Stats, error) { return GetStats(l.DB, now) }
+
+func (l *Local) Events(q EventQuery) ([]models.SessionEvent, error) {
+	return Events(l.DB, q)
+}
+
+func (l *Local) VerifyAudit() (*storage.AuditReport, error) { return storage.VerifyAudit(l.DB) }
+
+func (l *Local) AddBlockedSite(domain string) error { return AddBlockedSite(l.DB, domain) }
+
+func (l *Local) UpdateBlockedSite(site models.BlockedSite) error {
+	return UpdateBlockedSite(l.DB, site)
+}
+
+func (l *Local) RemoveBlockedSite(domain string) error { return RemoveBlockedSite(l.DB, domain) }
+
+func (l *Local) ListBlockedSites() ([]models.BlockedSite, error) {
+	return storage.GetAllBlockedSites(l.DB)
+}
+
+func (l *Local) AddBlockedApp(name string) error { return AddBlockedApp(l.DB, name) }
+
+func (l *Local) UpdateBlockedApp(app models.BlockedApp) error {
+	return UpdateBlockedApp(l.DB, app)
+}
+
+func (l *Local) RemoveBlockedApp(name string) error { return RemoveBlockedApp(l.DB, name) }
+
+func (l *Local) ListBlockedApps() ([]models.BlockedApp, error) {
+	return storage.GetAllBlockedApps(l.DB)
+}
+
+func (l *Local) ImportBlockList(list blocklist.List) (*ImportResult, error) {
+	return ImportBlockList(l.DB, list)
+}
+
+func (l *Local) Backup() (*backup.Archive, error) { return backup.Create(l.DB, time.Now()) }
+
+func (l *Local) Restore(archive *backup.Archive, mode string) (*backup.Result, error) {
+	return Restore(l.DB, archive, mode)
+}
+
+//***********************************************************//
+// Sessions
+//***********************************************************//
+
+// ParseDuration reads a session length such as "50m" or "1h30m". A bare
+// number is taken as minutes.
+func ParseDuration(s string) (time.Duration, error) {
+	d, err := parseLength(s)
+	if err != nil {
+		return 0, err
+	}
+	return d, ValidateDuration(d)
+}
+
+// ParseBreak reads a break length, written the same way as a session
+// length. Zero means no break.
+func ParseBreak(s string) (time.Duration, error) {
+	d, err := parseLength(s)
+	if err != nil {
+		return 0, err
+	}
+	return d, ValidateBreak(d)
+}
+
+// ParseExtension reads how much to extend a session by, written the same
+// way as a session length.
+func ParseExtension(s string) (time.Duration, error) {
+	d, err := parseLength(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("extend by at least a second")
+	}
+	return d, nil
+}
+
+func parseLength(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if minutes, err := strconv.Atoi(s); err == nil {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (try 25m or 1h30m)", s)
+	}
+	return d, nil
+}
+
+func ValidateDuration(d time.Duration) error {
+	if d < MinDuration {
+		return fmt.Errorf("session must last at least %s", MinDuration)
+	}
+	if d > MaxDuration {
+		return fmt.Errorf("session can't last longer than %s", MaxDuration)
+	}
+	return nil
+}
+
+func ValidateBreak(d time.Duration) error {
+	if d < 0 || (d > 0 && d < time.Minute) {
+		return fmt.Errorf("break must last at least a minute, or 0 for none")
+	}
+	if d > MaxBreak {
+		return fmt.Errorf("break can't last longer than %s", MaxBreak)
+	}
+	return nil
+}
 
+func StartSession(db *sql.DB, opts StartOptions) (*models.Session, error) {
+	opts.Profile = strings.TrimSpace(opts.Profile)
 
+	if opts.Duration == 0 && opts.Profile != "" {
+		profile, err := storage.GetProfileByName(db, opts.Profile)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if profile != nil {
+			opts.Duration = time.Duration(profile.DurationSeconds) * time.Second
+		}
+	}
+	err := ValidateDuration(opts.Duration)
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateBreak(opts.Break)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := ActiveSession(db)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrSessionActive
+	}
+
+	if opts.Profile != "" {
+		err := storage.EnsureProfile(db, opts.Profile, int64(opts.Duration/time.Second))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	session := models.Session{
+		DurationSeconds: int64(opts.Duration / time.Second),
+		Profile:         opts.Profile,
+		Strict:          opts.Strict,
+		BreakSeconds:    int64(opts.Break / time.Second),
+	}
+	session.Start()
+
+	payload := map[string]any{
+		"duration_seconds": session.DurationSeconds,
+		"profile":          session.Profile,
+		"strict":           session.Strict,
+	}
+	if session.BreakSeconds > 0 {
+		payload["break_seconds"] = session.BreakSeconds
+	}
+	id, err := storage.RecordSession(db, session, models.NewEvent(models.EventStarted, time.Unix(session.StartTime, 0), payload))
+	if err != nil {
+		return nil, err
+	}
+	session.ID = id
+
+	return &session, nil
+}
+
+// ActiveSession returns the running session, or nil if there is none.
+// A session that is still flagged active but has run out counts as none;
+// the scheduler flips the flag on its next tick.
+func ActiveSession(db *sql.DB) (*models.Session, error) {
+	session, err := storage.GetActiveSession(db)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.Expired() {
+		return nil, nil
+	}
+
+	return session, nil
+}
+
+func StopSession(db *sql.DB) error {
+	session, err := ActiveSession(db)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return ErrNoActiveSession
+	}
+	if session.Strict {
+		return ErrStrictSession
+	}
+
+	now := time.Now()
+	session.Abort(now)
+	_, err = storage.RecordSession(db, *session, models.NewEvent(models.EventAborted, now, map[string]any{
+		"remaining_seconds": session.RemainingAt(now),
+	}))
+	return err
+}
+
+// ValidateExtension checks that session, running at now, may have its end
+// moved by by. Time can be taken off a session that isn't strict, as long
+// as some is left; either way the whole session stays within MaxDuration.
+func ValidateExtension(session *models.Session, by time.Duration, now time.Time) error {
+	seconds := int64(by / time.Second)
+	switch {
+	case seconds == 0:
+		return fmt.Errorf("extend by at least a second")
+	case seconds < 0 && session.Strict:
+		return ErrStrictShorten
+	case session.RemainingAt(now)+seconds <= 0:
+		return fmt.Errorf("that would end the session; stop it instead")
+	case time.Duration(session.DurationSeconds+seconds)*time.Second > MaxDuration:
+		return fmt.Errorf("session can't last longer than %s", MaxDuration)
+	}
+	return nil
+}
+
+// ExtendSession moves the running session's end by by, to the second, and
+// logs how far it moved.
+func ExtendSession(db *sql.DB, by time.Duration) (*models.Session, error) {
+	session, err := ActiveSession(db)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+	now := time.Now()
+	err = ValidateExtension(session, by, now)
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := int64(by / time.Second)
+	session.DurationSeconds += seconds
+	_, err = storage.RecordSession(db, *session, models.NewEvent(models.EventExtended, now, map[string]any{
+		"by_seconds":       seconds,
+		"duration_seconds": session.DurationSeconds,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
 
+// maxNoteLength keeps notes to something that fits on a history row or two.
+const maxNoteLength = 500
+
+func QueryHistory(db *sql.DB, q HistoryQuery) (*HistoryPage, error) {
+	if q.Limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return nil, fmt.Errorf("the start of the date range must be before the end")
+	}
+
+	filter := storage.SessionFilter{Profile: strings.TrimSpace(q.Profile)}
+	if !q.From.IsZero() {
+		filter.From = q.From.Unix()
+	}
+	if !q.To.IsZero() {
+		filter.To = q.To.Unix()
+	}
+
+	total, err := storage.CountSessions(db, filter)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := storage.GetSessionsPage(db, filter, q.Limit, q.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistoryPage{Sessions: sessions, Total: total}, nil
+}
+
+// Events returns the events q selects, oldest first.
+func Events(db *sql.DB, q EventQuery) ([]models.SessionEvent, error) {
+	filter := storage.EventFilter{SessionID: q.SessionID, Limit: q.Limit}
+	if !q.From.IsZero() {
+		filter.From = q.From.Unix()
+	}
+	if !q.To.IsZero() {
+		filter.To = q.To.Unix()
+	}
+	return storage.GetSessionEvents(db, filter)
+}
+
+// DeleteSession removes a past session from the history. The running
+// session can't be deleted, since that would end it without a record.
+func DeleteSession(db *sql.DB, id int64) error {
+	active, err := ActiveSession(db)
+	if err != nil {
+		return err
+	}
+	if active != nil && active.ID == id {
+		return fmt.Errorf("the running session can't be deleted")
+	}
+
+	return storage.DeleteSession(db, id)
+}
+
+func AnnotateSession(db *sql.DB, id int64, note string) error {
+	note = strings.TrimSpace(note)
+	if len(note) > maxNoteLength {
+		return fmt.Errorf("note can't be longer than %d characters", maxNoteLength)
+	}
+
+	return storage.SetSessionNote(db, id, note)
+}
+
+// SeedProfiles creates each profile that doesn't exist yet. Existing
+// profiles keep the duration they have.
+func SeedProfiles(db *sql.DB, profiles []models.Profile) error {
+	for _, profile := range profiles {
+		name := strings.TrimSpace(profile.Name)
+		if name == "" {
+			continue
+		}
+		err := ValidateDuration(time.Duration(profile.DurationSeconds) * time.Second)
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		err = storage.EnsureProfile(db, name, profile.DurationSeconds)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNotStrict refuses changes that would weaken a running strict session.
+// Adding to the block lists is always allowed.
+func checkNotStrict(db *sql.DB) error {
+	session, err := ActiveSession(db)
+	if err != nil {
+		return err
+	}
+	if session != nil && session.Strict {
+		return ErrStrictSession
+	}
+	return nil
+}
+
+//***********************************************************//
+// Blocked sites
+//***********************************************************//
 
 func AddBlockedSite(db *sql.DB, domain string) error {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
 	validDomain := validator.IsValidDomain(domain)
 	if !validDomain {
 		return fmt.Errorf("invalid domain format")
 	}
 
-	_, err := storage.CreateBlockedSite(db, domain)
+	_, err := storage.GetBlockedSiteByDomain(db, domain)
+	if err == nil {
+		return fmt.Errorf("%s is already blocked", domain)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	_, err = storage.CreateBlockedSite(db, domain)
 	if err != nil {
 		return err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// UpdateBlockedSite changes the domain of an existing entry. Like removing,
+// it is refused while a strict session is running.
+func UpdateBlockedSite(db *sql.DB, site models.BlockedSite) error {
+	site.Domain = strings.ToLower(strings.TrimSpace(site.Domain))
+
+	if !validator.IsValidDomain(site.Domain) {
+		return fmt.Errorf("invalid domain format")
+	}
+
+	err := checkNotStrict(db)
+	if err != nil {
+		return err
+	}
+
+	existing, err := storage.GetBlockedSiteByDomain(db, site.Domain)
+	if err == nil && existing.ID != site.ID {
+		return fmt.Errorf("%s is already blocked", site.Domain)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return storage.UpdateBlockedSite(db, site)
+}
+
+func RemoveBlockedSite(db *sql.DB, domain string) error {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+
+	err := checkNotStrict(db)
+	if err != nil {
+		return err
+	}
+
+	site, err := storage.GetBlockedSiteByDomain(db, domain)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s is not blocked", domain)
+	}
+	if err != nil {
+		return err
+	}
+
+	return storage.DeleteBlockedSite(db, site.ID)
+}
+
+//***********************************************************//
+// Blocked apps
+//***********************************************************//
+
+func AddBlockedApp(db *sql.DB, name string) error {
+	name = strings.TrimSpace(name)
+
+	if !validator.IsValidProcessName(name) {
+		return fmt.Errorf("invalid process name")
+	}
+
+	_, err := storage.GetBlockedAppByName(db, name)
+	if err == nil {
+		return fmt.Errorf("%s is already blocked", name)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	_, err = storage.CreateBlockedApp(db, name)
+	return err
+}
+
+// UpdateBlockedApp renames an existing entry. Like removing, it is refused
+// while a strict session is running.
+func UpdateBlockedApp(db *sql.DB, app models.BlockedApp) error {
+	app.ProcessName = strings.TrimSpace(app.ProcessName)
+
+	if !validator.IsValidProcessName(app.ProcessName) {
+		return fmt.Errorf("invalid process name")
+	}
+
+	err := checkNotStrict(db)
+	if err != nil {
+		return err
+	}
+
+	existing, err := storage.GetBlockedAppByName(db, app.ProcessName)
+	if err == nil && existing.ID != app.ID {
+		return fmt.Errorf("%s is already blocked", app.ProcessName)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	return storage.UpdateBlockedApp(db, app)
+}
+
+func RemoveBlockedApp(db *sql.DB, name string) error {
+	name = strings.TrimSpace(name)
+
+	err := checkNotStrict(db)
+	if err != nil {
+		return err
+	}
+
+	app, err := storage.GetBlockedAppByName(db, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s is not blocked", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	return storage.DeleteBlockedApp(db, app.ID)
+}
+
+//***********************************************************//
+// Block list import
+//***********************************************************//
+
+// ImportResult counts what an import added and what was already blocked.
+type ImportResult struct {
+	AddedSites    int `json:"added_sites"`
+	AddedApps     int `json:"added_apps"`
+	ExistingSites int `json:"existing_sites"`
+	ExistingApps  int `json:"existing_apps"`
+}
+
+// ImportBlockList adds every new entry of list in one transaction. Callers
+// are expected to have dropped invalid entries with blocklist.Check; if any
+// are left, nothing is imported. Like adding, importing is allowed during
+// a strict session.
+func ImportBlockList(db *sql.DB, list blocklist.List) (*ImportResult, error) {
+	checked, rejected := blocklist.Check(list.Document())
+	if len(rejected) > 0 {
+		return nil, fmt.Errorf("block list has %d invalid entries, starting with %s", len(rejected), rejected[0])
+	}
+
+	addedSites, addedApps, err := storage.ImportBlockList(db, checked.Sites, checked.Apps)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportResult{
+		AddedSites:    addedSites,
+		AddedApps:     addedApps,
+		ExistingSites: len(checked.Sites) - addedSites,
+		ExistingApps:  len(checked.Apps) - addedApps,
+	}, nil
+}
+
+//***********************************************************//
+// Backups
+//***********************************************************//
+
+// Restore loads a backup. Merging only adds, so it is allowed at any time,
+// but replacing would pull the running session out from under the
+// scheduler and is refused until it ends.
+func Restore(db *sql.DB, archive *backup.Archive, mode string) (*backup.Result, error) {
+	if mode == backup.ModeReplace {
+		session, err := ActiveSession(db)
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			return nil, ErrRestoreSession
+		}
+	}
+	return backup.Restore(db, archive, mode)
+}
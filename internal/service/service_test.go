@@ -0,0 +1,255 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// setupServiceTestDB creates a migrated database in a temp directory
+func setupServiceTestDB(t *testing.T) *sql.DB {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = storage.InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	return db
+}
+
+// startSession starts a session for a test that needs one running
+func startSession(t *testing.T, db *sql.DB, opts StartOptions) *models.Session {
+	t.Helper()
+	session, err := StartSession(db, opts)
+	if err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	return session
+}
+
+// eventTypes lists the types of a session's events, oldest first
+func eventTypes(t *testing.T, db *sql.DB, id int64) []string {
+	t.Helper()
+	events, err := storage.GetSessionEvents(db, storage.EventFilter{SessionID: id})
+	if err != nil {
+		t.Fatalf("Failed to read events: %v", err)
+	}
+	var types []string
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// wantError checks err against want: nil, a sentinel, or text it contains
+func wantError(t *testing.T, err error, want any) {
+	t.Helper()
+	switch want := want.(type) {
+	case nil:
+		if err != nil {
+			t.Fatalf("error = %v, want none", err)
+		}
+	case error:
+		if !errors.Is(err, want) {
+			t.Fatalf("error = %v, want %v", err, want)
+		}
+	case string:
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %v, want one containing %q", err, want)
+		}
+	}
+}
+
+// Test StartSession - sessions start with the given or the profile's
+// length, and not while another runs
+func TestStartSession(t *testing.T) {
+	tests := []struct {
+		name         string
+		running      bool
+		opts         StartOptions
+		wantErr      any
+		wantDuration int64
+		wantBreak    int64
+	}{
+		{name: "plain", opts: StartOptions{Duration: 25 * time.Minute}, wantDuration: 1500},
+		{name: "with break", opts: StartOptions{Duration: 25 * time.Minute, Break: 5 * time.Minute}, wantDuration: 1500, wantBreak: 300},
+		{name: "break too short", opts: StartOptions{Duration: 25 * time.Minute, Break: time.Second}, wantErr: "at least a minute"},
+		{name: "break too long", opts: StartOptions{Duration: 25 * time.Minute, Break: 3 * time.Hour}, wantErr: "break can't last"},
+		{name: "with profile", opts: StartOptions{Duration: 50 * time.Minute, Profile: " deep "}, wantDuration: 3000},
+		{name: "profile length", opts: StartOptions{Profile: "work"}, wantDuration: 5400},
+		{name: "too short", opts: StartOptions{Duration: 30 * time.Second}, wantErr: "at least"},
+		{name: "too long", opts: StartOptions{Duration: 13 * time.Hour}, wantErr: "longer than"},
+		{name: "unknown profile and no length", opts: StartOptions{Profile: "nap"}, wantErr: "at least"},
+		{name: "already running", running: true, opts: StartOptions{Duration: 25 * time.Minute}, wantErr: ErrSessionActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			SeedProfiles(db, []models.Profile{{Name: "work", DurationSeconds: 5400}})
+			if tt.running {
+				startSession(t, db, StartOptions{Duration: time.Hour})
+			}
+
+			session, err := StartSession(db, tt.opts)
+			wantError(t, err, tt.wantErr)
+			if err != nil {
+				return
+			}
+
+			if session.DurationSeconds != tt.wantDuration || session.BreakSeconds != tt.wantBreak || !session.Active {
+				t.Errorf("session = %+v, want an active %ds session with a %ds break", session, tt.wantDuration, tt.wantBreak)
+			}
+			active, _ := ActiveSession(db)
+			if active == nil || active.ID != session.ID || active.Profile != strings.TrimSpace(tt.opts.Profile) {
+				t.Errorf("ActiveSession() = %+v, want session %d", active, session.ID)
+			}
+			if got := eventTypes(t, db, session.ID); len(got) != 1 || got[0] != models.EventStarted {
+				t.Errorf("events = %v, want one started", got)
+			}
+		})
+	}
+}
+
+// Test StopSession - a running session is aborted and logged, and a strict
+// one is refused
+func TestStopSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   *StartOptions
+		wantErr any
+	}{
+		{name: "running", start: &StartOptions{Duration: time.Hour}},
+		{name: "strict", start: &StartOptions{Duration: time.Hour, Strict: true}, wantErr: ErrStrictSession},
+		{name: "nothing running", wantErr: ErrNoActiveSession},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			var session *models.Session
+			if tt.start != nil {
+				session = startSession(t, db, *tt.start)
+			}
+
+			err := StopSession(db)
+			wantError(t, err, tt.wantErr)
+			if session == nil {
+				return
+			}
+
+			stored, _ := storage.GetSessionByID(db, session.ID)
+			events := eventTypes(t, db, session.ID)
+			if err != nil {
+				if !stored.Active || len(events) != 1 {
+					t.Errorf("refused stop changed the session: %+v, events %v", stored, events)
+				}
+				return
+			}
+			if stored.Active || stored.Status != models.StatusAborted {
+				t.Errorf("stopped session = %+v, want it aborted", stored)
+			}
+			if len(events) != 2 || events[1] != models.EventAborted {
+				t.Errorf("events = %v, want started then aborted", events)
+			}
+		})
+	}
+}
+
+// Test ExtendSession - the running session's end moves within the limits,
+// and strict sessions only get longer
+func TestExtendSession(t *testing.T) {
+	tests := []struct {
+		name         string
+		strict       bool
+		by           time.Duration
+		wantErr      any
+		wantDuration int64
+	}{
+		{name: "extend", by: 10 * time.Minute, wantDuration: 4200},
+		{name: "shorten", by: -10 * time.Minute, wantDuration: 3000},
+		{name: "extend strict", strict: true, by: 5 * time.Minute, wantDuration: 3900},
+		{name: "shorten strict", strict: true, by: -5 * time.Minute, wantErr: ErrStrictShorten},
+		{name: "shorten to nothing", by: -time.Hour, wantErr: "stop it instead"},
+		{name: "past the maximum", by: 12 * time.Hour, wantErr: "longer than"},
+		{name: "less than a second", by: time.Millisecond, wantErr: "at least a second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			started := startSession(t, db, StartOptions{Duration: time.Hour, Strict: tt.strict})
+
+			session, err := ExtendSession(db, tt.by)
+			wantError(t, err, tt.wantErr)
+
+			stored, _ := storage.GetSessionByID(db, started.ID)
+			events := eventTypes(t, db, started.ID)
+			if err != nil {
+				if stored.DurationSeconds != 3600 || len(events) != 1 {
+					t.Errorf("refused extension changed the session: %+v, events %v", stored, events)
+				}
+				return
+			}
+			if session.DurationSeconds != tt.wantDuration || stored.DurationSeconds != tt.wantDuration {
+				t.Errorf("duration = %d, stored %d; want %d", session.DurationSeconds, stored.DurationSeconds, tt.wantDuration)
+			}
+			if len(events) != 2 || events[1] != models.EventExtended {
+				t.Errorf("events = %v, want started then extended", events)
+			}
+		})
+	}
+
+	_, err := ExtendSession(setupServiceTestDB(t), time.Minute)
+	wantError(t, err, ErrNoActiveSession)
+}
+
+// Test DeleteSession - past sessions are deleted and logged, and the
+// running one is refused
+func TestDeleteSession(t *testing.T) {
+	db := setupServiceTestDB(t)
+	past := startSession(t, db, StartOptions{Duration: time.Hour})
+	StopSession(db)
+	running := startSession(t, db, StartOptions{Duration: time.Hour, Strict: true})
+
+	tests := []struct {
+		name    string
+		id      int64
+		wantErr any
+	}{
+		{name: "running", id: running.ID, wantErr: "running session can't be deleted"},
+		{name: "past", id: past.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DeleteSession(db, tt.id)
+			wantError(t, err, tt.wantErr)
+
+			stored, _ := storage.GetSessionByID(db, tt.id)
+			events := eventTypes(t, db, tt.id)
+			if err != nil {
+				if stored == nil {
+					t.Error("refused delete removed the session")
+				}
+				return
+			}
+			if stored != nil {
+				t.Errorf("session %d still exists", tt.id)
+			}
+			if events[len(events)-1] != models.EventDeleted {
+				t.Errorf("events = %v, want them to end with deleted", events)
+			}
+		})
+	}
+}
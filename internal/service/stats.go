@@ -0,0 +1,115 @@
+package service
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/storage"
+)
+
+// HeatmapWeeks is how far back Stats.Daily reaches, in whole weeks
+// including the current one.
+const HeatmapWeeks = 26
+
+// Stats summarises focus time for motivation. Days, weeks and months are
+// local to the time zone of the now passed to GetStats; weeks start on
+// Monday.
+type Stats struct {
+	TodaySeconds int64 `json:"today_seconds"`
+	WeekSeconds  int64 `json:"week_seconds"`
+	MonthSeconds int64 `json:"month_seconds"`
+	TotalSeconds int64 `json:"total_seconds"`
+	Sessions     int   `json:"sessions"`
+
+	// CurrentStreak counts consecutive days with a finished session up to
+	// today, or up to yesterday if nothing has been finished today yet.
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+
+	Completed int `json:"completed"`
+	Aborted   int `json:"aborted"`
+
+	// Daily covers the last HeatmapWeeks weeks, Weekly the last 12 weeks and
+	// Monthly the last 12 months. Periods without focus are left out.
+	Daily   []storage.FocusTotal `json:"daily"`
+	Weekly  []storage.FocusTotal `json:"weekly"`
+	Monthly []storage.FocusTotal `json:"monthly"`
+}
+
+// CompletionRate is the share of sessions with a known outcome that ran
+// to the end, or 0 when there are none.
+func (s *Stats) CompletionRate() float64 {
+	if s.Completed+s.Aborted == 0 {
+		return 0
+	}
+	return float64(s.Completed) / float64(s.Completed+s.Aborted)
+}
+
+// WeekStart returns midnight on the Monday of t's week, in t's location.
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+func GetStats(db *sql.DB, now time.Time) (*Stats, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	week := WeekStart(now)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	var stats Stats
+	var err error
+
+	stats.Daily, err = storage.FocusTotals(db, storage.PeriodDay, now.Location(), week.AddDate(0, 0, -7*(HeatmapWeeks-1)).Unix())
+	if err != nil {
+		return nil, err
+	}
+	stats.Weekly, err = storage.FocusTotals(db, storage.PeriodWeek, now.Location(), week.AddDate(0, 0, -7*11).Unix())
+	if err != nil {
+		return nil, err
+	}
+	stats.Monthly, err = storage.FocusTotals(db, storage.PeriodMonth, now.Location(), month.AddDate(0, -11, 0).Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	stats.TodaySeconds = secondsIn(stats.Daily, today)
+	stats.WeekSeconds = secondsIn(stats.Weekly, week)
+	stats.MonthSeconds = secondsIn(stats.Monthly, month)
+
+	stats.TotalSeconds, stats.Sessions, err = storage.TotalFocus(db)
+	if err != nil {
+		return nil, err
+	}
+	stats.Completed, stats.Aborted, err = storage.CompletionCounts(db)
+	if err != nil {
+		return nil, err
+	}
+
+	streaks, err := storage.FocusStreaks(db, now.Location())
+	if err != nil {
+		return nil, err
+	}
+	for _, streak := range streaks {
+		stats.LongestStreak = max(stats.LongestStreak, streak.Days)
+	}
+	// The newest streak is still going if it reaches today or yesterday.
+	if len(streaks) > 0 {
+		end := streaks[0].End
+		if end == today.Format(time.DateOnly) || end == today.AddDate(0, 0, -1).Format(time.DateOnly) {
+			stats.CurrentStreak = streaks[0].Days
+		}
+	}
+
+	return &stats, nil
+}
+
+// secondsIn returns the total for the period starting at start, or 0.
+func secondsIn(totals []storage.FocusTotal, start time.Time) int64 {
+	key := start.Format(time.DateOnly)
+	for _, total := range totals {
+		if total.Start == key {
+			return total.Seconds
+		}
+	}
+	return 0
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+//***********************************************************//
+// Audit chain
+//***********************************************************//
+
+// storedEvent is an event row as written, payload and hashes included.
+type storedEvent struct {
+	ID        int64
+	SessionID int64
+	Type      string
+	At        int64
+	Payload   string
+	PrevHash  string
+	Hash      string
+}
+
+// hash is the SHA-256 of the previous event's hash and this event's
+// contents. The ID is left out, so events keep their hashes when a restore
+// gives them new IDs; the chain still fixes their order.
+func (e storedEvent) hash() string {
+	h := sha256.New()
+	for _, field := range []string{
+		e.PrevHash,
+		strconv.FormatInt(e.SessionID, 10),
+		e.Type,
+		strconv.FormatInt(e.At, 10),
+		e.Payload,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func storedEvents(q querier) ([]storedEvent, error) {
+	rows, err := q.Query("SELECT id, session_id, type, at, payload, prev_hash, hash FROM session_events ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []storedEvent
+	for rows.Next() {
+		var e storedEvent
+		err := rows.Scan(&e.ID, &e.SessionID, &e.Type, &e.At, &e.Payload, &e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// chainEvents hashes the events logged before the chain existed, in order.
+func chainEvents(tx *sql.Tx) error {
+	events, err := storedEvents(tx)
+	if err != nil {
+		return err
+	}
+
+	prev := ""
+	for _, e := range events {
+		e.PrevHash = prev
+		prev = e.hash()
+		_, err := tx.Exec("UPDATE session_events SET prev_hash = ?, hash = ? WHERE id = ?", e.PrevHash, prev, e.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AuditReport is the outcome of checking the event log.
+type AuditReport struct {
+	// Events is how many events were checked.
+	Events int `json:"events"`
+	// Problem describes the first thing found wrong; it is empty when the
+	// log checks out.
+	Problem string `json:"problem,omitempty"`
+	// EventID and SessionID point at where the problem is, when it is
+	// about one event or session.
+	EventID   int64 `json:"event_id,omitempty"`
+	SessionID int64 `json:"session_id,omitempty"`
+}
+
+// OK reports whether nothing was found wrong.
+func (r AuditReport) OK() bool { return r.Problem == "" }
+
+// VerifyAudit walks the event chain and stops at the first broken link,
+// then checks the sessions table against the log: every session must have
+// events, and sessions the log ended must still exist, unless the log says
+// they were deleted, with the status the log gave them. The chain has no
+// secret in it, so it catches hand edits, not someone who recomputes every
+// hash after theirs or cuts the newest events off along with their
+// sessions.
+func VerifyAudit(db *sql.DB) (*AuditReport, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	events, err := storedEvents(tx)
+	if err != nil {
+		return nil, err
+	}
+	report := &AuditReport{Events: len(events)}
+
+	prev := ""
+	for _, e := range events {
+		if e.PrevHash != prev {
+			report.Problem = fmt.Sprintf("the chain breaks before event %d: an earlier event was removed or rewritten", e.ID)
+			report.EventID, report.SessionID = e.ID, e.SessionID
+			return report, nil
+		}
+		if e.hash() != e.Hash {
+			report.Problem = fmt.Sprintf("event %d (%s, session %d) was changed after it was logged", e.ID, e.Type, e.SessionID)
+			report.EventID, report.SessionID = e.ID, e.SessionID
+			return report, nil
+		}
+		prev = e.Hash
+	}
+
+	// What the log says about each session: the last way it ended, and
+	// whether it was deleted.
+	type logged struct {
+		ended   string
+		deleted bool
+	}
+	log := make(map[int64]*logged)
+	var order []int64
+	for _, e := range events {
+		// Events about the whole database, like a restore, belong to no
+		// session.
+		if e.SessionID == 0 {
+			continue
+		}
+		l := log[e.SessionID]
+		if l == nil {
+			l = &logged{}
+			log[e.SessionID] = l
+			order = append(order, e.SessionID)
+		}
+		switch e.Type {
+		case models.EventCompleted, models.EventAborted:
+			l.ended = e.Type
+		case models.EventDeleted:
+			l.deleted = true
+		}
+	}
+
+	rows, err := tx.Query("SELECT id, status FROM sessions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	status := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var s string
+		err := rows.Scan(&id, &s)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		status[id] = s
+		if log[id] == nil && report.Problem == "" {
+			report.Problem = fmt.Sprintf("session %d has no events: it was added outside LockIn", id)
+			report.SessionID = id
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if report.Problem != "" {
+		return report, nil
+	}
+
+	for _, id := range order {
+		l := log[id]
+		s, exists := status[id]
+		switch {
+		case l.deleted:
+		case !exists:
+			report.Problem = fmt.Sprintf("session %d was removed outside LockIn", id)
+		case s != l.ended:
+			report.Problem = fmt.Sprintf("session %d is marked %s but the log says %s", id, orNone(s), orNone(l.ended))
+		default:
+			continue
+		}
+		report.SessionID = id
+		return report, nil
+	}
+	return report, nil
+}
+
+func orNone(status string) string {
+	if status == "" {
+		return "not ended"
+	}
+	return status
+}
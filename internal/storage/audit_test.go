@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+// auditDB returns a database with one aborted and one completed session,
+// both recorded through the event log.
+func auditDB(t *testing.T) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	for i, end := range []func(s *models.Session, at time.Time) models.SessionEvent{
+		func(s *models.Session, at time.Time) models.SessionEvent {
+			s.Abort(at)
+			return models.NewEvent(models.EventAborted, at, map[string]any{"remaining_seconds": 900})
+		},
+		func(s *models.Session, at time.Time) models.SessionEvent {
+			s.Complete()
+			return models.NewEvent(models.EventCompleted, time.Unix(s.EndedAt, 0), nil)
+		},
+	} {
+		at := start.Add(time.Duration(i) * time.Hour)
+		session := models.Session{StartTime: at.Unix(), DurationSeconds: 1500, Active: true}
+		session.ID, err = RecordSession(db, session, models.NewEvent(models.EventStarted, at, map[string]any{"duration_seconds": 1500}))
+		if err != nil {
+			t.Fatalf("RecordSession() error = %v", err)
+		}
+		_, err = RecordSession(db, session, end(&session, at.Add(10*time.Minute)))
+		if err != nil {
+			t.Fatalf("RecordSession() error = %v", err)
+		}
+	}
+	return db
+}
+
+// Test VerifyAudit - an untouched log checks out, and each kind of hand
+// edit is reported where it was made
+func TestVerifyAudit(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(t *testing.T, db *sql.DB)
+		problem string
+		session int64
+	}{
+		{
+			name: "untouched",
+			edit: func(t *testing.T, db *sql.DB) {},
+		},
+		{
+			name: "deleted through LockIn",
+			edit: func(t *testing.T, db *sql.DB) {
+				if err := DeleteSession(db, 1); err != nil {
+					t.Fatalf("DeleteSession() error = %v", err)
+				}
+			},
+		},
+		{
+			name:    "payload edited",
+			edit:    exec(`UPDATE session_events SET payload = '{"remaining_seconds":0}' WHERE id = 2`),
+			problem: "event 2 (aborted, session 1) was changed",
+			session: 1,
+		},
+		{
+			name:    "event removed",
+			edit:    exec(`DELETE FROM session_events WHERE id = 2`),
+			problem: "the chain breaks before event 3",
+			session: 2,
+		},
+		{
+			name:    "session removed",
+			edit:    exec(`DELETE FROM sessions WHERE id = 1`),
+			problem: "session 1 was removed outside LockIn",
+			session: 1,
+		},
+		{
+			name:    "aborted session marked completed",
+			edit:    exec(`UPDATE sessions SET status = 'completed' WHERE id = 1`),
+			problem: "session 1 is marked completed but the log says aborted",
+			session: 1,
+		},
+		{
+			name:    "session added",
+			edit:    exec(`INSERT INTO sessions (start_time, duration_seconds, active, status) VALUES (1, 1500, 0, 'completed')`),
+			problem: "session 3 has no events",
+			session: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := auditDB(t)
+			tt.edit(t, db)
+
+			report, err := VerifyAudit(db)
+			if err != nil {
+				t.Fatalf("VerifyAudit() error = %v", err)
+			}
+			if tt.problem == "" {
+				if !report.OK() {
+					t.Errorf("VerifyAudit() problem = %q, want none", report.Problem)
+				}
+				return
+			}
+			if report.OK() || !strings.Contains(report.Problem, tt.problem) {
+				t.Errorf("VerifyAudit() problem = %q, want it to mention %q", report.Problem, tt.problem)
+			}
+			if report.SessionID != tt.session {
+				t.Errorf("SessionID = %d, want %d", report.SessionID, tt.session)
+			}
+		})
+	}
+}
+
+func exec(queries ...string) func(t *testing.T, db *sql.DB) {
+	return func(t *testing.T, db *sql.DB) {
+		for _, query := range queries {
+			if _, err := db.Exec(query); err != nil {
+				t.Fatalf("Failed to edit database: %v", err)
+			}
+		}
+	}
+}
+
+// Test the audit chain migration - events logged before the chain existed
+// are hashed in order and check out
+func TestChainMigration(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "old.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	err = migrateTo(db, 4)
+	if err != nil {
+		t.Fatalf("Failed to create version 4 schema: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO sessions (start_time, duration_seconds, active, ended_at, status)
+		VALUES (1000, 1500, 0, 2500, 'completed')`)
+	if err != nil {
+		t.Fatalf("Failed to insert session: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO session_events (session_id, type, at) VALUES (1, 'started', 1000), (1, 'completed', 2500)`)
+	if err != nil {
+		t.Fatalf("Failed to insert events: %v", err)
+	}
+
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("InitSchema() error = %v", err)
+	}
+	report, err := VerifyAudit(db)
+	if err != nil {
+		t.Fatalf("VerifyAudit() error = %v", err)
+	}
+	if !report.OK() || report.Events != 2 {
+		t.Errorf("VerifyAudit() = %+v, want 2 events and no problem", *report)
+	}
+}
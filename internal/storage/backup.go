@@ -0,0 +1,442 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+//***********************************************************//
+// Backups
+//***********************************************************//
+
+// Table is a table's rows as plain values, each row in Columns order.
+type Table struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// DumpTables reads every table in one transaction, so the copy is
+// consistent, and returns them with the schema version they are in.
+func DumpTables(db *sql.DB) (int, map[string]Table, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	names, err := tableNames(tx)
+	if err != nil {
+		return 0, nil, err
+	}
+	tables := make(map[string]Table, len(names))
+	for _, name := range names {
+		table, err := dumpTable(tx, name)
+		if err != nil {
+			return 0, nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+		tables[name] = table
+	}
+	return version, tables, nil
+}
+
+// tableNames lists the tables migrations created, leaving out SQLite's own.
+func tableNames(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func dumpTable(tx *sql.Tx, name string) (Table, error) {
+	rows, err := tx.Query("SELECT * FROM " + quoteIdent(name) + " ORDER BY rowid")
+	if err != nil {
+		return Table{}, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return Table{}, err
+	}
+	table := Table{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		err := rows.Scan(dest...)
+		if err != nil {
+			return Table{}, err
+		}
+		// No column holds binary data, so text read as bytes is text.
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table, rows.Err()
+}
+
+// LoadTables fills an empty database from dumped tables. It builds the
+// schema the dump was taken in, inserts the rows as they were, then runs the
+// remaining migrations, so dumps from older versions come out current.
+func LoadTables(db *sql.DB, version int, tables map[string]Table) error {
+	if version < 1 || version > len(migrations) {
+		return fmt.Errorf("schema version %d isn't supported by this build of LockIn (1 to %d)", version, len(migrations))
+	}
+	err := migrateTo(db, version)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	known, err := tableNames(tx)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i := sort.SearchStrings(known, name)
+		if i == len(known) || known[i] != name {
+			return fmt.Errorf("unknown table %q for schema version %d", name, version)
+		}
+		err := loadTable(tx, name, tables[name])
+		if err != nil {
+			return fmt.Errorf("error loading %s: %w", name, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return migrate(db)
+}
+
+func loadTable(tx *sql.Tx, name string, table Table) error {
+	if len(table.Rows) == 0 {
+		return nil
+	}
+	if len(table.Columns) == 0 {
+		return fmt.Errorf("rows without columns")
+	}
+
+	columns := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		columns[i] = quoteIdent(column)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.Prepare("INSERT INTO " + quoteIdent(name) + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders + ")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for n, row := range table.Rows {
+		if len(row) != len(columns) {
+			return fmt.Errorf("row %d has %d values for %d columns", n+1, len(row), len(columns))
+		}
+		values := make([]any, len(row))
+		for i, v := range row {
+			values[i] = plainValue(v)
+		}
+		_, err := stmt.Exec(values...)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", n+1, err)
+		}
+	}
+	return nil
+}
+
+// plainValue turns numbers decoded from JSON back into the integers SQLite
+// stored, whether they were decoded as json.Number or float64.
+func plainValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return f
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return v
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// RestoreCounts is how many rows a restore added of each kind, and how many
+// it skipped because the database already had them.
+type RestoreCounts struct {
+	Sessions int
+	Sites    int
+	Apps     int
+	Profiles int
+	// Events come with their sessions, so they aren't counted as skipped.
+	Events  int
+	Skipped int
+	// Replaced is how many sessions a replace removed from history.
+	Replaced int
+}
+
+// RestoreFrom copies the sessions and their events, block lists and
+// profiles of src, which must be in the current schema, into db in one
+// transaction. Rows get new IDs. With replace, db's own sessions, block
+// lists and profiles are deleted first; otherwise rows db already has are
+// skipped: sites, apps and profiles by name, sessions by start time and
+// length. Restored sessions are never active, since the machine the backup
+// came from has ended them one way or another.
+//
+// The event log is only ever appended to. A restored event goes first, then
+// a deleted event for each session a replace removes, then src's events
+// under their new session IDs, marked restored. The backup can't rewrite
+// or shorten the log, so an edited backup shows up there as what it is.
+func RestoreFrom(db, src *sql.DB, replace bool) (*RestoreCounts, error) {
+	sessions, err := GetAllSessions(src)
+	if err != nil {
+		return nil, err
+	}
+	sites, err := GetAllBlockedSites(src)
+	if err != nil {
+		return nil, err
+	}
+	apps, err := GetAllBlockedApps(src)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := GetAllProfiles(src)
+	if err != nil {
+		return nil, err
+	}
+	events, err := GetSessionEvents(src, EventFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = insertEvent(tx, models.NewEvent(models.EventRestored, now, map[string]any{
+		"replace":  replace,
+		"sessions": len(sessions),
+		"events":   len(events),
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &RestoreCounts{}
+	if replace {
+		counts.Replaced, err = replaceSessions(tx, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range []string{"blocked_sites", "blocked_apps", "profiles"} {
+			_, err := tx.Exec("DELETE FROM " + table)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ids, err := restoreSessions(tx, sessions)
+	if err != nil {
+		return nil, err
+	}
+	counts.Sessions = len(ids)
+
+	// Events of sessions that were skipped are already here.
+	for _, event := range events {
+		id, ok := ids[event.SessionID]
+		if !ok {
+			continue
+		}
+		event.SessionID = id
+		event.Payload = restoredPayload(event.Payload)
+		_, err := insertEvent(tx, event)
+		if err != nil {
+			return nil, err
+		}
+		counts.Events++
+	}
+
+	domains := make([]string, len(sites))
+	for i, site := range sites {
+		domains[i] = site.Domain
+	}
+	counts.Sites, err = importMissing(tx, "blocked_sites", "domain", domains)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(apps))
+	for i, app := range apps {
+		names[i] = app.ProcessName
+	}
+	counts.Apps, err = importMissing(tx, "blocked_apps", "process_name", names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		result, err := tx.Exec(
+			`INSERT INTO profiles (name, duration_seconds) VALUES (?, ?)
+			 ON CONFLICT(name) DO NOTHING`,
+			profile.Name,
+			profile.DurationSeconds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		counts.Profiles += int(n)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	counts.Skipped = len(sessions) + len(sites) + len(apps) + len(profiles) -
+		counts.Sessions - counts.Sites - counts.Apps - counts.Profiles
+	return counts, nil
+}
+
+// replaceSessions deletes every session ahead of a replace, logging each as
+// deleted so the log still accounts for it, and returns how many there were.
+func replaceSessions(tx *sql.Tx, now time.Time) (int, error) {
+	rows, err := tx.Query("SELECT id FROM sessions ORDER BY id")
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		event := models.NewEvent(models.EventDeleted, now, map[string]any{"restore": true})
+		event.SessionID = id
+		_, err := insertEvent(tx, event)
+		if err != nil {
+			return 0, err
+		}
+	}
+	_, err = tx.Exec("DELETE FROM sessions")
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// restoredPayload is payload marked as coming from a backup.
+func restoredPayload(payload map[string]any) map[string]any {
+	marked := make(map[string]any, len(payload)+1)
+	for k, v := range payload {
+		marked[k] = v
+	}
+	marked["restored"] = true
+	return marked
+}
+
+// restoreSessions inserts the sessions db doesn't have and maps their IDs
+// in src to their new ones.
+func restoreSessions(tx *sql.Tx, sessions []models.Session) (map[int64]int64, error) {
+	type key struct{ start, duration int64 }
+	existing := make(map[key]bool)
+	rows, err := tx.Query("SELECT start_time, duration_seconds FROM sessions")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var k key
+		err := rows.Scan(&k.start, &k.duration)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		existing[k] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int64]int64)
+	for _, session := range sessions {
+		k := key{session.StartTime, session.DurationSeconds}
+		if existing[k] {
+			continue
+		}
+		session.Active = false
+		id, err := insertSession(tx, session)
+		if err != nil {
+			return nil, err
+		}
+		existing[k] = true
+		ids[session.ID] = id
+	}
+	return ids, nil
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+//***********************************************************//
+// Session events
+//***********************************************************//
+
+// RecordSession saves session together with an event about it in one
+// transaction, so the log never misses a change or records one that didn't
+// happen. A session with ID 0 is inserted; otherwise it is updated. The
+// event is filed under the session's ID, which is returned.
+func RecordSession(db *sql.DB, session models.Session, event models.SessionEvent) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if session.ID == 0 {
+		session.ID, err = insertSession(tx, session)
+	} else {
+		err = updateSession(tx, session)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	event.SessionID = session.ID
+	_, err = insertEvent(tx, event)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return session.ID, nil
+}
+
+// AppendSessionEvent records an event that doesn't change its session.
+func AppendSessionEvent(db *sql.DB, event models.SessionEvent) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertEvent(tx, event)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// RecordTamper counts tampering against the session event is filed under
+// and logs event, in one transaction. The count is added to in place, so
+// nothing read before it can be written back over it.
+func RecordTamper(db *sql.DB, event models.SessionEvent) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE sessions SET tamper_count = tamper_count + 1 WHERE id = ?", event.SessionID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no session with id %d", event.SessionID)
+	}
+
+	_, err = insertEvent(tx, event)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertEvent appends event to the chain: it carries the hash of the event
+// before it, and its own hash covers that and its contents.
+func insertEvent(tx *sql.Tx, event models.SessionEvent) (int64, error) {
+	payload := []byte("{}")
+	if len(event.Payload) > 0 {
+		var err error
+		payload, err = json.Marshal(event.Payload)
+		if err != nil {
+			return 0, fmt.Errorf("error encoding %s event: %w", event.Type, err)
+		}
+	}
+
+	var prev string
+	err := tx.QueryRow("SELECT hash FROM session_events ORDER BY id DESC LIMIT 1").Scan(&prev)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	stored := storedEvent{
+		SessionID: event.SessionID,
+		Type:      event.Type,
+		At:        event.At,
+		Payload:   string(payload),
+		PrevHash:  prev,
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO session_events (session_id, type, at, payload, prev_hash, hash) VALUES (?, ?, ?, ?, ?, ?)`,
+		stored.SessionID,
+		stored.Type,
+		stored.At,
+		stored.Payload,
+		stored.PrevHash,
+		stored.hash(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// EventFilter selects events. A zero SessionID means every session, and a
+// zero Limit means no limit. From and To are Unix seconds; zero doesn't
+// filter and To is exclusive.
+type EventFilter struct {
+	SessionID int64
+	From      int64
+	To        int64
+	Limit     int
+}
+
+// GetSessionEvents returns the events filter selects, oldest first. With a
+// limit, it is the newest that are kept.
+func GetSessionEvents(db *sql.DB, filter EventFilter) ([]models.SessionEvent, error) {
+	query := "SELECT id, session_id, type, at, payload FROM session_events"
+	var where []string
+	var args []any
+	if filter.SessionID != 0 {
+		where = append(where, "session_id = ?")
+		args = append(args, filter.SessionID)
+	}
+	if filter.From != 0 {
+		where = append(where, "at >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != 0 {
+		where = append(where, "at < ?")
+		args = append(args, filter.To)
+	}
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.SessionEvent
+	for rows.Next() {
+		var event models.SessionEvent
+		var payload string
+		err := rows.Scan(&event.ID, &event.SessionID, &event.Type, &event.At, &payload)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(payload), &event.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("event %d has an invalid payload: %w", event.ID, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+	return events, nil
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+// Test RecordSession - sessions and their events are written together and
+// read back oldest first, newest kept under a limit
+func TestRecordSession(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	session := models.Session{StartTime: start.Unix(), DurationSeconds: 1500, Active: true}
+	id, err := RecordSession(db, session, models.NewEvent(models.EventStarted, start, map[string]any{"duration_seconds": 1500}))
+	if err != nil {
+		t.Fatalf("RecordSession() error = %v", err)
+	}
+	session.ID = id
+
+	session.Abort(start.Add(10 * time.Minute))
+	_, err = RecordSession(db, session, models.NewEvent(models.EventAborted, start.Add(10*time.Minute), nil))
+	if err != nil {
+		t.Fatalf("RecordSession() error = %v", err)
+	}
+	other, _ := RecordSession(db, models.Session{StartTime: start.Unix() + 3600, DurationSeconds: 600},
+		models.NewEvent(models.EventStarted, start.Add(time.Hour), nil))
+
+	stored, _ := GetSessionByID(db, id)
+	if stored.Status != models.StatusAborted {
+		t.Errorf("session status = %q, want aborted", stored.Status)
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string
+	}{
+		{"one session", EventFilter{SessionID: id}, []string{"started", "aborted"}},
+		{"every session", EventFilter{}, []string{"started", "aborted", "started"}},
+		{"newest under a limit", EventFilter{Limit: 2}, []string{"aborted", "started"}},
+		{"other session", EventFilter{SessionID: other}, []string{"started"}},
+		{"time range", EventFilter{From: start.Unix() + 300, To: start.Unix() + 3600}, []string{"aborted"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := GetSessionEvents(db, tt.filter)
+			if err != nil {
+				t.Fatalf("GetSessionEvents() error = %v", err)
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.Type)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	events, _ := GetSessionEvents(db, EventFilter{SessionID: id})
+	if events[0].Payload["duration_seconds"] != 1500.0 || len(events[1].Payload) != 0 {
+		t.Errorf("payloads = %v and %v", events[0].Payload, events[1].Payload)
+	}
+
+	// A failed event write leaves the session as it was.
+	session.Note = "changed"
+	_, err = RecordSession(db, session, models.NewEvent(models.EventTamper, start, map[string]any{"bad": func() {}}))
+	if err == nil {
+		t.Fatal("RecordSession() succeeded with a payload that can't be encoded")
+	}
+	stored, _ = GetSessionByID(db, id)
+	if stored.Note != "" {
+		t.Errorf("session note = %q, want the update rolled back", stored.Note)
+	}
+}
+
+// Test RecordTamper - tampering is counted in place, so a session read
+// before it and saved after doesn't undo the count
+func TestRecordTamper(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	session := models.Session{StartTime: start.Unix(), DurationSeconds: 1500, Active: true}
+	session.ID, _ = RecordSession(db, session, models.NewEvent(models.EventStarted, start, nil))
+
+	for range 2 {
+		event := models.NewEvent(models.EventTamper, start.Add(time.Minute), map[string]any{"missing": []string{"youtube.com"}})
+		event.SessionID = session.ID
+		err := RecordTamper(db, event)
+		if err != nil {
+			t.Fatalf("RecordTamper() error = %v", err)
+		}
+	}
+	session.DurationSeconds = 1800
+	_, err = RecordSession(db, session, models.NewEvent(models.EventExtended, start.Add(2*time.Minute), nil))
+	if err != nil {
+		t.Fatalf("RecordSession() error = %v", err)
+	}
+
+	stored, _ := GetSessionByID(db, session.ID)
+	if stored.TamperCount != 2 || stored.DurationSeconds != 1800 {
+		t.Errorf("session = %+v, want 2 tampers and the extension", stored)
+	}
+	events, _ := GetSessionEvents(db, EventFilter{SessionID: session.ID})
+	if len(events) != 4 {
+		t.Errorf("events = %+v, want started, 2 tampers and extended", events)
+	}
+
+	event := models.NewEvent(models.EventTamper, start, nil)
+	event.SessionID = 99
+	if err := RecordTamper(db, event); err == nil {
+		t.Error("RecordTamper() for a missing session = nil, want an error")
+	}
+}
+
+// Test concurrent event writes - hook events written from other goroutines
+// while tampering is recorded all land, and the chain still verifies
+func TestConcurrentEventWrites(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	id, _ := RecordSession(db, models.Session{StartTime: start.Unix(), DurationSeconds: 1500, Active: true},
+		models.NewEvent(models.EventStarted, start, nil))
+
+	const writers, writes = 8, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*writes)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range writes {
+				if i%2 == 0 {
+					event := models.NewEvent(models.EventTamper, start, nil)
+					event.SessionID = id
+					errs <- RecordTamper(db, event)
+					continue
+				}
+				event := models.NewEvent(models.EventHook, start, nil)
+				event.SessionID = id
+				_, err := AppendSessionEvent(db, event)
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("event write error = %v", err)
+		}
+	}
+
+	events, _ := GetSessionEvents(db, EventFilter{SessionID: id})
+	if len(events) != 1+writers*writes {
+		t.Errorf("got %d events, want %d", len(events), 1+writers*writes)
+	}
+	report, err := VerifyAudit(db)
+	if err != nil || !report.OK() {
+		t.Errorf("VerifyAudit() = %+v, %v; want an intact chain", report, err)
+	}
+}
+
+// Test the event log migration - sessions from before it get backfilled
+// started events, and ended ones their completed or aborted event
+func TestEventsBackfill(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "old.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	err = migrateTo(db, 3)
+	if err != nil {
+		t.Fatalf("Failed to create version 3 schema: %v", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO sessions (start_time, duration_seconds, active, profile, strict, ended_at, status)
+		VALUES (1000, 1500, 0, 'work', 1, 2500, 'completed'), (5000, 600, 0, '', 0, 0, '')`)
+	if err != nil {
+		t.Fatalf("Failed to insert sessions: %v", err)
+	}
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("InitSchema() error = %v", err)
+	}
+
+	events, err := GetSessionEvents(db, EventFilter{})
+	if err != nil {
+		t.Fatalf("GetSessionEvents() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	started, completed := events[0], events[1]
+	if started.Type != models.EventStarted || started.At != 1000 || started.Payload["profile"] != "work" ||
+		started.Payload["strict"] != true || started.Payload["backfilled"] != true {
+		t.Errorf("first event = %+v", started)
+	}
+	if completed.Type != models.EventCompleted || completed.At != 2500 {
+		t.Errorf("second event = %+v", completed)
+	}
+	if events[2].Type != models.EventStarted || events[2].At != 5000 {
+		t.Errorf("third event = %+v", events[2])
+	}
+}
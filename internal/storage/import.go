@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"database/sql"
+)
+
+//***********************************************************//
+// Block list import
+//***********************************************************//
+
+// ImportBlockList adds the sites and apps that aren't blocked yet in one
+// transaction, so either all of them are added or none are. Entries must
+// already be validated and normalized. It returns how many of each were
+// new.
+func ImportBlockList(db *sql.DB, sites, apps []string) (addedSites, addedApps int, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	addedSites, err = importMissing(tx, "blocked_sites", "domain", sites)
+	if err != nil {
+		return 0, 0, err
+	}
+	addedApps, err = importMissing(tx, "blocked_apps", "process_name", apps)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, err
+	}
+	return addedSites, addedApps, nil
+}
+
+// importMissing inserts the values not already in table's column.
+func importMissing(tx *sql.Tx, table, column string, values []string) (int, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	rows, err := tx.Query("SELECT " + column + " FROM " + table)
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var value string
+		err := rows.Scan(&value)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		existing[value] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO " + table + " (" + column + ") VALUES (?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	added := 0
+	for _, value := range values {
+		if existing[value] {
+			continue
+		}
+		_, err := stmt.Exec(value)
+		if err != nil {
+			return 0, err
+		}
+		existing[value] = true
+		added++
+	}
+	return added, nil
+}
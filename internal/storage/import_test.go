@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Test ImportBlockList - new entries are added, existing ones counted, and
+// a failure part way leaves both lists untouched
+func TestImportBlockList(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "import.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	CreateBlockedSite(db, "youtube.com")
+
+	addedSites, addedApps, err := ImportBlockList(db, []string{"youtube.com", "reddit.com"}, []string{"steam"})
+	if err != nil {
+		t.Fatalf("ImportBlockList() error = %v", err)
+	}
+	if addedSites != 1 || addedApps != 1 {
+		t.Errorf("added %d sites and %d apps, want 1 and 1", addedSites, addedApps)
+	}
+
+	// Make the app insert fail after the sites are in.
+	_, err = db.Exec(`CREATE TRIGGER refuse_boom BEFORE INSERT ON blocked_apps
+		WHEN NEW.process_name = 'boom' BEGIN SELECT RAISE(ABORT, 'boom'); END`)
+	if err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	_, _, err = ImportBlockList(db, []string{"news.example.com"}, []string{"boom"})
+	if err == nil {
+		t.Fatal("ImportBlockList() succeeded, want the trigger's error")
+	}
+
+	sites, _ := GetAllBlockedSites(db)
+	apps, _ := GetAllBlockedApps(db)
+	if len(sites) != 2 || len(apps) != 1 {
+		t.Errorf("have %d sites and %d apps after a failed import, want 2 and 1", len(sites), len(apps))
+	}
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations upgrade the schema one step at a time. PRAGMA user_version
+// records how many have run, so each executes exactly once per database.
+// Only ever append to this list.
+var migrations = []string{
+	// 1: the original tables. IF NOT EXISTS keeps databases created before
+	// versioning was introduced working.
+	`CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		start_time INTEGER NOT NULL,
+		duration_seconds INTEGER NOT NULL,
+		active INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS blocked_sites (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		domain TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS blocked_apps (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		process_name TEXT NOT NULL
+	);`,
+
+	// 2: profiles and strict sessions
+	`ALTER TABLE sessions ADD COLUMN profile TEXT NOT NULL DEFAULT '';
+	ALTER TABLE sessions ADD COLUMN strict INTEGER NOT NULL DEFAULT 0;
+	CREATE TABLE profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		duration_seconds INTEGER NOT NULL DEFAULT 0
+	);`,
+
+	// 3: how sessions ended, for the history page. Sessions that finished
+	// before this can't be told apart, so they keep an empty status.
+	`ALTER TABLE sessions ADD COLUMN ended_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE sessions ADD COLUMN status TEXT NOT NULL DEFAULT '';
+	ALTER TABLE sessions ADD COLUMN tamper_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE sessions ADD COLUMN note TEXT NOT NULL DEFAULT '';
+	CREATE INDEX sessions_start_time ON sessions (start_time);`,
+
+	// 4: the session event log. Sessions from before it get their start and,
+	// where it was recorded, their end, marked as backfilled.
+	`CREATE TABLE session_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		at INTEGER NOT NULL,
+		payload TEXT NOT NULL DEFAULT '{}'
+	);
+	CREATE INDEX session_events_session ON session_events (session_id, at);
+	INSERT INTO session_events (session_id, type, at, payload)
+		SELECT id, 'started', start_time, json_object(
+			'duration_seconds', duration_seconds,
+			'profile', profile,
+			'strict', json(CASE WHEN strict THEN 'true' ELSE 'false' END),
+			'backfilled', json('true'))
+		FROM sessions ORDER BY id;
+	INSERT INTO session_events (session_id, type, at, payload)
+		SELECT id, status, ended_at, '{"backfilled":true}'
+		FROM sessions WHERE status IN ('completed', 'aborted') ORDER BY id;`,
+
+	// 5: hash-chain the event log so hand edits show up. Events already
+	// logged are chained by chainEvents.
+	`ALTER TABLE session_events ADD COLUMN prev_hash TEXT NOT NULL DEFAULT '';
+	ALTER TABLE session_events ADD COLUMN hash TEXT NOT NULL DEFAULT '';`,
+
+	// 6: the break that follows a session
+	`ALTER TABLE sessions ADD COLUMN break_seconds INTEGER NOT NULL DEFAULT 0;`,
+}
+
+// migrationHooks run right after the migration with the same number, in
+// its transaction, for the changes SQL alone can't make.
+var migrationHooks = map[int]func(tx *sql.Tx) error{
+	5: chainEvents,
+}
+
+// SchemaVersion is the version a fully migrated database reports.
+var SchemaVersion = len(migrations)
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+// migrate runs every migration the database hasn't seen yet, each in its
+// own transaction together with the version bump.
+func migrate(db *sql.DB) error {
+	return migrateTo(db, len(migrations))
+}
+
+// migrateTo runs the migrations up to and including target. Restores use it
+// to rebuild the schema a backup was taken with before loading its rows.
+func migrateTo(db *sql.DB, target int) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build of LockIn supports (%d)", version, len(migrations))
+	}
+
+	for i := version; i < target; i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(migrations[i])
+		if hook := migrationHooks[i+1]; err == nil && hook != nil {
+			err = hook(tx)
+		}
+		if err == nil {
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("error applying migration %d: %w", i+1, err)
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/youssef28m/LockIn/internal/models"
 )
@@ -22,73 +24,93 @@ func Connect() *sql.DB {
 		log.Fatal("Error creating database directory:", err)
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := Open(dbPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return db
 }
 
+// Open opens the SQLite database at path. Callers that need the schema
+// should follow it with InitSchema.
+//
+// Transactions take the write lock when they begin, and wait up to five
+// seconds for it: events are written from more than one goroutine, and a
+// transaction that read the chain first and then found the lock taken
+// would fail at once instead of waiting its turn.
+func Open(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", path+"?_txlock=immediate&_busy_timeout=5000")
+}
+
 func CreateDB() {
 	db := Connect()
 	defer db.Close()
 
-	// Create sessions table
-	sessionsSQL := `CREATE TABLE IF NOT EXISTS sessions (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		start_time INTEGER NOT NULL,
-		duration_seconds INTEGER NOT NULL,
-		active INTEGER NOT NULL
-	);`
-
-	_, err := db.Exec(sessionsSQL)
+	err := InitSchema(db)
 	if err != nil {
-		log.Fatal("Error creating sessions table:", err)
+		log.Fatal(err)
 	}
+}
 
+// InitSchema brings the database up to the current schema version.
+func InitSchema(db *sql.DB) error {
+	return migrate(db)
+}
 
-	// Create blocked_sites table
-	blockedSitesSQL := `CREATE TABLE IF NOT EXISTS blocked_sites (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		domain TEXT NOT NULL
-	);`
-
-	_, err = db.Exec(blockedSitesSQL)
-	if err != nil {
-		log.Fatal("Error creating blocked_sites table:", err)
-	}
+//************************************************************//
+// Session CRUD Operations
+//************************************************************//
 
+const sessionColumns = "id, start_time, duration_seconds, active, profile, strict, ended_at, status, tamper_count, note, break_seconds"
 
-	// Create blocked_apps table
-	blockedAppsSQL := `CREATE TABLE IF NOT EXISTS blocked_apps (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		process_name TEXT NOT NULL
-	);`
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
-	_, err = db.Exec(blockedAppsSQL)
-	if err != nil {
-		log.Fatal("Error creating blocked_apps table:", err)
-	}
+func scanSession(row rowScanner) (models.Session, error) {
+	var session models.Session
+	var activeInt, strictInt int
+	err := row.Scan(&session.ID, &session.StartTime, &session.DurationSeconds, &activeInt, &session.Profile, &strictInt,
+		&session.EndedAt, &session.Status, &session.TamperCount, &session.Note, &session.BreakSeconds)
+	session.Active = activeInt != 0
+	session.Strict = strictInt != 0
+	return session, err
+}
 
+func CreateSession(db *sql.DB, startTime int64, durationSeconds int, active bool) (int64, error) {
+	return InsertSession(db, models.Session{
+		StartTime:       startTime,
+		DurationSeconds: int64(durationSeconds),
+		Active:          active,
+	})
 }
 
-//************************************************************//
-// Session CRUD Operations
-//************************************************************//
+// InsertSession stores every field of session except ID and returns the new ID.
+func InsertSession(db *sql.DB, session models.Session) (int64, error) {
+	return insertSession(db, session)
+}
 
-func CreateSession(db *sql.DB, startTime int64, durationSeconds int, active bool) (int64, error) {
-	activeInt := 0
-	if active {
-		activeInt = 1
-	}
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
 
+func insertSession(ex execer, session models.Session) (int64, error) {
 	// Execute the insert
-	result, err := db.Exec(
-		`INSERT INTO sessions (start_time, duration_seconds, active)
-		 VALUES (?, ?, ?)`,
-		startTime,
-		durationSeconds,
-		activeInt,
+	result, err := ex.Exec(
+		`INSERT INTO sessions (start_time, duration_seconds, active, profile, strict, ended_at, status, tamper_count, note, break_seconds)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		session.StartTime,
+		session.DurationSeconds,
+		session.Active,
+		session.Profile,
+		session.Strict,
+		session.EndedAt,
+		session.Status,
+		session.TamperCount,
+		session.Note,
+		session.BreakSeconds,
 	)
 	if err != nil {
 		return 0, err
@@ -104,7 +126,27 @@ func CreateSession(db *sql.DB, startTime int64, durationSeconds int, active bool
 }
 
 func GetAllSessions(db *sql.DB) ([]models.Session, error) {
-	rows, err := db.Query("SELECT id, start_time, duration_seconds, active FROM sessions")
+	rows, err := db.Query("SELECT " + sessionColumns + " FROM sessions")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetRecentSessions returns up to limit sessions, newest first.
+func GetRecentSessions(db *sql.DB, limit int) ([]models.Session, error) {
+	rows, err := db.Query("SELECT "+sessionColumns+" FROM sessions ORDER BY start_time DESC, id DESC LIMIT ?", limit)
 	if err != nil {
 		return nil, err
 	}
@@ -112,11 +154,61 @@ func GetAllSessions(db *sql.DB) ([]models.Session, error) {
 
 	var sessions []models.Session
 	for rows.Next() {
-		var session models.Session
-		var activeInt int
-		err := rows.Scan(&session.ID, &session.StartTime, &session.DurationSeconds, &activeInt)
-		session.Active = activeInt != 0
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// SessionFilter narrows a history query. Zero fields don't filter.
+type SessionFilter struct {
+	// From and To bound start_time as Unix seconds, To exclusive.
+	From    int64
+	To      int64
+	Profile string
+}
+
+func (f SessionFilter) where() (string, []any) {
+	var conds []string
+	var args []any
+	if f.From != 0 {
+		conds = append(conds, "start_time >= ?")
+		args = append(args, f.From)
+	}
+	if f.To != 0 {
+		conds = append(conds, "start_time < ?")
+		args = append(args, f.To)
+	}
+	if f.Profile != "" {
+		conds = append(conds, "profile = ?")
+		args = append(args, f.Profile)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// GetSessionsPage returns up to limit sessions matching filter, newest
+// first, skipping the first offset.
+func GetSessionsPage(db *sql.DB, filter SessionFilter, limit, offset int) ([]models.Session, error) {
+	where, args := filter.where()
+	args = append(args, limit, offset)
+
+	rows, err := db.Query("SELECT "+sessionColumns+" FROM sessions"+where+
+		" ORDER BY start_time DESC, id DESC LIMIT ? OFFSET ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -126,13 +218,31 @@ func GetAllSessions(db *sql.DB) ([]models.Session, error) {
 	return sessions, rows.Err()
 }
 
+// CountSessions returns how many sessions match filter.
+func CountSessions(db *sql.DB, filter SessionFilter) (int, error) {
+	where, args := filter.where()
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sessions"+where, args...).Scan(&count)
+	return count, err
+}
+
 func GetSessionByID(db *sql.DB, id int64) (*models.Session, error) {
 
-	row := db.QueryRow("SELECT id, start_time, duration_seconds, active FROM sessions WHERE id = ?", id)
-	var session models.Session
-	var activeInt int
-	err := row.Scan(&session.ID, &session.StartTime, &session.DurationSeconds, &activeInt)
-	session.Active = activeInt != 0
+	row := db.QueryRow("SELECT "+sessionColumns+" FROM sessions WHERE id = ?", id)
+	session, err := scanSession(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetActiveSession returns the most recently started session that is still
+// marked active. It returns sql.ErrNoRows when there is none.
+func GetActiveSession(db *sql.DB) (*models.Session, error) {
+	row := db.QueryRow(`SELECT ` + sessionColumns + ` FROM sessions
+		WHERE active = 1 ORDER BY start_time DESC LIMIT 1`)
+	session, err := scanSession(row)
 	if err != nil {
 		return nil, err
 	}
@@ -141,13 +251,21 @@ func GetSessionByID(db *sql.DB, id int64) (*models.Session, error) {
 }
 
 func UpdateSession(db *sql.DB, session models.Session) error {
+	return updateSession(db, session)
+}
+
+func updateSession(ex execer, session models.Session) error {
 	query := `
 	UPDATE sessions
-	SET start_time = ?, duration_seconds = ?, active = ?
+	SET start_time = ?, duration_seconds = ?, active = ?, profile = ?, strict = ?,
+		ended_at = ?, status = ?, note = ?, break_seconds = ?
 	WHERE id = ?
 	`
 
-	result, err := db.Exec(query, session.StartTime, session.DurationSeconds, session.Active, session.ID)
+	// tamper_count is left alone: only RecordTamper counts, so a session
+	// read before tampering was logged can't write the old count back.
+	result, err := ex.Exec(query, session.StartTime, session.DurationSeconds, session.Active, session.Profile, session.Strict,
+		session.EndedAt, session.Status, session.Note, session.BreakSeconds, session.ID)
 	if err != nil {
 		return err
 	}
@@ -165,10 +283,36 @@ func UpdateSession(db *sql.DB, session models.Session) error {
 
 }
 
+func SetSessionNote(db *sql.DB, id int64, note string) error {
+	result, err := db.Exec(`UPDATE sessions SET note = ? WHERE id = ?`, note, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no session found with id %d", id)
+	}
+
+	return nil
+}
+
+// DeleteSession removes a session from history. Its events are kept and a
+// deleted event is added, so the audit log still accounts for it.
 func DeleteSession(db *sql.DB, id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `DELETE FROM sessions WHERE id = ?`
 
-	result, err := db.Exec(query, id)
+	result, err := tx.Exec(query, id)
 	if err != nil {
 		return err
 	}
@@ -182,7 +326,14 @@ func DeleteSession(db *sql.DB, id int64) error {
 		return fmt.Errorf("no session found with id %d", id)
 	}
 
-	return nil
+	event := models.NewEvent(models.EventDeleted, time.Now(), nil)
+	event.SessionID = id
+	_, err = insertEvent(tx, event)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 //***********************************************************//
@@ -237,6 +388,17 @@ func GetBlockedSiteByID(db *sql.DB, id int64) (*models.BlockedSite, error) {
 	return &site, nil
 }
 
+func GetBlockedSiteByDomain(db *sql.DB, domain string) (*models.BlockedSite, error) {
+	row := db.QueryRow("SELECT id, domain FROM blocked_sites WHERE domain = ?", domain)
+	var site models.BlockedSite
+	err := row.Scan(&site.ID, &site.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	return &site, nil
+}
+
 func UpdateBlockedSite(db *sql.DB, site models.BlockedSite) error {
 	query := `UPDATE blocked_sites SET domain = ? WHERE id = ?`
 
@@ -329,6 +491,17 @@ func GetBlockedAppByID(db *sql.DB, id int64) (*models.BlockedApp, error) {
 	return &app, nil
 }
 
+func GetBlockedAppByName(db *sql.DB, processName string) (*models.BlockedApp, error) {
+	row := db.QueryRow("SELECT id, process_name FROM blocked_apps WHERE process_name = ?", processName)
+	var app models.BlockedApp
+	err := row.Scan(&app.ID, &app.ProcessName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &app, nil
+}
+
 func UpdateBlockedApp(db *sql.DB, app models.BlockedApp) error {
 	query := `UPDATE blocked_apps SET process_name = ? WHERE id = ?`
 
@@ -368,3 +541,50 @@ func DeleteBlockedApp(db *sql.DB, id int64) error {
 
 	return nil
 }
+
+//***********************************************************//
+// Profiles
+//***********************************************************//
+
+func GetAllProfiles(db *sql.DB) ([]models.Profile, error) {
+	rows, err := db.Query("SELECT id, name, duration_seconds FROM profiles ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []models.Profile
+	for rows.Next() {
+		var profile models.Profile
+		err := rows.Scan(&profile.ID, &profile.Name, &profile.DurationSeconds)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+func GetProfileByName(db *sql.DB, name string) (*models.Profile, error) {
+	row := db.QueryRow("SELECT id, name, duration_seconds FROM profiles WHERE name = ?", name)
+	var profile models.Profile
+	err := row.Scan(&profile.ID, &profile.Name, &profile.DurationSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// EnsureProfile creates the named profile if it doesn't exist yet. An
+// existing profile keeps its default duration.
+func EnsureProfile(db *sql.DB, name string, durationSeconds int64) error {
+	_, err := db.Exec(
+		`INSERT INTO profiles (name, duration_seconds) VALUES (?, ?)
+		 ON CONFLICT(name) DO NOTHING`,
+		name,
+		durationSeconds,
+	)
+	return err
+}
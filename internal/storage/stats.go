@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//***********************************************************//
+// Focus statistics
+//***********************************************************//
+
+// Period is the bucket size for FocusTotals.
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+)
+
+// localDay is the session's start date in the caller's time zone, using the
+// UTC offset in force when it started, taken from the offsets table that
+// zoneOffsets adds to the query.
+const localDay = `date(start_time + (SELECT off FROM offsets WHERE from_ts <= start_time ORDER BY from_ts DESC LIMIT 1), 'unixepoch')`
+
+// periodStart turns a local day into the first day of its bucket. Weeks
+// start on Monday.
+var periodStart = map[Period]string{
+	PeriodDay:   localDay,
+	PeriodWeek:  "date(" + localDay + ", 'weekday 0', '-6 days')",
+	PeriodMonth: "date(" + localDay + ", 'start of month')",
+}
+
+// focusedSeconds is how long a finished session actually focused. Sessions
+// from before ended_at was recorded count their planned length.
+const focusedSeconds = `CASE WHEN ended_at > 0 THEN ended_at - start_time ELSE duration_seconds END`
+
+// countsTowardStreak picks the sessions that make a day part of a streak:
+// finished and not aborted.
+const countsTowardStreak = `active = 0 AND status != 'aborted'`
+
+// zoneOffsets returns a WITH clause for the offsets table localDay reads:
+// loc's UTC offset from each from_ts on, across the starts of the sessions
+// matching where. SQLite only knows the process's own zone, so loc's DST
+// changes are worked out here and handed to the query.
+func zoneOffsets(db *sql.DB, loc *time.Location, where string, args ...any) (string, []any, error) {
+	var first, last int64
+	err := db.QueryRow(`SELECT COALESCE(MIN(start_time), 0), COALESCE(MAX(start_time), 0)
+		FROM sessions WHERE `+where, args...).Scan(&first, &last)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var values []string
+	var offsets []any
+	for at := first; ; {
+		t := time.Unix(at, 0).In(loc)
+		_, off := t.Zone()
+		values = append(values, "(?, ?)")
+		offsets = append(offsets, at, off)
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.Unix() > last {
+			break
+		}
+		at = end.Unix()
+	}
+	return "WITH offsets(from_ts, off) AS (VALUES " + strings.Join(values, ", ") + ")", offsets, nil
+}
+
+// FocusTotal is the focused time in one day, week or month. Start is the
+// first day of the bucket as YYYY-MM-DD.
+type FocusTotal struct {
+	Start    string `json:"start"`
+	Seconds  int64  `json:"seconds"`
+	Sessions int    `json:"sessions"`
+}
+
+// FocusTotals sums focused time per period for finished sessions that
+// started at or after since, oldest first. Sessions are put on the day they
+// started in loc, each with the UTC offset in force then, so a DST change
+// doesn't move them.
+func FocusTotals(db *sql.DB, period Period, loc *time.Location, since int64) ([]FocusTotal, error) {
+	bucket, ok := periodStart[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown period %q", period)
+	}
+
+	const finished = `active = 0 AND start_time >= ?`
+	with, args, err := zoneOffsets(db, loc, finished, since)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(with+`
+		SELECT `+bucket+` AS bucket, SUM(`+focusedSeconds+`), COUNT(*)
+		FROM sessions
+		WHERE `+finished+`
+		GROUP BY bucket
+		ORDER BY bucket`, append(args, since)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []FocusTotal
+	for rows.Next() {
+		var total FocusTotal
+		err := rows.Scan(&total.Start, &total.Seconds, &total.Sessions)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+// TotalFocus returns the focused time and number of finished sessions
+// across the whole history.
+func TotalFocus(db *sql.DB) (seconds int64, sessions int, err error) {
+	err = db.QueryRow(`SELECT COALESCE(SUM(`+focusedSeconds+`), 0), COUNT(*)
+		FROM sessions WHERE active = 0`).Scan(&seconds, &sessions)
+	return seconds, sessions, err
+}
+
+// CompletionCounts returns how many sessions ran to the end and how many
+// were stopped early. Sessions from before the outcome was recorded are in
+// neither.
+func CompletionCounts(db *sql.DB) (completed, aborted int, err error) {
+	err = db.QueryRow(`SELECT
+		COALESCE(SUM(status = 'completed'), 0),
+		COALESCE(SUM(status = 'aborted'), 0)
+		FROM sessions`).Scan(&completed, &aborted)
+	return completed, aborted, err
+}
+
+// Streak is a run of consecutive local days with at least one finished,
+// non-aborted session.
+type Streak struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Days  int    `json:"days"`
+}
+
+// FocusStreaks returns every streak, most recent first. Days are local to
+// loc, as in FocusTotals.
+func FocusStreaks(db *sql.DB, loc *time.Location) ([]Streak, error) {
+	with, args, err := zoneOffsets(db, loc, countsTowardStreak)
+	if err != nil {
+		return nil, err
+	}
+	// Consecutive days share the same day-minus-row-number, which groups
+	// each run together.
+	rows, err := db.Query(with+`, days AS (
+			SELECT DISTINCT `+localDay+` AS day FROM sessions WHERE `+countsTowardStreak+`
+		), runs AS (
+			SELECT day, julianday(day) - ROW_NUMBER() OVER (ORDER BY day) AS run FROM days
+		)
+		SELECT MIN(day), MAX(day), COUNT(*) FROM runs
+		GROUP BY run
+		ORDER BY MAX(day) DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var streaks []Streak
+	for rows.Next() {
+		var streak Streak
+		err := rows.Scan(&streak.Start, &streak.End, &streak.Days)
+		if err != nil {
+			return nil, err
+		}
+		streaks = append(streaks, streak)
+	}
+
+	return streaks, rows.Err()
+}
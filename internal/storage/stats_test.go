@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/youssef28m/LockIn/internal/models"
+)
+
+// setupStatsTestDB creates a migrated database and inserts sessions
+func setupStatsTestDB(t *testing.T, sessions []models.Session) *sql.DB {
+	db, err := Open(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	err = InitSchema(db)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	for _, session := range sessions {
+		_, err := InsertSession(db, session)
+		if err != nil {
+			t.Fatalf("Failed to insert session: %v", err)
+		}
+	}
+	return db
+}
+
+// at returns the Unix time for a UTC date and hour in March 2026
+func at(day, hour int) int64 {
+	return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC).Unix()
+}
+
+func completed(start, seconds int64) models.Session {
+	s := models.Session{StartTime: start, DurationSeconds: seconds}
+	s.Complete()
+	return s
+}
+
+func aborted(start, seconds, after int64) models.Session {
+	s := models.Session{StartTime: start, DurationSeconds: seconds}
+	s.Abort(time.Unix(start+after, 0))
+	return s
+}
+
+// statsSeed covers two weeks of March 2026. The 2nd is a Monday.
+var statsSeed = []models.Session{
+	completed(at(2, 9), 3000),
+	completed(at(2, 14), 1500),
+	aborted(at(3, 9), 3000, 600),
+	completed(at(4, 23), 1500),
+	completed(at(5, 9), 3000),
+	// The 6th is skipped
+	completed(at(7, 9), 5400),
+	aborted(at(9, 9), 3000, 1200),
+	completed(at(10, 9), 1500),
+	// Sessions from before outcomes were recorded count their full length
+	{StartTime: at(11, 9), DurationSeconds: 600},
+	// The running session isn't counted anywhere
+	{StartTime: at(12, 9), DurationSeconds: 3000, Active: true},
+}
+
+// Test FocusTotals - daily, weekly and monthly buckets
+func TestFocusTotals(t *testing.T) {
+	db := setupStatsTestDB(t, statsSeed)
+
+	tests := []struct {
+		name     string
+		period   Period
+		since    int64
+		expected []FocusTotal
+	}{
+		{"daily", PeriodDay, at(9, 0), []FocusTotal{
+			{"2026-03-09", 1200, 1},
+			{"2026-03-10", 1500, 1},
+			{"2026-03-11", 600, 1},
+		}},
+		{"weekly", PeriodWeek, 0, []FocusTotal{
+			{"2026-03-02", 3000 + 1500 + 600 + 1500 + 3000 + 5400, 6},
+			{"2026-03-09", 1200 + 1500 + 600, 3},
+		}},
+		{"monthly", PeriodMonth, 0, []FocusTotal{
+			{"2026-03-01", 18300, 9},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			totals, err := FocusTotals(db, test.period, time.UTC, test.since)
+			if err != nil {
+				t.Fatalf("FocusTotals failed: %v", err)
+			}
+			if !reflect.DeepEqual(totals, test.expected) {
+				t.Errorf("FocusTotals = %+v, expected %+v", totals, test.expected)
+			}
+		})
+	}
+}
+
+// Test FocusTotals - the UTC offset moves late sessions onto the next day
+func TestFocusTotalsOffset(t *testing.T) {
+	db := setupStatsTestDB(t, statsSeed)
+
+	totals, err := FocusTotals(db, PeriodDay, time.FixedZone("UTC+2", 2*3600), at(4, 0))
+	if err != nil {
+		t.Fatalf("FocusTotals failed: %v", err)
+	}
+
+	// 23:00 UTC on the 4th is 01:00 on the 5th at UTC+2
+	if len(totals) == 0 || totals[0] != (FocusTotal{"2026-03-05", 1500 + 3000, 2}) {
+		t.Errorf("Expected the 4th's late session on the 5th, got %+v", totals)
+	}
+}
+
+// Test FocusTotals and FocusStreaks across a DST change - each session
+// keeps the day it started on locally, whichever offset is in force now
+func TestStatsAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("Failed to load Europe/Berlin: %v", err)
+	}
+	// Clocks went forward at 02:00 on Sunday 29 March 2026.
+	late := func(day int) int64 {
+		return time.Date(2026, 3, day, 23, 30, 0, 0, berlin).Unix()
+	}
+	db := setupStatsTestDB(t, []models.Session{
+		completed(late(28), 1500),
+		completed(late(29), 1500),
+		completed(late(30), 1500),
+	})
+
+	totals, err := FocusTotals(db, PeriodDay, berlin, 0)
+	if err != nil {
+		t.Fatalf("FocusTotals failed: %v", err)
+	}
+	expected := []FocusTotal{
+		{"2026-03-28", 1500, 1},
+		{"2026-03-29", 1500, 1},
+		{"2026-03-30", 1500, 1},
+	}
+	if !reflect.DeepEqual(totals, expected) {
+		t.Errorf("FocusTotals = %+v, expected %+v", totals, expected)
+	}
+
+	streaks, err := FocusStreaks(db, berlin)
+	if err != nil {
+		t.Fatalf("FocusStreaks failed: %v", err)
+	}
+	if len(streaks) != 1 || streaks[0] != (Streak{"2026-03-28", "2026-03-30", 3}) {
+		t.Errorf("FocusStreaks = %+v, expected one 3-day streak", streaks)
+	}
+}
+
+// Test TotalFocus and CompletionCounts
+func TestTotalsAndCompletion(t *testing.T) {
+	db := setupStatsTestDB(t, statsSeed)
+
+	seconds, sessions, err := TotalFocus(db)
+	if err != nil {
+		t.Fatalf("TotalFocus failed: %v", err)
+	}
+	if seconds != 18300 || sessions != 9 {
+		t.Errorf("TotalFocus = %d seconds over %d sessions, expected 18300 over 9", seconds, sessions)
+	}
+
+	completedCount, abortedCount, err := CompletionCounts(db)
+	if err != nil {
+		t.Fatalf("CompletionCounts failed: %v", err)
+	}
+	if completedCount != 6 || abortedCount != 2 {
+		t.Errorf("CompletionCounts = %d completed, %d aborted, expected 6 and 2", completedCount, abortedCount)
+	}
+}
+
+// Test FocusStreaks - aborted-only days and gaps break streaks
+func TestFocusStreaks(t *testing.T) {
+	db := setupStatsTestDB(t, statsSeed)
+
+	streaks, err := FocusStreaks(db, time.UTC)
+	if err != nil {
+		t.Fatalf("FocusStreaks failed: %v", err)
+	}
+
+	expected := []Streak{
+		{"2026-03-10", "2026-03-11", 2},
+		{"2026-03-07", "2026-03-07", 1},
+		{"2026-03-04", "2026-03-05", 2},
+		{"2026-03-02", "2026-03-02", 1},
+	}
+	if !reflect.DeepEqual(streaks, expected) {
+		t.Errorf("FocusStreaks = %+v, expected %+v", streaks, expected)
+	}
+}
+
+// Test stats on an empty database
+func TestStatsEmpty(t *testing.T) {
+	db := setupStatsTestDB(t, nil)
+
+	totals, err := FocusTotals(db, PeriodDay, time.UTC, 0)
+	if err != nil || len(totals) != 0 {
+		t.Errorf("Expected no totals, got %+v (%v)", totals, err)
+	}
+	seconds, sessions, err := TotalFocus(db)
+	if err != nil || seconds != 0 || sessions != 0 {
+		t.Errorf("Expected zero total, got %d/%d (%v)", seconds, sessions, err)
+	}
+	streaks, err := FocusStreaks(db, time.UTC)
+	if err != nil || len(streaks) != 0 {
+		t.Errorf("Expected no streaks, got %+v (%v)", streaks, err)
+	}
+}
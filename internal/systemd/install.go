@@ -0,0 +1,115 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Scope picks which half of LockIn a unit belongs to.
+type Scope int
+
+const (
+	SystemScope Scope = iota
+	UserScope
+)
+
+// Runner executes systemctl. Tests swap it for a recorder.
+type Runner func(name string, args ...string) error
+
+func ExecRunner(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Installer writes units into place and drives systemctl.
+type Installer struct {
+	SystemDir string
+	UserDir   string
+	Run       Runner
+}
+
+func NewInstaller() (*Installer, error) {
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Installer{
+		SystemDir: "/etc/systemd/system",
+		UserDir:   filepath.Join(config, "systemd", "user"),
+		Run:       ExecRunner,
+	}, nil
+}
+
+// unit returns where the unit for scope lives, its name, and the flags
+// systemctl needs to manage it.
+func (i *Installer) unit(scope Scope) (path, name string, systemctl []string) {
+	if scope == SystemScope {
+		return filepath.Join(i.SystemDir, HelperUnitName), HelperUnitName, nil
+	}
+	return filepath.Join(i.UserDir, DaemonUnitName), DaemonUnitName, []string{"--user"}
+}
+
+// Render returns the unit text for scope.
+func Render(scope Scope, opts Options) string {
+	if scope == SystemScope {
+		return HelperUnit(opts)
+	}
+	return DaemonUnit(opts)
+}
+
+// Install writes the unit for scope, reloads systemd and enables it.
+func (i *Installer) Install(scope Scope, opts Options) error {
+	path, name, systemctl := i.unit(scope)
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, []byte(Render(scope, opts)), 0644)
+	if err != nil {
+		return err
+	}
+
+	err = i.Run("systemctl", append(systemctl, "daemon-reload")...)
+	if err != nil {
+		return fmt.Errorf("systemctl daemon-reload: %w", err)
+	}
+	err = i.Run("systemctl", append(systemctl, "enable", "--now", name)...)
+	if err != nil {
+		return fmt.Errorf("systemctl enable %s: %w", name, err)
+	}
+	return nil
+}
+
+// Uninstall disables the unit for scope and removes its file. A unit that
+// was never installed is not an error.
+func (i *Installer) Uninstall(scope Scope) error {
+	path, name, systemctl := i.unit(scope)
+
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	err = i.Run("systemctl", append(systemctl, "disable", "--now", name)...)
+	if err != nil {
+		return fmt.Errorf("systemctl disable %s: %w", name, err)
+	}
+	err = os.Remove(path)
+	if err != nil {
+		return err
+	}
+	return i.Run("systemctl", append(systemctl, "daemon-reload")...)
+}
+
+// Installed reports whether the unit file for scope exists.
+func (i *Installer) Installed(scope Scope) bool {
+	path, _, _ := i.unit(scope)
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,118 @@
+// Package systemd renders and installs the units that keep LockIn running
+// across reboots: a hardened system unit for the root helper and a user
+// unit for the scheduler daemon.
+package systemd
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	HelperUnitName = "lockin-helper.service"
+	DaemonUnitName = "lockind.service"
+)
+
+// Options fills in the unit templates.
+type Options struct {
+	HelperPath   string
+	DaemonPath   string
+	HelperSocket string
+	AllowUIDs    []int
+}
+
+// The helper only ever needs to rewrite /etc/hosts and listen on its socket
+// under /run/lockin, so everything else is locked down. Only the file itself
+// is writable, not /etc, so the helper can't swap in a new file and
+// rewrites it in place instead.
+var helperTemplate = template.Must(template.New("helper").Parse(`[Unit]
+Description=LockIn privileged hosts-file helper
+Documentation=https://github.com/youssef28m/LockIn
+
+[Service]
+Type=simple
+ExecStart={{.HelperPath}} -socket {{.HelperSocket}}{{with .AllowUIDList}} -allow-uid {{.}}{{end}}
+Restart=on-failure
+RestartSec=2
+RuntimeDirectory=lockin
+RuntimeDirectoryMode=0755
+UMask=0022
+
+CapabilityBoundingSet=
+NoNewPrivileges=yes
+# /etc stays read-only; the helper rewrites /etc/hosts in place.
+ProtectSystem=strict
+ReadWritePaths=/etc/hosts
+ProtectHome=yes
+PrivateTmp=yes
+PrivateDevices=yes
+PrivateNetwork=yes
+ProtectKernelTunables=yes
+ProtectKernelModules=yes
+ProtectKernelLogs=yes
+ProtectControlGroups=yes
+ProtectClock=yes
+ProtectHostname=yes
+RestrictAddressFamilies=AF_UNIX
+RestrictNamespaces=yes
+RestrictRealtime=yes
+RestrictSUIDSGID=yes
+LockPersonality=yes
+MemoryDenyWriteExecute=yes
+SystemCallArchitectures=native
+SystemCallFilter=@system-service
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// User units can't use most of the sandboxing options without user
+// namespaces, so the daemon gets the ones that work everywhere.
+var daemonTemplate = template.Must(template.New("daemon").Parse(`[Unit]
+Description=LockIn focus session daemon
+Documentation=https://github.com/youssef28m/LockIn
+
+[Service]
+Type=simple
+ExecStart={{.DaemonPath}} -helper {{.HelperSocket}}
+Restart=on-failure
+RestartSec=2
+
+NoNewPrivileges=yes
+LockPersonality=yes
+RestrictRealtime=yes
+RestrictSUIDSGID=yes
+MemoryDenyWriteExecute=yes
+SystemCallArchitectures=native
+
+[Install]
+WantedBy=default.target
+`))
+
+type templateData struct {
+	Options
+	AllowUIDList string
+}
+
+func render(tmpl *template.Template, opts Options) string {
+	uids := make([]string, 0, len(opts.AllowUIDs))
+	for _, uid := range opts.AllowUIDs {
+		uids = append(uids, strconv.Itoa(uid))
+	}
+
+	var b bytes.Buffer
+	// The templates are fixed and the data is plain strings; Execute can't fail.
+	tmpl.Execute(&b, templateData{
+		Options:      opts,
+		AllowUIDList: strings.Join(uids, ","),
+	})
+	return b.String()
+}
+
+// HelperUnit renders the system unit for lockin-helper.
+func HelperUnit(opts Options) string { return render(helperTemplate, opts) }
+
+// DaemonUnit renders the user unit for lockind.
+func DaemonUnit(opts Options) string { return render(daemonTemplate, opts) }
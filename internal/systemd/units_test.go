@@ -0,0 +1,170 @@
+package systemd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/youssef28m/LockIn/internal/blocker"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var testOptions = Options{
+	HelperPath:   "/usr/local/bin/lockin-helper",
+	DaemonPath:   "/usr/local/bin/lockind",
+	HelperSocket: "/run/lockin/helper.sock",
+	AllowUIDs:    []int{1000},
+}
+
+// checkGolden compares got with testdata/name, or rewrites it under -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		err := os.WriteFile(path, []byte(got), 0644)
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match golden file.\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestUnitGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		golden string
+		render func(Options) string
+		opts   Options
+	}{
+		{"helper unit", "lockin-helper.service.golden", HelperUnit, testOptions},
+		{"daemon unit", "lockind.service.golden", DaemonUnit, testOptions},
+		{
+			"helper unit with several users", "lockin-helper-multi.service.golden", HelperUnit,
+			Options{HelperPath: "/opt/lockin/lockin-helper", HelperSocket: "/run/lockin/helper.sock", AllowUIDs: []int{1000, 1001}},
+		},
+		{
+			"helper unit root only", "lockin-helper-root.service.golden", HelperUnit,
+			Options{HelperPath: "/usr/bin/lockin-helper", HelperSocket: "/run/lockin/helper.sock"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkGolden(t, test.golden, test.render(test.opts))
+		})
+	}
+}
+
+// unitSettings returns the values of key in unit, in order
+func unitSettings(unit, key string) []string {
+	var values []string
+	for _, line := range strings.Split(unit, "\n") {
+		if value, ok := strings.CutPrefix(line, key+"="); ok {
+			values = append(values, strings.Fields(value)...)
+		}
+	}
+	return values
+}
+
+// Test helper sandbox - the unit leaves only the hosts file writable, not
+// its directory, and the hosts writer still applies and clears a block in
+// a directory it can't write to
+func TestHelperUnitSandbox(t *testing.T) {
+	unit := HelperUnit(testOptions)
+	if got := unitSettings(unit, "ProtectSystem"); !reflect.DeepEqual(got, []string{"strict"}) {
+		t.Errorf("ProtectSystem = %q, want strict", got)
+	}
+	writable := unitSettings(unit, "ReadWritePaths")
+	if !reflect.DeepEqual(writable, []string{"/etc/hosts"}) {
+		t.Fatalf("ReadWritePaths = %q, want just /etc/hosts", writable)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to a read-only directory")
+	}
+	etc := t.TempDir()
+	hosts := filepath.Join(etc, filepath.Base(writable[0]))
+	os.WriteFile(hosts, []byte("127.0.0.1 localhost\n"), 0644)
+	os.Chmod(etc, 0555)
+	defer os.Chmod(etc, 0755)
+
+	enforcer := blocker.HostsEnforcer{Path: hosts}
+	err := enforcer.Apply([]string{"a.example.com"})
+	if err != nil {
+		t.Fatalf("Apply() under the sandbox failed: %v", err)
+	}
+	err = enforcer.Clear()
+	if err != nil {
+		t.Fatalf("Clear() under the sandbox failed: %v", err)
+	}
+}
+
+// recorder stands in for systemctl
+type recorder struct{ calls []string }
+
+func (r *recorder) run(name string, args ...string) error {
+	r.calls = append(r.calls, name+" "+strings.Join(args, " "))
+	return nil
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	dir := t.TempDir()
+	rec := &recorder{}
+	installer := &Installer{
+		SystemDir: filepath.Join(dir, "system"),
+		UserDir:   filepath.Join(dir, "user"),
+		Run:       rec.run,
+	}
+
+	err := installer.Install(UserScope, testOptions)
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "user", DaemonUnitName))
+	if err != nil {
+		t.Fatalf("unit file not written: %v", err)
+	}
+	if string(content) != DaemonUnit(testOptions) {
+		t.Error("installed unit differs from rendered unit")
+	}
+	if !installer.Installed(UserScope) || installer.Installed(SystemScope) {
+		t.Error("only the user unit should be installed")
+	}
+
+	err = installer.Uninstall(UserScope)
+	if err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if installer.Installed(UserScope) {
+		t.Error("user unit should be removed")
+	}
+
+	// Uninstalling something that isn't there is a no-op
+	err = installer.Uninstall(SystemScope)
+	if err != nil {
+		t.Fatalf("Uninstall of missing unit failed: %v", err)
+	}
+
+	expected := []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable --now lockind.service",
+		"systemctl --user disable --now lockind.service",
+		"systemctl --user daemon-reload",
+	}
+	if !reflect.DeepEqual(rec.calls, expected) {
+		t.Errorf("systemctl calls = %q, expected %q", rec.calls, expected)
+	}
+}
@@ -0,0 +1,45 @@
+// Package nav holds the page identifiers and navigation messages shared by
+// the root model and the pages, so pages can ask to move without importing
+// the root.
+package nav
+
+import tea "github.com/charmbracelet/bubbletea"
+
+type Page int
+
+const (
+	Home Page = iota
+	SetTimer
+	Timer
+	BlockSites
+	BlockApps
+	History
+	Stats
+)
+
+// PushMsg asks the root to open Page on top of the current one.
+type PushMsg struct{ Page Page }
+
+// ReplaceMsg asks the root to swap the current page for Page, so going back
+// skips it. Used when a page has done its job, like starting a session.
+type ReplaceMsg struct{ Page Page }
+
+// PopMsg asks the root to go back to the previous page.
+type PopMsg struct{}
+
+// RevealedMsg tells a page it is back on top after the page above it
+// closed. Messages only reach the top page, so one that ticks has to start
+// ticking again.
+type RevealedMsg struct{}
+
+func Push(page Page) tea.Cmd {
+	return func() tea.Msg { return PushMsg{Page: page} }
+}
+
+func Replace(page Page) tea.Cmd {
+	return func() tea.Msg { return ReplaceMsg{Page: page} }
+}
+
+func Pop() tea.Cmd {
+	return func() tea.Msg { return PopMsg{} }
+}
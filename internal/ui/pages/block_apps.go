@@ -0,0 +1,506 @@
+package pages
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/validator"
+)
+
+// processRefresh is how often the page re-reads the process list to keep
+// the running column current.
+const processRefresh = 2 * time.Second
+
+type blockAppsKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Add    key.Binding
+	Pick   key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Back   key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+}
+
+func (k blockAppsKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Add, k.Pick, k.Edit, k.Delete, k.Back, k.Help}
+}
+
+func (k blockAppsKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Add, k.Pick, k.Edit, k.Delete},
+		{k.Back, k.Help, k.Quit},
+	}
+}
+
+func newBlockAppsKeys(k Keymap) blockAppsKeys {
+	return blockAppsKeys{
+		Up:     k.Binding(KeyUp, "move up"),
+		Down:   k.Binding(KeyDown, "move down"),
+		Add:    k.Binding(KeyAdd, "add"),
+		Pick:   k.Binding(KeyPick, "pick running app"),
+		Edit:   k.Binding(KeyEdit, "edit"),
+		Delete: k.Binding(KeyDelete, "delete"),
+		Back:   k.Binding(KeyBack, "back"),
+		Help:   k.Binding(KeyHelp, "toggle help"),
+		Quit:   k.Binding(KeyQuit, "quit"),
+	}
+}
+
+// appsLoadedMsg carries a fresh copy of the app block list and whether a
+// strict session currently locks it.
+type appsLoadedMsg struct {
+	apps   []models.BlockedApp
+	locked bool
+	err    error
+}
+
+// appSavedMsg reports the result of an add, edit or delete.
+type appSavedMsg struct {
+	status string
+	err    error
+}
+
+// processesMsg carries a snapshot of the running processes.
+type processesMsg struct {
+	processes []blocker.Process
+	err       error
+}
+
+// processTickMsg asks the page to take another process snapshot.
+type processTickMsg struct{}
+
+type BlockAppsModel struct {
+	env     *Env
+	keys    blockAppsKeys
+	form    formKeys
+	confirm confirmKeys
+	pick    fieldKeys
+
+	apps   []models.BlockedApp
+	locked bool
+
+	processes  []blocker.Process
+	processErr string
+
+	mode   listMode
+	cursor int
+	input  textinput.Model
+
+	// picker filters the running process names while picking.
+	picker     textinput.Model
+	pickCursor int
+
+	// editing is the entry being edited or deleted.
+	editing models.BlockedApp
+	status  string
+	err     string
+	height  int
+}
+
+func NewBlockAppsModel(env *Env) BlockAppsModel {
+	input := newInput(env)
+	input.Placeholder = "firefox"
+	input.CharLimit = 255
+	input.Width = 32
+
+	picker := newInput(env)
+	picker.Prompt = "/ "
+	picker.Placeholder = "type to narrow the list"
+	picker.Width = 24
+
+	return BlockAppsModel{
+		env:     env,
+		keys:    newBlockAppsKeys(env.Keys),
+		form:    newFormKeys(env.Keys, "save"),
+		confirm: newConfirmKeys(env.Keys),
+		pick:    newFieldKeys(env.Keys, "next app", "previous app", "block"),
+		input:   input,
+		picker:  picker,
+	}
+}
+
+func (m BlockAppsModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.scan())
+}
+
+func (m BlockAppsModel) Keys() help.KeyMap {
+	switch m.mode {
+	case modeAdd, modeEdit:
+		return m.form
+	case modeConfirmDelete:
+		return m.confirm
+	case modePick:
+		return m.pick
+	}
+	return m.keys
+}
+
+// InputFocused tells the root to leave shortcut keys alone while typing.
+func (m BlockAppsModel) InputFocused() bool {
+	return m.mode != modeBrowse
+}
+
+func (m BlockAppsModel) load() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		apps, err := svc.ListBlockedApps()
+		if err != nil {
+			return appsLoadedMsg{err: err}
+		}
+		session, err := svc.ActiveSession()
+		if err != nil {
+			return appsLoadedMsg{err: err}
+		}
+		return appsLoadedMsg{apps: apps, locked: session != nil && session.Strict}
+	}
+}
+
+func (m BlockAppsModel) scan() tea.Cmd {
+	list := m.env.Processes
+	if list == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		processes, err := list()
+		return processesMsg{processes: processes, err: err}
+	}
+}
+
+// pickable returns the running process names matching the picker filter,
+// leaving out apps that are already blocked.
+func (m BlockAppsModel) pickable() []string {
+	query := strings.ToLower(strings.TrimSpace(m.picker.Value()))
+
+	var names []string
+	for _, name := range blocker.ProcessNames(m.processes) {
+		if m.blocked(name) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(name), query) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (m BlockAppsModel) blocked(name string) bool {
+	for _, app := range m.apps {
+		if app.ProcessName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m BlockAppsModel) selected() (models.BlockedApp, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.apps) {
+		return models.BlockedApp{}, false
+	}
+	return m.apps[m.cursor], true
+}
+
+func (m BlockAppsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case appsLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.apps = msg.apps
+		m.locked = msg.locked
+		m.cursor = clamp(m.cursor, 0, len(m.apps)-1)
+		return m, nil
+
+	case appSavedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, m.load()
+		}
+		m.status = msg.status
+		return m, m.load()
+
+	case processesMsg:
+		m.processErr = ""
+		if msg.err != nil {
+			m.processErr = msg.err.Error()
+		} else {
+			m.processes = msg.processes
+		}
+		m.pickCursor = clamp(m.pickCursor, 0, len(m.pickable())-1)
+		return m, m.env.Tick(processRefresh, func(time.Time) tea.Msg { return processTickMsg{} })
+
+	case processTickMsg:
+		return m, m.scan()
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case modeAdd, modeEdit:
+			return m.updateInput(msg)
+		case modeConfirmDelete:
+			return m.updateConfirm(msg)
+		case modePick:
+			return m.updatePick(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+
+	return m, nil
+}
+
+func (m BlockAppsModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.err = ""
+	m.status = ""
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.apps)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, m.keys.Add):
+		m.mode = modeAdd
+		m.input.SetValue("")
+		return m, m.input.Focus()
+	case key.Matches(msg, m.keys.Pick):
+		if m.processErr != "" {
+			m.err = "Can't list running processes: " + m.processErr
+			return m, nil
+		}
+		m.mode = modePick
+		m.picker.SetValue("")
+		m.pickCursor = 0
+		return m, m.picker.Focus()
+	case key.Matches(msg, m.keys.Edit):
+		app, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if m.locked {
+			m.err = "A strict session is running; entries can't be changed until it ends."
+			return m, nil
+		}
+		m.mode = modeEdit
+		m.editing = app
+		m.input.SetValue(app.ProcessName)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case key.Matches(msg, m.keys.Delete):
+		app, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if m.locked {
+			m.err = "A strict session is running; entries can't be removed until it ends."
+			return m, nil
+		}
+		m.mode = modeConfirmDelete
+		m.editing = app
+	}
+	return m, nil
+}
+
+func (m BlockAppsModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.form.Cancel):
+		m.mode = modeBrowse
+		m.err = ""
+		m.input.Blur()
+		return m, nil
+
+	case key.Matches(msg, m.form.Submit):
+		name := strings.TrimSpace(m.input.Value())
+		if !validator.IsValidProcessName(name) {
+			m.err = fmt.Sprintf("%q isn't a valid process name (use the name shown by ps, or press %s to pick)", name, keyName(m.keys.Pick))
+			return m, nil
+		}
+
+		svc := m.env.Service
+		mode, editing := m.mode, m.editing
+		m.mode = modeBrowse
+		m.err = ""
+		m.input.Blur()
+		return m, func() tea.Msg {
+			if mode == modeEdit {
+				editing.ProcessName = name
+				return appSavedMsg{status: "Updated " + name, err: svc.UpdateBlockedApp(editing)}
+			}
+			return appSavedMsg{status: "Blocked " + name, err: svc.AddBlockedApp(name)}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.err = ""
+	if value := strings.TrimSpace(m.input.Value()); value != "" && !validator.IsValidProcessName(value) {
+		m.err = "not a valid process name"
+	}
+	return m, cmd
+}
+
+func (m BlockAppsModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.confirm.Confirm):
+		svc := m.env.Service
+		name := m.editing.ProcessName
+		m.mode = modeBrowse
+		return m, func() tea.Msg {
+			return appSavedMsg{status: "Unblocked " + name, err: svc.RemoveBlockedApp(name)}
+		}
+	case key.Matches(msg, m.confirm.Deny):
+		m.mode = modeBrowse
+	}
+	return m, nil
+}
+
+func (m BlockAppsModel) updatePick(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.pick.Cancel):
+		m.picker.Blur()
+		m.mode = modeBrowse
+		return m, nil
+	case key.Matches(msg, m.pick.Prev):
+		if m.pickCursor > 0 {
+			m.pickCursor--
+		}
+		return m, nil
+	case key.Matches(msg, m.pick.Next):
+		if m.pickCursor < len(m.pickable())-1 {
+			m.pickCursor++
+		}
+		return m, nil
+	case key.Matches(msg, m.pick.Submit):
+		names := m.pickable()
+		if m.pickCursor >= len(names) {
+			return m, nil
+		}
+		name := names[m.pickCursor]
+		svc := m.env.Service
+		m.picker.Blur()
+		m.mode = modeBrowse
+		return m, func() tea.Msg {
+			return appSavedMsg{status: "Blocked " + name, err: svc.AddBlockedApp(name)}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	m.pickCursor = 0
+	return m, cmd
+}
+
+func (m BlockAppsModel) View() string {
+	th := m.env.Theme
+	var b strings.Builder
+
+	b.WriteString(th.Header("🔒", "Block Apps"))
+
+	if m.locked {
+		b.WriteString(th.Notice(th.Icon("🔐 ", "")+"Strict session running: you can add apps, but not edit or remove them.") + "\n\n")
+	}
+
+	if m.mode == modePick {
+		m.viewPicker(&b)
+	} else {
+		m.viewList(&b)
+	}
+
+	b.WriteString("\n")
+	switch m.mode {
+	case modeAdd:
+		b.WriteString("Add app: " + m.input.View() + "\n")
+	case modeEdit:
+		b.WriteString("Edit app: " + m.input.View() + "\n")
+	case modeConfirmDelete:
+		b.WriteString(th.Warning.Render(fmt.Sprintf("Remove %s from the block list? (%s/%s)", m.editing.ProcessName, keyName(m.confirm.Confirm), keyName(m.confirm.Deny))) + "\n")
+	}
+
+	if m.err != "" {
+		b.WriteString(th.Err(m.err) + "\n")
+	} else if m.status != "" {
+		b.WriteString(th.OK(m.status) + "\n")
+	}
+
+	return b.String()
+}
+
+func (m BlockAppsModel) viewList(b *strings.Builder) {
+	th := m.env.Theme
+	if len(m.apps) == 0 {
+		b.WriteString(th.Muted.Render(fmt.Sprintf("   No blocked apps yet. Press %s to add one, or %s to pick a running app.", keyName(m.keys.Add), keyName(m.keys.Pick))) + "\n")
+		return
+	}
+
+	width := 0
+	for _, app := range m.apps {
+		width = max(width, len(app.ProcessName))
+	}
+
+	start, end := listWindow(m.cursor, len(m.apps), m.listHeight())
+	for i := start; i < end; i++ {
+		name := fmt.Sprintf("%-*s", width, m.apps[i].ProcessName)
+		b.WriteString(th.Item(m.cursor == i, name) + "  " + m.runningLabel(m.apps[i].ProcessName) + "\n")
+	}
+	if end-start < len(m.apps) {
+		b.WriteString("\n" + th.Muted.Render(fmt.Sprintf("   %d of %d shown", end-start, len(m.apps))) + "\n")
+	}
+}
+
+func (m BlockAppsModel) runningLabel(name string) string {
+	th := m.env.Theme
+	switch {
+	case m.processErr != "" || m.env.Processes == nil:
+		return ""
+	case blocker.IsRunning(m.processes, name):
+		return th.Success.Render(th.Icon("●", "*") + " running")
+	default:
+		return th.Muted.Render(th.Icon("○", "-") + " not running")
+	}
+}
+
+func (m BlockAppsModel) viewPicker(b *strings.Builder) {
+	th := m.env.Theme
+	b.WriteString("Pick a running app " + th.Muted.Render(fmt.Sprintf("(%s to block, %s to cancel)", keyName(m.pick.Submit), keyName(m.pick.Cancel))) + "\n\n")
+	b.WriteString(m.picker.View() + "\n\n")
+
+	names := m.pickable()
+	if len(names) == 0 {
+		b.WriteString(th.Muted.Render("   No running apps match.") + "\n")
+		return
+	}
+
+	start, end := listWindow(m.pickCursor, len(names), m.listHeight()-2)
+	for i := start; i < end; i++ {
+		b.WriteString(th.Item(m.pickCursor == i, names[i]) + "\n")
+	}
+	if end-start < len(names) {
+		b.WriteString("\n" + th.Muted.Render(fmt.Sprintf("   %d of %d shown", end-start, len(names))) + "\n")
+	}
+}
+
+// listHeight is how many rows of the list fit around the header, input
+// line and help footer.
+func (m BlockAppsModel) listHeight() int {
+	if m.height == 0 {
+		return 15
+	}
+	return max(m.height-14, 3)
+}
@@ -0,0 +1,30 @@
+package pages
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+func seedApps(store *uitest.Store) {
+	store.AddApp("firefox")
+	store.AddApp("discord")
+}
+
+// Test block apps view - the running column, validation and the picker
+func TestBlockAppsView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model { return NewBlockAppsModel(env) }, []viewCase{
+		{name: "list", golden: "block_apps_list.golden", setup: seedApps},
+		{name: "invalid", golden: "block_apps_invalid.golden", setup: seedApps,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) {
+				d.Press("a").Type("bad/name").Press("enter")
+			}},
+		{name: "picker", golden: "block_apps_picker.golden", setup: seedApps,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("p", "down") }},
+		{name: "picker filtered", golden: "block_apps_picker_filtered.golden", setup: seedApps,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("p").Type("ste") }},
+		{name: "picked", golden: "block_apps_picked.golden", setup: seedApps,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("p", "enter") }},
+	})
+}
@@ -2,31 +2,417 @@ package pages
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/validator"
 )
 
-type BlockSitesModel struct{ cursor int }
+type blockSitesKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Add    key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Filter key.Binding
+	Back   key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+}
+
+func (k blockSitesKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Add, k.Edit, k.Delete, k.Filter, k.Back, k.Help}
+}
+
+func (k blockSitesKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Add, k.Edit, k.Delete, k.Filter},
+		{k.Back, k.Help, k.Quit},
+	}
+}
+
+func newBlockSitesKeys(k Keymap) blockSitesKeys {
+	return blockSitesKeys{
+		Up:     k.Binding(KeyUp, "move up"),
+		Down:   k.Binding(KeyDown, "move down"),
+		Add:    k.Binding(KeyAdd, "add"),
+		Edit:   k.Binding(KeyEdit, "edit"),
+		Delete: k.Binding(KeyDelete, "delete"),
+		Filter: k.Binding(KeyFilter, "filter"),
+		Back:   k.Binding(KeyBack, "back"),
+		Help:   k.Binding(KeyHelp, "toggle help"),
+		Quit:   k.Binding(KeyQuit, "quit"),
+	}
+}
 
-func NewBlockSitesModel() BlockSitesModel { return BlockSitesModel{} }
+// listMode is what a list page is doing with the keyboard right now.
+type listMode int
+
+const (
+	modeBrowse listMode = iota
+	modeAdd
+	modeEdit
+	modeConfirmDelete
+	modeFilter
+	modePick
+)
 
-func (m BlockSitesModel) Init() tea.Cmd { return nil }
+// sitesLoadedMsg carries a fresh copy of the block list and whether a strict
+// session currently locks it.
+type sitesLoadedMsg struct {
+	sites  []models.BlockedSite
+	locked bool
+	err    error
+}
+
+// siteSavedMsg reports the result of an add, edit or delete.
+type siteSavedMsg struct {
+	status string
+	err    error
+}
 
-func (m BlockSitesModel) Update(msg tea.Msg) (BlockSitesModel, tea.Cmd) {
+type BlockSitesModel struct {
+	env     *Env
+	keys    blockSitesKeys
+	form    formKeys
+	confirm confirmKeys
+
+	sites  []models.BlockedSite
+	locked bool
+
+	mode   listMode
+	cursor int
+	input  textinput.Model
+	filter textinput.Model
+
+	// editing is the entry being edited or deleted.
+	editing models.BlockedSite
+	status  string
+	err     string
+	height  int
+}
+
+func NewBlockSitesModel(env *Env) BlockSitesModel {
+	input := newInput(env)
+	input.Placeholder = "example.com"
+	input.CharLimit = 253
+	input.Width = 40
+
+	filter := newInput(env)
+	filter.Prompt = "/ "
+	filter.Placeholder = "filter"
+	filter.Width = 24
+
+	return BlockSitesModel{
+		env:     env,
+		keys:    newBlockSitesKeys(env.Keys),
+		form:    newFormKeys(env.Keys, "save"),
+		confirm: newConfirmKeys(env.Keys),
+		input:   input,
+		filter:  filter,
+	}
+}
+
+func (m BlockSitesModel) Init() tea.Cmd { return m.load() }
+
+func (m BlockSitesModel) Keys() help.KeyMap {
+	switch m.mode {
+	case modeAdd, modeEdit:
+		return m.form
+	case modeFilter:
+		return newFormKeys(m.env.Keys, "keep filter")
+	case modeConfirmDelete:
+		return m.confirm
+	}
+	return m.keys
+}
+
+// InputFocused tells the root to leave shortcut keys alone while typing.
+func (m BlockSitesModel) InputFocused() bool {
+	return m.mode != modeBrowse
+}
+
+func (m BlockSitesModel) load() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		sites, err := svc.ListBlockedSites()
+		if err != nil {
+			return sitesLoadedMsg{err: err}
+		}
+		session, err := svc.ActiveSession()
+		if err != nil {
+			return sitesLoadedMsg{err: err}
+		}
+		return sitesLoadedMsg{sites: sites, locked: session != nil && session.Strict}
+	}
+}
+
+// visible returns the sites matching the current filter.
+func (m BlockSitesModel) visible() []models.BlockedSite {
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if query == "" {
+		return m.sites
+	}
+
+	var matches []models.BlockedSite
+	for _, site := range m.sites {
+		if strings.Contains(site.Domain, query) {
+			matches = append(matches, site)
+		}
+	}
+	return matches
+}
+
+func (m BlockSitesModel) selected() (models.BlockedSite, bool) {
+	sites := m.visible()
+	if m.cursor < 0 || m.cursor >= len(sites) {
+		return models.BlockedSite{}, false
+	}
+	return sites[m.cursor], true
+}
+
+func (m BlockSitesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case sitesLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.sites = msg.sites
+		m.locked = msg.locked
+		m.cursor = clamp(m.cursor, 0, len(m.visible())-1)
+		return m, nil
+
+	case siteSavedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, m.load()
+		}
+		m.status = msg.status
+		return m, m.load()
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "down":
+		switch m.mode {
+		case modeAdd, modeEdit:
+			return m.updateInput(msg)
+		case modeConfirmDelete:
+			return m.updateConfirm(msg)
+		case modeFilter:
+			return m.updateFilter(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+
+	return m, nil
+}
+
+func (m BlockSitesModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.err = ""
+	m.status = ""
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.visible())-1 {
 			m.cursor++
-		case "up":
-			if m.cursor > 0 {
-				m.cursor--
+		}
+	case key.Matches(msg, m.keys.Filter):
+		m.mode = modeFilter
+		return m, m.filter.Focus()
+	case key.Matches(msg, m.keys.Add):
+		m.mode = modeAdd
+		m.input.SetValue("")
+		return m, m.input.Focus()
+	case key.Matches(msg, m.keys.Edit):
+		site, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if m.locked {
+			m.err = "A strict session is running; entries can't be changed until it ends."
+			return m, nil
+		}
+		m.mode = modeEdit
+		m.editing = site
+		m.input.SetValue(site.Domain)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case key.Matches(msg, m.keys.Delete):
+		site, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if m.locked {
+			m.err = "A strict session is running; entries can't be removed until it ends."
+			return m, nil
+		}
+		m.mode = modeConfirmDelete
+		m.editing = site
+	}
+	return m, nil
+}
+
+func (m BlockSitesModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.form.Cancel):
+		m.mode = modeBrowse
+		m.err = ""
+		m.input.Blur()
+		return m, nil
+
+	case key.Matches(msg, m.form.Submit):
+		domain := strings.ToLower(strings.TrimSpace(m.input.Value()))
+		if !validator.IsValidDomain(domain) {
+			m.err = fmt.Sprintf("%q isn't a valid domain (try example.com, without http:// or a path)", domain)
+			return m, nil
+		}
+
+		svc := m.env.Service
+		mode, editing := m.mode, m.editing
+		m.mode = modeBrowse
+		m.err = ""
+		m.input.Blur()
+		return m, func() tea.Msg {
+			if mode == modeEdit {
+				editing.Domain = domain
+				return siteSavedMsg{status: "Updated " + domain, err: svc.UpdateBlockedSite(editing)}
 			}
+			return siteSavedMsg{status: "Blocked " + domain, err: svc.AddBlockedSite(domain)}
 		}
 	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	// Validate as the user types so mistakes show up before enter.
+	m.err = ""
+	if value := strings.TrimSpace(m.input.Value()); value != "" && !validator.IsValidDomain(value) {
+		m.err = "not a valid domain yet"
+	}
+	return m, cmd
+}
+
+func (m BlockSitesModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.confirm.Confirm):
+		svc := m.env.Service
+		domain := m.editing.Domain
+		m.mode = modeBrowse
+		return m, func() tea.Msg {
+			return siteSavedMsg{status: "Unblocked " + domain, err: svc.RemoveBlockedSite(domain)}
+		}
+	case key.Matches(msg, m.confirm.Deny):
+		m.mode = modeBrowse
+	}
 	return m, nil
 }
 
+func (m BlockSitesModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.form.Cancel):
+		m.filter.SetValue("")
+		m.filter.Blur()
+		m.mode = modeBrowse
+		m.cursor = 0
+		return m, nil
+	case key.Matches(msg, m.form.Submit):
+		m.filter.Blur()
+		m.mode = modeBrowse
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.cursor = 0
+	return m, cmd
+}
+
 func (m BlockSitesModel) View() string {
-	return fmt.Sprintf("🔒 Block Sites\n\nCursor: %d\n\nPress q → Quit", m.cursor)
+	th := m.env.Theme
+	var b strings.Builder
+
+	b.WriteString(th.Header("🔒", "Block Sites"))
+
+	if m.locked {
+		b.WriteString(th.Notice(th.Icon("🔐 ", "")+"Strict session running: you can add sites, but not edit or remove them.") + "\n\n")
+	}
+
+	if m.mode == modeFilter || m.filter.Value() != "" {
+		b.WriteString(m.filter.View() + "\n\n")
+	}
+
+	sites := m.visible()
+	switch {
+	case len(m.sites) == 0:
+		b.WriteString(th.Muted.Render(fmt.Sprintf("   No blocked sites yet. Press %s to add one.", keyName(m.keys.Add))) + "\n")
+	case len(sites) == 0:
+		b.WriteString(th.Muted.Render("   No sites match the filter.") + "\n")
+	}
+
+	start, end := listWindow(m.cursor, len(sites), m.listHeight())
+	for i := start; i < end; i++ {
+		b.WriteString(th.Item(m.cursor == i, sites[i].Domain) + "\n")
+	}
+	if end-start < len(sites) {
+		b.WriteString("\n" + th.Muted.Render(fmt.Sprintf("   %d of %d shown", end-start, len(sites))) + "\n")
+	}
+
+	b.WriteString("\n")
+	switch m.mode {
+	case modeAdd:
+		b.WriteString("Add site: " + m.input.View() + "\n")
+	case modeEdit:
+		b.WriteString("Edit site: " + m.input.View() + "\n")
+	case modeConfirmDelete:
+		b.WriteString(th.Warning.Render(fmt.Sprintf("Remove %s from the block list? (%s/%s)", m.editing.Domain, keyName(m.confirm.Confirm), keyName(m.confirm.Deny))) + "\n")
+	}
+
+	if m.err != "" {
+		b.WriteString(th.Err(m.err) + "\n")
+	} else if m.status != "" {
+		b.WriteString(th.OK(m.status) + "\n")
+	}
+
+	return b.String()
+}
+
+// listHeight is how many rows of the list fit around the header, input
+// line and help footer.
+func (m BlockSitesModel) listHeight() int {
+	if m.height == 0 {
+		return 15
+	}
+	return max(m.height-14, 3)
+}
+
+// listWindow returns the slice of a list of n rows to show so the cursor
+// stays visible in a window of size rows.
+func listWindow(cursor, n, size int) (start, end int) {
+	if n <= size {
+		return 0, n
+	}
+	start = clamp(cursor-size/2, 0, n-size)
+	return start, start + size
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
 }
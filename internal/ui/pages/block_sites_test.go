@@ -0,0 +1,55 @@
+package pages
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+func seedSites(store *uitest.Store) {
+	store.AddSite("youtube.com")
+	store.AddSite("reddit.com")
+	store.AddSite("news.ycombinator.com")
+}
+
+// startStrict puts a strict session in the store that started ten minutes
+// before testNow.
+func startStrict(store *uitest.Store) {
+	store.AddSession(models.Session{
+		StartTime:       testNow.Unix() - 600,
+		DurationSeconds: 3000,
+		Active:          true,
+		Strict:          true,
+	})
+}
+
+// Test block sites view - listing, adding, validation, editing, deleting and
+// the strict lock
+func TestBlockSitesView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model { return NewBlockSitesModel(env) }, []viewCase{
+		{name: "empty", golden: "block_sites_empty.golden"},
+		{name: "list", golden: "block_sites_list.golden", setup: seedSites,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("j") }},
+		{name: "added", golden: "block_sites_added.golden", setup: seedSites,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) {
+				d.Press("a").Type("Example.com").Press("enter")
+			}},
+		{name: "invalid", golden: "block_sites_invalid.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) {
+				d.Press("a").Type("http://nope").Press("enter")
+			}},
+		{name: "edit", golden: "block_sites_edit.golden", setup: seedSites,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("j", "e") }},
+		{name: "confirm delete", golden: "block_sites_delete.golden", setup: seedSites,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("d") }},
+		{name: "deleted", golden: "block_sites_deleted.golden", setup: seedSites,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("d", "y") }},
+		{name: "filter", golden: "block_sites_filter.golden", setup: seedSites,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("/").Type("red") }},
+		{name: "strict", golden: "block_sites_strict.golden",
+			setup: func(store *uitest.Store) { seedSites(store); startStrict(store) },
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("d") }},
+	})
+}
@@ -0,0 +1,52 @@
+package pages
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/ui/theme"
+)
+
+// Env is handed to every page constructor. It carries what pages need from
+// outside the UI so the root can build it once and tests can fake it.
+type Env struct {
+	// Menu is what the home screen lists.
+	Menu []MenuItem
+	// Service is the daemon client, or direct database access when no
+	// daemon is running.
+	Service service.Service
+	// Processes lists what is running, for the blocked apps page.
+	Processes func() ([]blocker.Process, error)
+	// Now is the clock the timer pages count down against.
+	Now func() time.Time
+	// Tick is tea.Tick, or in tests a clock's Tick that only fires when
+	// the test moves the clock.
+	Tick func(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd
+	// StaticCursor stops text inputs blinking, since a blink is a timer
+	// nothing in a test would fire.
+	StaticCursor bool
+	// Theme is what every page styles its view with.
+	Theme theme.Theme
+	// Keys maps actions to keys; nil means the defaults.
+	Keys Keymap
+	// DefaultDuration is the session length the timer page starts on;
+	// zero means the first preset.
+	DefaultDuration time.Duration
+	// StartEnforcer starts lockind in the background and returns a client
+	// for it, so sessions are enforced while the UI is open and after it
+	// quits.
+	StartEnforcer func() (service.Service, error)
+}
+
+// newInput returns a text input whose cursor blinks unless env says not to.
+func newInput(env *Env) textinput.Model {
+	input := textinput.New()
+	if env.StaticCursor {
+		input.Cursor.SetMode(cursor.CursorStatic)
+	}
+	return input
+}
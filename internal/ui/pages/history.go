@@ -0,0 +1,493 @@
+package pages
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+)
+
+// historyPageSize is how many sessions are fetched at a time. More are
+// loaded as the cursor reaches the end of what is on screen.
+const historyPageSize = 50
+
+// dateLayout is how the filter's date range is typed.
+const dateLayout = "2006-01-02"
+
+type historyKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Filter key.Binding
+	Note   key.Binding
+	Delete key.Binding
+	Back   key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+}
+
+func (k historyKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Filter, k.Note, k.Delete, k.Back, k.Help}
+}
+
+func (k historyKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Filter, k.Note, k.Delete},
+		{k.Back, k.Help, k.Quit},
+	}
+}
+
+func newHistoryKeys(k Keymap) historyKeys {
+	return historyKeys{
+		Up:     k.Binding(KeyUp, "move up"),
+		Down:   k.Binding(KeyDown, "move down"),
+		Filter: k.Binding(KeyFilter, "filter"),
+		Note:   k.Binding(KeyNote, "note"),
+		Delete: k.Binding(KeyDelete, "delete"),
+		Back:   k.Binding(KeyBack, "back"),
+		Help:   k.Binding(KeyHelp, "toggle help"),
+		Quit:   k.Binding(KeyQuit, "quit"),
+	}
+}
+
+// historyMode is what the history page is doing with the keyboard.
+type historyMode int
+
+const (
+	historyBrowse historyMode = iota
+	historyFilter
+	historyNote
+	historyConfirmDelete
+)
+
+// historyLoadedMsg carries one page of sessions. append is set when it
+// continues the rows already shown rather than replacing them.
+type historyLoadedMsg struct {
+	page   *service.HistoryPage
+	append bool
+	err    error
+}
+
+// historySavedMsg reports the result of a delete or note.
+type historySavedMsg struct {
+	status string
+	err    error
+}
+
+type HistoryModel struct {
+	env     *Env
+	keys    historyKeys
+	form    formKeys
+	fields  fieldKeys
+	confirm confirmKeys
+
+	query    service.HistoryQuery
+	sessions []models.Session
+	total    int
+	loading  bool
+
+	mode   historyMode
+	cursor int
+
+	// filters are the from, to and profile fields of the filter form.
+	filters     [3]textinput.Model
+	filterFocus int
+	note        textinput.Model
+
+	status string
+	err    string
+	height int
+}
+
+func NewHistoryModel(env *Env) HistoryModel {
+	var filters [3]textinput.Model
+	for i, placeholder := range []string{dateLayout, dateLayout, "any profile"} {
+		filters[i] = newInput(env)
+		filters[i].Placeholder = placeholder
+		filters[i].CharLimit = 64
+		filters[i].Width = 16
+	}
+
+	note := newInput(env)
+	note.Placeholder = "what happened?"
+	note.CharLimit = 500
+	note.Width = 48
+
+	return HistoryModel{
+		env:     env,
+		keys:    newHistoryKeys(env.Keys),
+		form:    newFormKeys(env.Keys, "save note"),
+		fields:  newFieldKeys(env.Keys, "next field", "previous field", "apply"),
+		confirm: newConfirmKeys(env.Keys),
+		query:   service.HistoryQuery{Limit: historyPageSize},
+		filters: filters,
+		note:    note,
+	}
+}
+
+func (m HistoryModel) Init() tea.Cmd { return m.load(false) }
+
+func (m HistoryModel) Keys() help.KeyMap {
+	switch m.mode {
+	case historyFilter:
+		return m.fields
+	case historyNote:
+		return m.form
+	case historyConfirmDelete:
+		return m.confirm
+	}
+	return m.keys
+}
+
+// InputFocused tells the root to leave shortcut keys alone while typing.
+func (m HistoryModel) InputFocused() bool {
+	return m.mode != historyBrowse
+}
+
+// load fetches the first page for the current query, or the next page
+// after the rows already loaded when more is set.
+func (m *HistoryModel) load(more bool) tea.Cmd {
+	svc := m.env.Service
+	query := m.query
+	if more {
+		query.Offset = len(m.sessions)
+	}
+	m.loading = true
+	return func() tea.Msg {
+		page, err := svc.QueryHistory(query)
+		return historyLoadedMsg{page: page, append: more, err: err}
+	}
+}
+
+func (m HistoryModel) selected() (models.Session, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.sessions) {
+		return models.Session{}, false
+	}
+	return m.sessions[m.cursor], true
+}
+
+func (m HistoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		return m, nil
+
+	case historyLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		if msg.append {
+			m.sessions = append(m.sessions, msg.page.Sessions...)
+		} else {
+			m.sessions = msg.page.Sessions
+		}
+		m.total = msg.page.Total
+		m.cursor = clamp(m.cursor, 0, len(m.sessions)-1)
+		return m, nil
+
+	case historySavedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		} else {
+			m.status = msg.status
+		}
+		return m, m.load(false)
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case historyFilter:
+			return m.updateFilter(msg)
+		case historyNote:
+			return m.updateNote(msg)
+		case historyConfirmDelete:
+			return m.updateConfirm(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+
+	return m, nil
+}
+
+func (m HistoryModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.err = ""
+	m.status = ""
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.cursor < len(m.sessions)-1 {
+			m.cursor++
+		}
+		// Fetch the next page just before the cursor runs out of rows.
+		if m.cursor >= len(m.sessions)-1 && len(m.sessions) < m.total && !m.loading {
+			return m, m.load(true)
+		}
+	case key.Matches(msg, m.keys.Filter):
+		m.mode = historyFilter
+		m.filterFocus = 0
+		return m, m.filters[0].Focus()
+	case key.Matches(msg, m.keys.Note):
+		session, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		m.mode = historyNote
+		m.note.SetValue(session.Note)
+		m.note.CursorEnd()
+		return m, m.note.Focus()
+	case key.Matches(msg, m.keys.Delete):
+		if _, ok := m.selected(); ok {
+			m.mode = historyConfirmDelete
+		}
+	}
+	return m, nil
+}
+
+func (m HistoryModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.fields.Cancel):
+		m.blurFilters()
+		m.mode = historyBrowse
+		m.err = ""
+		return m, nil
+
+	case key.Matches(msg, m.fields.Next, m.fields.Prev):
+		m.filters[m.filterFocus].Blur()
+		step := 1
+		if key.Matches(msg, m.fields.Prev) {
+			step = len(m.filters) - 1
+		}
+		m.filterFocus = (m.filterFocus + step) % len(m.filters)
+		return m, m.filters[m.filterFocus].Focus()
+
+	case key.Matches(msg, m.fields.Submit):
+		query, err := m.filterQuery()
+		if err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.blurFilters()
+		m.mode = historyBrowse
+		m.err = ""
+		m.query = query
+		m.cursor = 0
+		return m, m.load(false)
+	}
+
+	var cmd tea.Cmd
+	m.filters[m.filterFocus], cmd = m.filters[m.filterFocus].Update(msg)
+	return m, cmd
+}
+
+func (m *HistoryModel) blurFilters() {
+	for i := range m.filters {
+		m.filters[i].Blur()
+	}
+}
+
+// filterQuery builds a query from the filter form. Dates are whole days in
+// local time, and the range includes the end date.
+func (m HistoryModel) filterQuery() (service.HistoryQuery, error) {
+	query := service.HistoryQuery{Limit: historyPageSize}
+
+	from := strings.TrimSpace(m.filters[0].Value())
+	if from != "" {
+		t, err := time.ParseInLocation(dateLayout, from, m.env.Now().Location())
+		if err != nil {
+			return query, fmt.Errorf("%q isn't a date like 2026-03-02", from)
+		}
+		query.From = t
+	}
+
+	to := strings.TrimSpace(m.filters[1].Value())
+	if to != "" {
+		t, err := time.ParseInLocation(dateLayout, to, m.env.Now().Location())
+		if err != nil {
+			return query, fmt.Errorf("%q isn't a date like 2026-03-02", to)
+		}
+		query.To = t.AddDate(0, 0, 1)
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return query, fmt.Errorf("the from date must not be after the to date")
+	}
+
+	query.Profile = strings.TrimSpace(m.filters[2].Value())
+	return query, nil
+}
+
+func (m HistoryModel) updateNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.form.Cancel):
+		m.note.Blur()
+		m.mode = historyBrowse
+		return m, nil
+	case key.Matches(msg, m.form.Submit):
+		session, ok := m.selected()
+		m.note.Blur()
+		m.mode = historyBrowse
+		if !ok {
+			return m, nil
+		}
+		svc := m.env.Service
+		note := m.note.Value()
+		return m, func() tea.Msg {
+			return historySavedMsg{status: "Saved note", err: svc.AnnotateSession(session.ID, note)}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.note, cmd = m.note.Update(msg)
+	return m, cmd
+}
+
+func (m HistoryModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.confirm.Confirm):
+		session, ok := m.selected()
+		m.mode = historyBrowse
+		if !ok {
+			return m, nil
+		}
+		svc := m.env.Service
+		return m, func() tea.Msg {
+			return historySavedMsg{status: "Deleted session", err: svc.DeleteSession(session.ID)}
+		}
+	case key.Matches(msg, m.confirm.Deny):
+		m.mode = historyBrowse
+	}
+	return m, nil
+}
+
+func (m HistoryModel) View() string {
+	th := m.env.Theme
+	var b strings.Builder
+
+	b.WriteString(th.Header("📜", "Session History"))
+
+	if m.mode == historyFilter {
+		labels := []string{"From:    ", "To:      ", "Profile: "}
+		for i, label := range labels {
+			b.WriteString(th.Cursor(m.filterFocus == i) + label + m.filters[i].View() + "\n")
+		}
+		b.WriteString("\n")
+	} else if summary := m.filterSummary(); summary != "" {
+		b.WriteString(th.Muted.Render("Filtered: "+summary) + "\n\n")
+	}
+
+	if len(m.sessions) == 0 {
+		if m.loading {
+			b.WriteString(th.Muted.Render("   Loading…") + "\n")
+		} else {
+			b.WriteString(th.Muted.Render("   No sessions to show.") + "\n")
+		}
+	} else {
+		b.WriteString(th.Title.Render(fmt.Sprintf("   %-16s  %-7s  %-7s  %-12s  %-9s  %-6s  %s",
+			"Date", "Planned", "Actual", "Profile", "Result", "Tamper", "Note")) + "\n")
+
+		start, end := listWindow(m.cursor, len(m.sessions), m.listHeight())
+		for i := start; i < end; i++ {
+			b.WriteString(th.Item(m.cursor == i, historyRow(m.sessions[i], m.env.Now().Location())) + "\n")
+		}
+		b.WriteString("\n" + th.Muted.Render(fmt.Sprintf("   %d of %d sessions loaded", len(m.sessions), m.total)) + "\n")
+	}
+
+	b.WriteString("\n")
+	switch m.mode {
+	case historyNote:
+		b.WriteString("Note: " + m.note.View() + "\n")
+	case historyConfirmDelete:
+		if session, ok := m.selected(); ok {
+			b.WriteString(th.Warning.Render(fmt.Sprintf("Delete the session from %s? (%s/%s)", sessionDate(session, m.env.Now().Location()),
+				keyName(m.confirm.Confirm), keyName(m.confirm.Deny))) + "\n")
+		}
+	}
+
+	if m.err != "" {
+		b.WriteString(th.Err(m.err) + "\n")
+	} else if m.status != "" {
+		b.WriteString(th.OK(m.status) + "\n")
+	}
+
+	return b.String()
+}
+
+func (m HistoryModel) filterSummary() string {
+	var parts []string
+	if !m.query.From.IsZero() {
+		parts = append(parts, "from "+m.query.From.Format(dateLayout))
+	}
+	if !m.query.To.IsZero() {
+		parts = append(parts, "to "+m.query.To.AddDate(0, 0, -1).Format(dateLayout))
+	}
+	if m.query.Profile != "" {
+		parts = append(parts, "profile "+m.query.Profile)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func historyRow(s models.Session, loc *time.Location) string {
+	actual := "-"
+	if seconds := s.ActualSeconds(); seconds > 0 {
+		actual = models.FormatDuration(time.Duration(seconds) * time.Second)
+	}
+
+	result := s.Status
+	switch {
+	case s.Active:
+		result = "running"
+	case result == "":
+		result = "-"
+	}
+
+	profile := s.Profile
+	if profile == "" {
+		profile = "-"
+	}
+
+	return fmt.Sprintf("%-16s  %-7s  %-7s  %-12s  %-9s  %-6d  %s",
+		sessionDate(s, loc),
+		models.FormatDuration(time.Duration(s.DurationSeconds)*time.Second),
+		actual,
+		truncate(profile, 12),
+		result,
+		s.TamperCount,
+		truncate(s.Note, 30),
+	)
+}
+
+// sessionDate shows the session's start in loc, the time zone of the
+// page's clock.
+func sessionDate(s models.Session, loc *time.Location) string {
+	return time.Unix(s.StartTime, 0).In(loc).Format("2006-01-02 15:04")
+}
+
+// truncate shortens s to n characters, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// listHeight is how many rows of the table fit around the header, filter
+// form and help footer.
+func (m HistoryModel) listHeight() int {
+	if m.height == 0 {
+		return 15
+	}
+	return max(m.height-16, 3)
+}
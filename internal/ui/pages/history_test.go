@@ -0,0 +1,51 @@
+package pages
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+func seedHistory(store *uitest.Store) {
+	day := func(n int, hour int) int64 {
+		return testNow.AddDate(0, 0, -n).Add(time.Duration(hour-9) * time.Hour).Unix()
+	}
+
+	done := models.Session{StartTime: day(3, 9), DurationSeconds: 3000, Profile: "work"}
+	done.Complete()
+	store.AddSession(done)
+
+	stopped := models.Session{StartTime: day(2, 14), DurationSeconds: 5400, Profile: "study", TamperCount: 2}
+	stopped.Abort(time.Unix(stopped.StartTime+1200, 0))
+	store.AddSession(stopped)
+
+	noted := models.Session{StartTime: day(1, 10), DurationSeconds: 1500, Note: "Wrote the report"}
+	noted.Complete()
+	store.AddSession(noted)
+}
+
+// Test history view - the table, filters, notes and deleting
+func TestHistoryView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model { return NewHistoryModel(env) }, []viewCase{
+		{name: "empty", golden: "history_empty.golden"},
+		{name: "list", golden: "history_list.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("j") }},
+		{name: "filter form", golden: "history_filter.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("f").Type("2026-03-08") }},
+		{name: "filter bad date", golden: "history_filter_invalid.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("f").Type("March").Press("enter") }},
+		{name: "filtered", golden: "history_filtered.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) {
+				d.Press("f", "tab", "tab").Type("study").Press("enter")
+			}},
+		{name: "note", golden: "history_note.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("j", "n").Type("Deep work").Press("enter") }},
+		{name: "confirm delete", golden: "history_delete.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("d") }},
+		{name: "deleted", golden: "history_deleted.golden", setup: seedHistory,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("d", "y") }},
+	})
+}
@@ -1,22 +1,23 @@
 package pages
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/ui/nav"
 )
 
 
 
 // implement help keys
 type homeKeys struct {
-	Help key.Binding
-	Quit key.Binding
-	Up   key.Binding
-	Down key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
 }
 
 func (k homeKeys) ShortHelp() []key.Binding {
@@ -25,62 +26,60 @@ func (k homeKeys) ShortHelp() []key.Binding {
 
 func (k homeKeys) FullHelp() [][]key.Binding {
     return [][]key.Binding{
-		{k.Up, k.Down},
+		{k.Up, k.Down, k.Select},
         {k.Help ,k.Quit},
     }
 }
 
-var hKeys = homeKeys{
-	Help: key.NewBinding(
-		key.WithKeys("?"),
-		key.WithHelp("?", "toggle help"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
-	),
-	Up: key.NewBinding(
-		key.WithKeys("up", "k"),
-		key.WithHelp("↑/k", "move up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down", "j"),
-		key.WithHelp("↓/j", "move down"),
-	),
+func newHomeKeys(k Keymap) homeKeys {
+	return homeKeys{
+		Help:   k.Binding(KeyHelp, "toggle help"),
+		Quit:   k.Binding(KeyQuit, "quit"),
+		Up:     k.Binding(KeyUp, "move up"),
+		Down:   k.Binding(KeyDown, "move down"),
+		Select: k.Binding(KeySelect, "open"),
+	}
+}
+
+// MenuItem is one entry on the home screen and the page it opens.
+type MenuItem struct {
+	Title string
+	Page  nav.Page
 }
 
 type HomeModel struct {
+	env    *Env
 	cursor int
-	choice string
+	items  []MenuItem
 	keys   homeKeys
 }
 
 func (m HomeModel) Keys() help.KeyMap {
-    return hKeys
+    return m.keys
 }
 
 
-func NewHomeModel() HomeModel { return HomeModel{} }
+func NewHomeModel(env *Env) HomeModel {
+	return HomeModel{env: env, items: env.Menu, keys: newHomeKeys(env.Keys)}
+}
 
 func (m HomeModel) Init() tea.Cmd { return nil }
 
-var choices = []string{"Add website to block list", "Set Timer"}
-
-func (m HomeModel) Update(msg tea.Msg) (HomeModel, tea.Cmd) {
+func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			m.choice = choices[m.cursor]
-		case "q", "Q", "ctrl+c":
-			return m, tea.Quit
-		case "down", "k":
-			if m.cursor < len(choices)-1 {
+		switch {
+		case key.Matches(msg, m.keys.Select):
+			if len(m.items) > 0 {
+				return m, nav.Push(m.items[m.cursor].Page)
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.items)-1 {
 				m.cursor++
 			}
-		case "up", "j":
+		case key.Matches(msg, m.keys.Up):
 			if m.cursor > 0 {
 				m.cursor--
 			}
@@ -93,18 +92,11 @@ func (m HomeModel) Update(msg tea.Msg) (HomeModel, tea.Cmd) {
 func (m HomeModel) View() string {
 	var b strings.Builder
 
-	b.WriteString("\n🔒  LockIn\n")
-	b.WriteString("====================\n\n")
+	b.WriteString(m.env.Theme.Header("🔒", "LockIn"))
 
-	
-	for i, c := range choices {
-		cursor := "   "
-		if m.cursor == i {
-			cursor = "➜  "
-		}
-		b.WriteString(fmt.Sprintf("%s%s\n", cursor, c))
+	for i, item := range m.items {
+		b.WriteString(m.env.Theme.Item(m.cursor == i, item.Title) + "\n")
 	}
 
-
 	return b.String()
 }
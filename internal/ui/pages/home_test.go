@@ -0,0 +1,32 @@
+package pages
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/ui/nav"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+var testMenu = []MenuItem{{"One", nav.BlockSites}, {"Two", nav.BlockApps}, {"Three", nav.Stats}}
+
+// Test home navigation - k moves up and j moves down, as the help says
+func TestHomeNavigation(t *testing.T) {
+	env := &Env{Menu: testMenu}
+	d := uitest.New(t, NewHomeModel(env)).Press("j", "j", "k")
+
+	if cursor := d.Model.(HomeModel).cursor; cursor != 1 {
+		t.Errorf("cursor = %d after j, j, k, want 1", cursor)
+	}
+}
+
+// Test home view - the menu with the cursor moved down
+func TestHomeView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model {
+		env.Menu = testMenu
+		return NewHomeModel(env)
+	}, []viewCase{
+		{name: "menu", golden: "home_menu.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("down") }},
+	})
+}
@@ -0,0 +1,244 @@
+package pages
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Key actions, as named in the [keys] section of the config file. A page
+// asks the keymap for the action's binding and uses that one binding both
+// to handle the key and to list it in the help, so the two can't disagree.
+const (
+	KeyUp         = "up"
+	KeyDown       = "down"
+	KeyLeft       = "left"
+	KeyRight      = "right"
+	KeyNextField  = "next_field"
+	KeyPrevField  = "prev_field"
+	KeySelect     = "select"
+	KeyAdd        = "add"
+	KeyEdit       = "edit"
+	KeyDelete     = "delete"
+	KeyFilter     = "filter"
+	KeyPick       = "pick"
+	KeyNote       = "note"
+	KeyToggle     = "toggle"
+	KeyStart      = "start"
+	KeyNewSession = "new_session"
+	KeyExtend     = "extend"
+	KeyRefresh    = "refresh"
+	KeySubmit     = "submit"
+	KeyCancel     = "cancel"
+	KeyConfirm    = "confirm"
+	KeyDeny       = "deny"
+	KeyBackground = "background"
+	KeyBack       = "back"
+	KeyHelp       = "help"
+	KeyQuit       = "quit"
+)
+
+// defaultKeys are the keys each action starts with.
+var defaultKeys = map[string][]string{
+	KeyUp:         {"up", "k"},
+	KeyDown:       {"down", "j"},
+	KeyLeft:       {"left"},
+	KeyRight:      {"right"},
+	KeyNextField:  {"down", "tab"},
+	KeyPrevField:  {"up", "shift+tab"},
+	KeySelect:     {"enter"},
+	KeyAdd:        {"a"},
+	KeyEdit:       {"e", "enter"},
+	KeyDelete:     {"d", "x"},
+	KeyFilter:     {"/", "f"},
+	KeyPick:       {"p"},
+	KeyNote:       {"n"},
+	KeyToggle:     {" "},
+	KeyStart:      {"enter"},
+	KeyNewSession: {"s"},
+	KeyExtend:     {"+", "="},
+	KeyRefresh:    {"r"},
+	KeySubmit:     {"enter"},
+	KeyCancel:     {"esc"},
+	KeyConfirm:    {"y", "Y"},
+	KeyDeny:       {"n", "N", "esc"},
+	KeyBackground: {"b"},
+	KeyBack:       {"esc"},
+	KeyHelp:       {"?"},
+	KeyQuit:       {"q"},
+}
+
+// ForceQuit quits from anywhere, even while typing. It can't be remapped.
+var ForceQuit = key.NewBinding(
+	key.WithKeys("ctrl+c"),
+	key.WithHelp("ctrl+c", "quit"),
+)
+
+// typingActions are handled while a text field has focus, so they can't be
+// bound to keys that would otherwise be typed.
+var typingActions = []string{KeyNextField, KeyPrevField, KeySubmit, KeyCancel}
+
+// Keymap holds the keys for every action. The zero value uses the defaults.
+type Keymap map[string][]string
+
+// DefaultKeymap returns a keymap with every action on its default keys.
+func DefaultKeymap() Keymap {
+	k := make(Keymap, len(defaultKeys))
+	for action, keys := range defaultKeys {
+		k[action] = slices.Clone(keys)
+	}
+	return k
+}
+
+// NewKeymap applies the remapped actions from the config file over the
+// defaults. Each remap replaces all of an action's keys.
+func NewKeymap(overrides map[string][]string) (Keymap, error) {
+	k := DefaultKeymap()
+
+	actions := make([]string, 0, len(overrides))
+	for action := range overrides {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	for _, action := range actions {
+		keys := overrides[action]
+		if _, ok := defaultKeys[action]; !ok {
+			return nil, fmt.Errorf("unknown key action %q", action)
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("key action %q has no keys", action)
+		}
+		for _, s := range keys {
+			if s == "" {
+				return nil, fmt.Errorf("key action %q has an empty key", action)
+			}
+			if slices.Contains(typingActions, action) && utf8.RuneCountInString(s) == 1 && s != " " {
+				return nil, fmt.Errorf("key action %q can't use %q: it would be typed into text fields", action, s)
+			}
+		}
+		k[action] = slices.Clone(keys)
+	}
+
+	return k, nil
+}
+
+// Binding returns the binding for action, described in the help as desc.
+func (k Keymap) Binding(action, desc string) key.Binding {
+	keys, ok := k[action]
+	if !ok {
+		keys = defaultKeys[action]
+	}
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(keyLabel(keys), desc),
+	)
+}
+
+// keyNames are shown in the help instead of the raw key strings.
+var keyNames = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	" ":     "space",
+}
+
+// keyName is how a binding's keys are named in view text, so the text
+// follows the keymap like the help footer does.
+func keyName(b key.Binding) string { return b.Help().Key }
+
+// keyLabel is how a binding's keys appear in the help: the first key, and
+// the second as well when the first is an arrow, as in ↑/k.
+func keyLabel(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	name := func(s string) string {
+		if n, ok := keyNames[s]; ok {
+			return n
+		}
+		return s
+	}
+
+	label := name(keys[0])
+	switch keys[0] {
+	case "up", "down", "left", "right":
+		if len(keys) > 1 {
+			label += "/" + name(keys[1])
+		}
+	}
+	return strings.TrimSpace(label)
+}
+
+// formKeys are the help shown while typing into a text field.
+type formKeys struct {
+	Submit key.Binding
+	Cancel key.Binding
+}
+
+func newFormKeys(k Keymap, submit string) formKeys {
+	return formKeys{
+		Submit: k.Binding(KeySubmit, submit),
+		Cancel: k.Binding(KeyCancel, "cancel"),
+	}
+}
+
+func (k formKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Submit, k.Cancel}
+}
+
+func (k formKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Submit, k.Cancel}}
+}
+
+// confirmKeys are the help shown while a yes/no question is open.
+type confirmKeys struct {
+	Confirm key.Binding
+	Deny    key.Binding
+}
+
+func newConfirmKeys(k Keymap) confirmKeys {
+	return confirmKeys{
+		Confirm: k.Binding(KeyConfirm, "yes"),
+		Deny:    k.Binding(KeyDeny, "no"),
+	}
+}
+
+func (k confirmKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Confirm, k.Deny}
+}
+
+func (k confirmKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Confirm, k.Deny}}
+}
+
+// fieldKeys are the help shown while moving between fields or rows with a
+// text field focused, like the history filter or the app picker.
+type fieldKeys struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Submit key.Binding
+	Cancel key.Binding
+}
+
+func newFieldKeys(k Keymap, next, prev, submit string) fieldKeys {
+	return fieldKeys{
+		Next:   k.Binding(KeyNextField, next),
+		Prev:   k.Binding(KeyPrevField, prev),
+		Submit: k.Binding(KeySubmit, submit),
+		Cancel: k.Binding(KeyCancel, "cancel"),
+	}
+}
+
+func (k fieldKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Prev, k.Submit, k.Cancel}
+}
+
+func (k fieldKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Next, k.Prev}, {k.Submit, k.Cancel}}
+}
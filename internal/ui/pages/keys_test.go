@@ -0,0 +1,124 @@
+package pages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+// Test NewKeymap - remaps replace the defaults and bad remaps are rejected
+func TestNewKeymap(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		action    string
+		press     string
+		want      bool
+		wantErr   bool
+	}{
+		{"default up", nil, KeyUp, "k", true, false},
+		{"default down", nil, KeyDown, "j", true, false},
+		{"remapped", map[string][]string{KeyUp: {"w"}}, KeyUp, "w", true, false},
+		{"remap drops defaults", map[string][]string{KeyUp: {"w"}}, KeyUp, "k", false, false},
+		{"others untouched", map[string][]string{KeyUp: {"w"}}, KeyDown, "j", true, false},
+		{"unknown action", map[string][]string{"jump": {"g"}}, "", "", false, true},
+		{"no keys", map[string][]string{KeyQuit: {}}, "", "", false, true},
+		{"empty key", map[string][]string{KeyQuit: {""}}, "", "", false, true},
+		{"typed into fields", map[string][]string{KeySubmit: {"s"}}, "", "", false, true},
+		{"named key in fields", map[string][]string{KeySubmit: {"ctrl+s"}}, KeySubmit, "ctrl+s", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := NewKeymap(tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewKeymap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := key.Matches(uitest.Key(tt.press), km.Binding(tt.action, ""))
+			if got != tt.want {
+				t.Errorf("%s matches %q = %v, want %v", tt.action, tt.press, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test keyLabel - help labels show arrows as symbols
+func TestKeyLabel(t *testing.T) {
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"up", "k"}, "↑/k"},
+		{[]string{"down", "tab"}, "↓/tab"},
+		{[]string{"e", "enter"}, "e"},
+		{[]string{" "}, "space"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := keyLabel(tt.keys); got != tt.want {
+			t.Errorf("keyLabel(%q) = %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+}
+
+// Test remapped keys in view text - hints in the pages name the keys the
+// keymap binds, not the defaults
+func TestRemappedKeysInViews(t *testing.T) {
+	keys, err := NewKeymap(map[string][]string{
+		KeyNewSession: {"n"},
+		KeyAdd:        {"+"},
+		KeyPick:       {"ctrl+p"},
+		KeyStart:      {"ctrl+s"},
+		KeyConfirm:    {"o"},
+		KeyDeny:       {"ctrl+n"},
+		KeyCancel:     {"ctrl+g"},
+	})
+	if err != nil {
+		t.Fatalf("NewKeymap() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		open  func(env *Env) tea.Model
+		press []string
+		want  []string
+	}{
+		{"timer", func(env *Env) tea.Model { return NewTimerModel(env) }, nil, []string{"Press n to set a timer."}},
+		{"block sites", func(env *Env) tea.Model { return NewBlockSitesModel(env) }, nil, []string{"Press + to add one."}},
+		{"block apps", func(env *Env) tea.Model { return NewBlockAppsModel(env) }, nil, []string{"Press + to add one, or ctrl+p to pick"}},
+		{"set timer", func(env *Env) tea.Model { return NewSetTimerModel(env) }, nil, []string{"Press ctrl+s to focus"}},
+		{"remove site", func(env *Env) tea.Model { return NewBlockSitesModel(env) }, []string{"d"}, []string{"from the block list? (o/ctrl+n)"}},
+		{"remove app", func(env *Env) tea.Model { return NewBlockAppsModel(env) }, []string{"d"}, []string{"from the block list? (o/ctrl+n)"}},
+		{"pick app", func(env *Env) tea.Model { return NewBlockAppsModel(env) }, []string{"ctrl+p"}, []string{"(enter to block, ctrl+g to cancel)"}},
+		{"delete session", func(env *Env) tea.Model { return NewHistoryModel(env) }, []string{"d"}, []string{"? (o/ctrl+n)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := uitest.NewClock(testNow)
+			store := uitest.NewStore(clock)
+			// Prompts need something to ask about; the empty pages don't.
+			if len(tt.press) > 0 {
+				store.AddSite("news.example.com")
+				store.AddApp("slack")
+				store.AddSession(models.Session{StartTime: testNow.Unix() - 3600, DurationSeconds: 1500, Status: models.StatusCompleted})
+			}
+			env := testEnv(clock, store)
+			env.Keys = keys
+			view := uitest.New(t, tt.open(env)).Size(80, 24).Press(tt.press...).View()
+			for _, want := range tt.want {
+				if !strings.Contains(view, want) {
+					t.Errorf("view doesn't say %q:\n%s", want, view)
+				}
+			}
+		})
+	}
+}
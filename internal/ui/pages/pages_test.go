@@ -0,0 +1,104 @@
+package pages
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/ui/theme"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// testNow is a Tuesday morning; every page test starts its clock here.
+var testNow = time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	// Snapshots are plain text whatever terminal the tests run in.
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+// checkGolden compares got with testdata/name, or rewrites it under -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		err := os.MkdirAll("testdata", 0755)
+		if err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		err = os.WriteFile(path, []byte(got), 0644)
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match golden file.\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// testProcesses is what the blocked apps page sees running.
+func testProcesses() ([]blocker.Process, error) {
+	return []blocker.Process{
+		{PID: 101, Name: "firefox", Command: "firefox"},
+		{PID: 102, Name: "slack", Command: "slack"},
+		{PID: 103, Name: "steam", Command: "steam"},
+	}, nil
+}
+
+var noColorTheme, _ = theme.New(theme.Dark, true, false)
+
+func testEnv(clock *uitest.Clock, store *uitest.Store) *Env {
+	return &Env{
+		Service:   store,
+		Processes: testProcesses,
+		Now:       clock.Now,
+		Tick:      clock.Tick,
+		// Blinking would be a timer the tests never fire.
+		StaticCursor: true,
+		// Without colour the heatmap shows its levels as glyphs.
+		Theme: noColorTheme,
+	}
+}
+
+// viewCase drives a page through steps and snapshots its view.
+type viewCase struct {
+	name   string
+	golden string
+	// setup seeds the store before the page opens.
+	setup func(store *uitest.Store)
+	steps func(d *uitest.Driver, clock *uitest.Clock)
+}
+
+func runViewCases(t *testing.T, open func(env *Env) tea.Model, tests []viewCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := uitest.NewClock(testNow)
+			store := uitest.NewStore(clock)
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+
+			d := uitest.New(t, open(testEnv(clock, store))).Size(80, 24)
+			if tt.steps != nil {
+				tt.steps(d, clock)
+			}
+			checkGolden(t, tt.golden, d.View())
+		})
+	}
+}
@@ -2,31 +2,476 @@ package pages
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/ui/nav"
 )
 
-type SetTimerModel struct{ minutes int }
+// presets are the one-key session lengths offered above the custom entry.
+var presets = []time.Duration{25 * time.Minute, 50 * time.Minute, 90 * time.Minute}
 
-func NewSetTimerModel() SetTimerModel { return SetTimerModel{} }
+// breaks are the break lengths a session can be followed by; the first is
+// no break at all.
+var breaks = []time.Duration{0, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute}
 
-func (m SetTimerModel) Init() tea.Cmd { return nil }
+type setTimerKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Left   key.Binding
+	Right  key.Binding
+	Toggle key.Binding
+	Start  key.Binding
+	// Daemon and Deny answer the prompt to start lockind.
+	Daemon key.Binding
+	Deny   key.Binding
+	Back   key.Binding
+	// Cancel goes back from a text field, where Back's keys may be typed.
+	Cancel key.Binding
+	Help   key.Binding
+	Quit   key.Binding
+	// prompt is set while asking to start lockind.
+	prompt bool
+}
+
+func (k setTimerKeys) ShortHelp() []key.Binding {
+	if k.prompt {
+		return []key.Binding{k.Daemon, k.Deny, k.Quit}
+	}
+	return []key.Binding{k.Start, k.Toggle, k.Back, k.Help}
+}
+
+func (k setTimerKeys) FullHelp() [][]key.Binding {
+	if k.prompt {
+		return [][]key.Binding{k.ShortHelp()}
+	}
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Left, k.Right},
+		{k.Toggle, k.Start},
+		{k.Back, k.Help, k.Quit},
+	}
+}
+
+func newSetTimerKeys(k Keymap) setTimerKeys {
+	return setTimerKeys{
+		Up:     k.Binding(KeyPrevField, "previous field"),
+		Down:   k.Binding(KeyNextField, "next field"),
+		Left:   k.Binding(KeyLeft, "shorter preset or break"),
+		Right:  k.Binding(KeyRight, "longer preset or break"),
+		Toggle: k.Binding(KeyToggle, "toggle strict"),
+		Start:  k.Binding(KeyStart, "start session"),
+		Daemon: k.Binding(KeyBackground, "start lockind and focus"),
+		Deny:   k.Binding(KeyDeny, "cancel"),
+		Back:   k.Binding(KeyBack, "back"),
+		Cancel: k.Binding(KeyCancel, "back"),
+		Help:   k.Binding(KeyHelp, "toggle help"),
+		Quit:   k.Binding(KeyQuit, "quit"),
+	}
+}
+
+// setTimerField is the row of the form that has focus.
+type setTimerField int
+
+const (
+	fieldPreset setTimerField = iota
+	fieldCustom
+	fieldProfile
+	fieldBreak
+	fieldStrict
+	fieldCount
+)
+
+// profilesLoadedMsg carries the saved profiles for the profile field.
+type profilesLoadedMsg struct {
+	profiles []models.Profile
+	err      error
+}
+
+// daemonStartedMsg carries a client for lockind once it is up.
+type daemonStartedMsg struct {
+	svc service.Service
+	err error
+}
+
+// sessionStartedMsg reports the result of starting a session.
+type sessionStartedMsg struct {
+	session *models.Session
+	err     error
+}
+
+type SetTimerModel struct {
+	env  *Env
+	keys setTimerKeys
+
+	field  setTimerField
+	preset int
+	// picked is set once a preset was chosen by hand, so it wins over the
+	// profile's usual length.
+	picked   bool
+	custom   textinput.Model
+	profile  textinput.Model
+	brk      int
+	strict   bool
+	profiles []models.Profile
+
+	// needDaemon is set while asking to start lockind: without it a
+	// session would run with nothing blocked.
+	needDaemon bool
+	starting   bool
+	err        string
+}
+
+func NewSetTimerModel(env *Env) SetTimerModel {
+	custom := newInput(env)
+	custom.Placeholder = "e.g. 1h30m"
+	custom.CharLimit = 16
+	custom.Width = 12
+
+	profile := newInput(env)
+	profile.Placeholder = "none"
+	profile.CharLimit = 64
+	profile.Width = 24
+	profile.ShowSuggestions = true
+
+	m := SetTimerModel{env: env, keys: newSetTimerKeys(env.Keys), preset: 0, custom: custom, profile: profile}
+	m.setDefault(env.DefaultDuration)
+	return m
+}
+
+// setDefault starts the form on d: its preset if there is one, otherwise
+// as a custom entry.
+func (m *SetTimerModel) setDefault(d time.Duration) {
+	if d == 0 {
+		return
+	}
+	for i, preset := range presets {
+		if preset == d {
+			m.preset = i
+			return
+		}
+	}
+	m.custom.SetValue(models.FormatDuration(d))
+}
+
+func (m SetTimerModel) Init() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		profiles, err := svc.Profiles()
+		return profilesLoadedMsg{profiles: profiles, err: err}
+	}
+}
+
+// Keys lists the keys that work on the focused field: in a text field only
+// ctrl+c quits, and the cancel key goes back.
+func (m SetTimerModel) Keys() help.KeyMap {
+	keys := m.keys
+	keys.prompt = m.needDaemon
+	if m.InputFocused() {
+		keys.Back = keys.Cancel
+		keys.Quit = ForceQuit
+	}
+	return keys
+}
+
+// InputFocused tells the root to leave shortcut keys alone while typing,
+// and while the lockind prompt is up.
+func (m SetTimerModel) InputFocused() bool {
+	return m.needDaemon || m.field == fieldCustom || m.field == fieldProfile
+}
+
+// local reports whether sessions would go straight to the database, with
+// no lockind to enforce them.
+func (m SetTimerModel) local() bool {
+	_, local := m.env.Service.(*service.Local)
+	return local
+}
+
+// duration is the length the session would start with: a custom entry,
+// else the profile's usual length unless a preset was picked, else the
+// preset, as lockin start --profile does.
+func (m SetTimerModel) duration() (time.Duration, error) {
+	if strings.TrimSpace(m.custom.Value()) != "" {
+		return service.ParseDuration(m.custom.Value())
+	}
+	if d, ok := m.profileLength(); ok {
+		return d, nil
+	}
+	return presets[m.preset], nil
+}
+
+// profileLength is the saved profile's usual length, when it applies.
+func (m SetTimerModel) profileLength() (time.Duration, bool) {
+	if m.picked {
+		return 0, false
+	}
+	profile, ok := m.savedProfile()
+	if !ok || profile.DurationSeconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(profile.DurationSeconds) * time.Second, true
+}
 
-func (m SetTimerModel) Update(msg tea.Msg) (SetTimerModel, tea.Cmd) {
+// savedProfile returns the stored profile matching the profile field.
+func (m SetTimerModel) savedProfile() (models.Profile, bool) {
+	name := strings.TrimSpace(m.profile.Value())
+	for _, profile := range m.profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return models.Profile{}, false
+}
+
+func (m SetTimerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+
+	case profilesLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.profiles = msg.profiles
+		names := make([]string, len(msg.profiles))
+		for i, profile := range msg.profiles {
+			names[i] = profile.Name
+		}
+		m.profile.SetSuggestions(names)
+		return m, nil
+
+	case daemonStartedMsg:
+		m.starting = false
+		m.needDaemon = false
+		if msg.err != nil {
+			m.err = fmt.Sprintf("Couldn't start lockind: %v", msg.err)
+			return m, nil
+		}
+		m.env.Service = msg.svc
+		return m.start()
+
+	case sessionStartedMsg:
+		m.starting = false
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		return m, nav.Replace(nav.Timer)
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up":
-			m.minutes++
-		case "down":
-			if m.minutes > 0 {
-				m.minutes--
+		return m.updateKeys(msg)
+	}
+
+	return m, nil
+}
+
+func (m SetTimerModel) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.needDaemon {
+		return m.updatePrompt(msg)
+	}
+
+	switch {
+	case m.InputFocused() && key.Matches(msg, m.keys.Cancel):
+		// The text fields swallow back from the root, so go back here.
+		return m, nav.Pop()
+	case key.Matches(msg, m.keys.Start):
+		return m.start()
+	case key.Matches(msg, m.keys.Up):
+		return m.focus((m.field + fieldCount - 1) % fieldCount)
+	case key.Matches(msg, m.keys.Down):
+		return m.focus((m.field + 1) % fieldCount)
+	}
+
+	switch m.field {
+	case fieldPreset:
+		switch {
+		case key.Matches(msg, m.keys.Left):
+			m.preset = max(m.preset-1, 0)
+			m.picked = true
+		case key.Matches(msg, m.keys.Right):
+			m.preset = min(m.preset+1, len(presets)-1)
+			m.picked = true
+		}
+		m.custom.SetValue("")
+		m.err = ""
+		return m, nil
+
+	case fieldBreak:
+		switch {
+		case key.Matches(msg, m.keys.Left):
+			m.brk = max(m.brk-1, 0)
+		case key.Matches(msg, m.keys.Right):
+			m.brk = min(m.brk+1, len(breaks)-1)
+		}
+		return m, nil
+
+	case fieldStrict:
+		if key.Matches(msg, m.keys.Toggle) {
+			m.strict = !m.strict
+		}
+		return m, nil
+
+	case fieldCustom:
+		var cmd tea.Cmd
+		m.custom, cmd = m.custom.Update(msg)
+		m.err = ""
+		if _, err := m.duration(); err != nil {
+			m.err = err.Error()
+		}
+		return m, cmd
+
+	case fieldProfile:
+		var cmd tea.Cmd
+		m.profile, cmd = m.profile.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// updatePrompt answers the prompt to start lockind before focusing.
+func (m SetTimerModel) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case m.starting:
+	case key.Matches(msg, m.keys.Daemon):
+		m.starting = true
+		start := m.env.StartEnforcer
+		return m, func() tea.Msg {
+			if start == nil {
+				return daemonStartedMsg{err: fmt.Errorf("can't start lockind from here")}
 			}
+			svc, err := start()
+			return daemonStartedMsg{svc: svc, err: err}
 		}
+	case key.Matches(msg, m.keys.Deny):
+		m.needDaemon = false
+	}
+	return m, nil
+}
+
+func (m SetTimerModel) focus(field setTimerField) (tea.Model, tea.Cmd) {
+	m.field = field
+	m.custom.Blur()
+	m.profile.Blur()
+
+	switch field {
+	case fieldCustom:
+		return m, m.custom.Focus()
+	case fieldProfile:
+		return m, m.profile.Focus()
 	}
 	return m, nil
 }
 
+func (m SetTimerModel) start() (tea.Model, tea.Cmd) {
+	if m.starting {
+		return m, nil
+	}
+
+	d, err := m.duration()
+	if err != nil {
+		m.err = err.Error()
+		return m, nil
+	}
+	if m.local() {
+		m.needDaemon = true
+		m.err = ""
+		return m, nil
+	}
+
+	opts := service.StartOptions{
+		Duration: d,
+		Profile:  strings.TrimSpace(m.profile.Value()),
+		Strict:   m.strict,
+		Break:    breaks[m.brk],
+	}
+	svc := m.env.Service
+	m.starting = true
+	m.err = ""
+	return m, func() tea.Msg {
+		session, err := svc.StartSession(opts)
+		return sessionStartedMsg{session: session, err: err}
+	}
+}
+
 func (m SetTimerModel) View() string {
-	return fmt.Sprintf("⏲ Set Timer Page\n\nMinutes: %d\n\nPress q → Quit", m.minutes)
+	th := m.env.Theme
+	var b strings.Builder
+
+	b.WriteString(th.Header("⏲", "Set Timer"))
+
+	var choices []string
+	for i, preset := range presets {
+		label := fmt.Sprintf(" %dm ", int(preset/time.Minute))
+		_, fromProfile := m.profileLength()
+		if i == m.preset && strings.TrimSpace(m.custom.Value()) == "" && !fromProfile {
+			label = "[" + label + "]"
+		} else {
+			label = " " + label + " "
+		}
+		choices = append(choices, label)
+	}
+	b.WriteString(m.row(fieldPreset, "Preset:   "+strings.Join(choices, " ")))
+	b.WriteString(m.row(fieldCustom, "Custom:   "+m.custom.View()))
+
+	profileLine := "Profile:  " + m.profile.View()
+	if profile, ok := m.savedProfile(); ok {
+		profileLine += th.Muted.Render(fmt.Sprintf("  (usually %s)", models.FormatDuration(time.Duration(profile.DurationSeconds)*time.Second)))
+	}
+	b.WriteString(m.row(fieldProfile, profileLine))
+
+	var lengths []string
+	for i, brk := range breaks {
+		label := " none "
+		if brk > 0 {
+			label = fmt.Sprintf(" %dm ", int(brk/time.Minute))
+		}
+		if i == m.brk {
+			label = "[" + label + "]"
+		} else {
+			label = " " + label + " "
+		}
+		lengths = append(lengths, label)
+	}
+	b.WriteString(m.row(fieldBreak, "Break:    "+strings.Join(lengths, " ")))
+
+	strict := "[ ]"
+	if m.strict {
+		strict = "[x]"
+	}
+	b.WriteString(m.row(fieldStrict, "Strict:   "+strict+th.Muted.Render(" can't be stopped or loosened until it ends")))
+
+	b.WriteString("\n")
+	if d, err := m.duration(); err == nil {
+		end := m.env.Now().Add(d)
+		line := fmt.Sprintf("Press %s to focus for %s, until %s", keyName(m.keys.Start), models.FormatDuration(d), end.Format("15:04"))
+		if brk := breaks[m.brk]; brk > 0 {
+			line += fmt.Sprintf(", then take a %s break", models.FormatDuration(brk))
+		}
+		b.WriteString(line + ".\n")
+	}
+	switch {
+	case m.needDaemon && m.starting:
+		b.WriteString(th.Muted.Render("Starting lockind…") + "\n")
+	case m.needDaemon:
+		b.WriteString(th.Notice(fmt.Sprintf("lockind isn't running, so nothing would block this session.\n\nPress %s to start lockind and focus, or %s to cancel.",
+			keyName(m.keys.Daemon), keyName(m.keys.Deny))) + "\n")
+	case m.local():
+		b.WriteString(th.Warning.Render("lockind isn't running; it has to be started before a session can block anything.") + "\n")
+	case m.starting:
+		b.WriteString(th.Muted.Render("Starting…") + "\n")
+	}
+	if m.err != "" {
+		b.WriteString(th.Err(m.err) + "\n")
+	}
+
+	return b.String()
+}
+
+func (m SetTimerModel) row(field setTimerField, text string) string {
+	return m.env.Theme.Cursor(m.field == field) + text + "\n"
 }
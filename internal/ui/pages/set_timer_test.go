@@ -0,0 +1,169 @@
+package pages
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+	"github.com/youssef28m/LockIn/internal/ui/nav"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+func seedProfiles(store *uitest.Store) {
+	store.ProfileList = []models.Profile{
+		{ID: 1, Name: "study", DurationSeconds: 5400},
+		{ID: 2, Name: "work", DurationSeconds: 3000},
+	}
+}
+
+// Test set timer view - presets, custom lengths, profiles and strict mode
+func TestSetTimerView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model { return NewSetTimerModel(env) }, []viewCase{
+		{name: "initial", golden: "set_timer_initial.golden"},
+		{name: "longer preset", golden: "set_timer_preset.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("right", "right") }},
+		{name: "bad custom", golden: "set_timer_custom_invalid.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("tab").Type("abc") }},
+		{name: "custom", golden: "set_timer_custom.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("tab").Type("1h15m") }},
+		{name: "profile", golden: "set_timer_profile.golden", setup: seedProfiles,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("tab", "tab").Type("study") }},
+		{name: "break", golden: "set_timer_break.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("shift+tab", "shift+tab", "right", "right") }},
+		{name: "strict", golden: "set_timer_strict.golden",
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("shift+tab", " ") }},
+		{name: "already running", golden: "set_timer_running.golden", setup: startStrict,
+			steps: func(d *uitest.Driver, _ *uitest.Clock) { d.Press("enter") }},
+	})
+}
+
+// Test set timer default - the configured length picks its preset, or fills
+// in the custom field when no preset matches
+func TestSetTimerDefault(t *testing.T) {
+	withDefault := func(d time.Duration) func(env *Env) tea.Model {
+		return func(env *Env) tea.Model {
+			env.DefaultDuration = d
+			return NewSetTimerModel(env)
+		}
+	}
+
+	runViewCases(t, withDefault(90*time.Minute), []viewCase{
+		{name: "preset", golden: "set_timer_default_preset.golden"},
+	})
+	runViewCases(t, withDefault(40*time.Minute), []viewCase{
+		{name: "custom", golden: "set_timer_default_custom.golden"},
+	})
+}
+
+// Test set timer start - enter starts a session, with its break, and swaps
+// in the timer page
+func TestSetTimerStart(t *testing.T) {
+	clock := uitest.NewClock(testNow)
+	store := uitest.NewStore(clock)
+	d := uitest.New(t, NewSetTimerModel(testEnv(clock, store))).Size(80, 24)
+
+	d.Press("right", "shift+tab", " ", "shift+tab", "right", "enter")
+
+	session, err := store.ActiveSession()
+	if err != nil || session == nil {
+		t.Fatalf("ActiveSession() = %v, %v; want a running session", session, err)
+	}
+	if session.DurationSeconds != 3000 || !session.Strict || session.BreakSeconds != 300 {
+		t.Errorf("session = %+v, want a strict 50m session with a 5m break", session)
+	}
+
+	replaced := false
+	for _, msg := range d.Msgs {
+		if msg == (nav.ReplaceMsg{Page: nav.Timer}) {
+			replaced = true
+		}
+	}
+	if !replaced {
+		t.Error("expected the page to be replaced by the timer")
+	}
+}
+
+// Test set timer profile length - a saved profile starts with its usual
+// length unless a preset or custom length is picked
+func TestSetTimerProfileLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(d *uitest.Driver)
+		want  int64
+	}{
+		{"profile's length", func(d *uitest.Driver) { d.Press("tab", "tab").Type("study") }, 5400},
+		{"preset picked", func(d *uitest.Driver) { d.Press("tab", "tab").Type("study").Press("tab", "tab", "tab", "right") }, 3000},
+		{"custom length", func(d *uitest.Driver) { d.Press("tab").Type("40m").Press("tab").Type("study") }, 2400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := uitest.NewClock(testNow)
+			store := uitest.NewStore(clock)
+			seedProfiles(store)
+			d := uitest.New(t, NewSetTimerModel(testEnv(clock, store))).Size(80, 24)
+
+			tt.steps(d)
+			d.Press("enter")
+
+			session, _ := store.ActiveSession()
+			if session == nil || session.DurationSeconds != tt.want || session.Profile != "study" {
+				t.Errorf("session = %+v, want a %ds study session", session, tt.want)
+			}
+		})
+	}
+}
+
+// Test set timer without lockind - enter asks to start lockind instead of
+// starting a session nothing would enforce, and starts both on b
+func TestSetTimerNeedsDaemon(t *testing.T) {
+	clock := uitest.NewClock(testNow)
+	store := uitest.NewStore(clock)
+	env := testEnv(clock, store)
+	db, err := storage.Open(filepath.Join(t.TempDir(), "local.db"))
+	if err == nil {
+		err = storage.InitSchema(db)
+	}
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	env.Service = service.NewLocal(db)
+	var startErr error
+	env.StartEnforcer = func() (service.Service, error) {
+		return store, startErr
+	}
+	d := uitest.New(t, NewSetTimerModel(env)).Size(80, 24)
+
+	d.Press("enter")
+	checkGolden(t, "set_timer_needs_daemon.golden", d.View())
+
+	d.Press("n")
+	if strings.Contains(d.View(), "Press b") {
+		t.Error("n should close the prompt")
+	}
+
+	startErr = errors.New("lockind not found")
+	d.Press("enter", "b")
+	if !strings.Contains(d.View(), "Couldn't start lockind: lockind not found") {
+		t.Errorf("view doesn't show the failure:\n%s", d.View())
+	}
+	if session, _ := store.ActiveSession(); session != nil {
+		t.Fatalf("a session started without lockind: %+v", session)
+	}
+
+	startErr = nil
+	d.Press("enter", "b")
+	if session, _ := store.ActiveSession(); session == nil {
+		t.Fatal("no session started once lockind was up")
+	}
+	if env.Service != store {
+		t.Error("the UI should talk to lockind once it is up")
+	}
+}
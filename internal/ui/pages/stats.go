@@ -0,0 +1,201 @@
+package pages
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/ui/theme"
+)
+
+type statsKeys struct {
+	Refresh key.Binding
+	Back    key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+}
+
+func (k statsKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.Refresh, k.Back, k.Help}
+}
+
+func (k statsKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Refresh},
+		{k.Back, k.Help, k.Quit},
+	}
+}
+
+func newStatsKeys(k Keymap) statsKeys {
+	return statsKeys{
+		Refresh: k.Binding(KeyRefresh, "refresh"),
+		Back:    k.Binding(KeyBack, "back"),
+		Help:    k.Binding(KeyHelp, "toggle help"),
+		Quit:    k.Binding(KeyQuit, "quit"),
+	}
+}
+
+// heatLevels shade a heatmap cell by how long was focused that day, from
+// nothing up to two hours or more. Level i covers days under heatLevels[i].
+var heatLevels = [theme.HeatLevels]time.Duration{
+	time.Second,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	1<<63 - 1,
+}
+
+// statsLoadedMsg carries freshly computed statistics.
+type statsLoadedMsg struct {
+	stats *service.Stats
+	err   error
+}
+
+type StatsModel struct {
+	env   *Env
+	keys  statsKeys
+	stats *service.Stats
+	now   time.Time
+	err   string
+}
+
+func NewStatsModel(env *Env) StatsModel {
+	return StatsModel{env: env, keys: newStatsKeys(env.Keys)}
+}
+
+func (m StatsModel) Init() tea.Cmd { return m.load() }
+
+func (m StatsModel) Keys() help.KeyMap { return m.keys }
+
+func (m StatsModel) load() tea.Cmd {
+	svc := m.env.Service
+	now := m.env.Now()
+	return func() tea.Msg {
+		stats, err := svc.Stats(now)
+		return statsLoadedMsg{stats: stats, err: err}
+	}
+}
+
+func (m StatsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case statsLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.err = ""
+		m.stats = msg.stats
+		m.now = m.env.Now()
+		return m, nil
+
+	case tea.KeyMsg:
+		if key.Matches(msg, m.keys.Refresh) {
+			return m, m.load()
+		}
+	}
+
+	return m, nil
+}
+
+func (m StatsModel) View() string {
+	th := m.env.Theme
+	var b strings.Builder
+
+	b.WriteString(th.Header("📊", "Focus Stats"))
+
+	if m.err != "" {
+		b.WriteString(th.Err(m.err) + "\n")
+		return b.String()
+	}
+	if m.stats == nil {
+		b.WriteString(th.Muted.Render("Loading…") + "\n")
+		return b.String()
+	}
+
+	s := m.stats
+	seconds := func(n int64) string { return models.FormatDuration(time.Duration(n) * time.Second) }
+
+	b.WriteString(fmt.Sprintf("Today:       %-8s This week:   %-8s This month: %s\n",
+		seconds(s.TodaySeconds), seconds(s.WeekSeconds), seconds(s.MonthSeconds)))
+	b.WriteString(fmt.Sprintf("All time:    %s over %s\n", seconds(s.TotalSeconds), plural(s.Sessions, "session")))
+	b.WriteString(fmt.Sprintf("Streak:      %s (longest %s)\n",
+		plural(s.CurrentStreak, "day"), plural(s.LongestStreak, "day")))
+	if known := s.Completed + s.Aborted; known > 0 {
+		b.WriteString(fmt.Sprintf("Completed:   %.0f%% of sessions (%d of %d)\n", s.CompletionRate()*100, s.Completed, known))
+	} else {
+		b.WriteString("Completed:   -\n")
+	}
+
+	b.WriteString("\n" + m.heatmap() + "\n")
+
+	return b.String()
+}
+
+// heatmap draws one column per week and one row per weekday, oldest week
+// on the left, like a contribution calendar.
+func (m StatsModel) heatmap() string {
+	th := m.env.Theme
+	byDay := make(map[string]int64, len(m.stats.Daily))
+	for _, total := range m.stats.Daily {
+		byDay[total.Start] = total.Seconds
+	}
+
+	first := service.WeekStart(m.now).AddDate(0, 0, -7*(service.HeatmapWeeks-1))
+	today := m.now.Format(time.DateOnly)
+
+	var b strings.Builder
+
+	// Month labels sit above the first week that starts in a new month.
+	labels := []rune(strings.Repeat(" ", 2*service.HeatmapWeeks+2))
+	lastMonth := time.Month(0)
+	for week := 0; week < service.HeatmapWeeks; week++ {
+		monday := first.AddDate(0, 0, 7*week)
+		if monday.Month() != lastMonth {
+			copy(labels[2*week:], []rune(monday.Format("Jan")))
+			lastMonth = monday.Month()
+		}
+	}
+	b.WriteString(th.Muted.Render("    "+strings.TrimRight(string(labels), " ")) + "\n")
+
+	for weekday := 0; weekday < 7; weekday++ {
+		label := "   "
+		if weekday%2 == 0 {
+			label = first.AddDate(0, 0, weekday).Format("Mon")
+		}
+		b.WriteString(th.Muted.Render(label) + " ")
+
+		for week := 0; week < service.HeatmapWeeks; week++ {
+			day := first.AddDate(0, 0, 7*week+weekday).Format(time.DateOnly)
+			if day > today {
+				break
+			}
+			b.WriteString(th.HeatCell(heatLevel(byDay[day])) + " ")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n" + th.Muted.Render("    Less") + " ")
+	for level := range heatLevels {
+		b.WriteString(th.HeatCell(level) + " ")
+	}
+	b.WriteString(th.Muted.Render("More") + "\n")
+
+	return b.String()
+}
+
+// heatLevel is the heatmap shade for a day with the given focused seconds.
+func heatLevel(seconds int64) int {
+	focused := time.Duration(seconds) * time.Second
+	for level, below := range heatLevels {
+		if focused < below {
+			return level
+		}
+	}
+	return len(heatLevels) - 1
+}
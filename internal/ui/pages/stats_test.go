@@ -0,0 +1,39 @@
+package pages
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+func seedStats(store *uitest.Store) {
+	store.StatsResult = &service.Stats{
+		TodaySeconds:  1500,
+		WeekSeconds:   9000,
+		MonthSeconds:  21600,
+		TotalSeconds:  90000,
+		Sessions:      42,
+		CurrentStreak: 3,
+		LongestStreak: 9,
+		Completed:     30,
+		Aborted:       10,
+		Daily: []storage.FocusTotal{
+			{Start: "2026-02-16", Seconds: 1200, Sessions: 1},
+			{Start: "2026-03-02", Seconds: 9000, Sessions: 3},
+			{Start: "2026-03-08", Seconds: 3000, Sessions: 1},
+			{Start: "2026-03-09", Seconds: 4500, Sessions: 2},
+			{Start: "2026-03-10", Seconds: 1500, Sessions: 1},
+		},
+	}
+}
+
+// Test stats view - the summary and heatmap
+func TestStatsView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model { return NewStatsModel(env) }, []viewCase{
+		{name: "empty", golden: "stats_empty.golden"},
+		{name: "summary", golden: "stats_summary.golden", setup: seedStats},
+	})
+}
@@ -2,21 +2,371 @@ package pages
 
 import (
 	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/ui/nav"
 )
 
-type TimerModel struct{ elapsed int }
+// timerReload is how many ticks pass between re-reading the session, so a
+// stop from the CLI or another window shows up without hammering the daemon.
+const timerReload = 5
 
-func NewTimerModel() TimerModel { return TimerModel{} }
+// extendStep is how much time the extend key adds.
+const extendStep = 10 * time.Minute
 
-func (m TimerModel) Init() tea.Cmd { return nil }
+type timerKeys struct {
+	NewSession key.Binding
+	Extend     key.Binding
+	Back       key.Binding
+	Help       key.Binding
+	Quit       key.Binding
+	// running is set while a session runs: it can be extended, and a new
+	// timer can't be set.
+	running bool
+}
+
+// action is the key that works in the page's state.
+func (k timerKeys) action() key.Binding {
+	if k.running {
+		return k.Extend
+	}
+	return k.NewSession
+}
+
+func (k timerKeys) ShortHelp() []key.Binding {
+	return []key.Binding{k.action(), k.Back, k.Help}
+}
+
+func (k timerKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.action()},
+		{k.Back, k.Help, k.Quit},
+	}
+}
+
+func newTimerKeys(k Keymap) timerKeys {
+	return timerKeys{
+		NewSession: k.Binding(KeyNewSession, "set a new timer"),
+		Extend:     k.Binding(KeyExtend, "add "+models.FormatDuration(extendStep)),
+		Back:       k.Binding(KeyBack, "back"),
+		Help:       k.Binding(KeyHelp, "toggle help"),
+		Quit:       k.Binding(KeyQuit, "quit"),
+	}
+}
+
+// lastTimerID hands each timer page its own tick chain, so ticks from a page
+// that was closed and reopened don't double up.
+var lastTimerID int64
+
+// timerTickMsg fires once a second for the timer page with the same id.
+type timerTickMsg struct{ id int64 }
+
+// sessionLoadedMsg carries the active session, if any, and the size of the
+// block lists it enforces.
+type sessionLoadedMsg struct {
+	session *models.Session
+	sites   int
+	apps    int
+	err     error
+}
+
+// sessionExtendedMsg carries the session after the extend key was pressed.
+type sessionExtendedMsg struct {
+	session *models.Session
+	err     error
+}
+
+type TimerModel struct {
+	env  *Env
+	keys timerKeys
+	id   int64
+
+	loaded  bool
+	session *models.Session
+	sites   int
+	apps    int
+
+	// finished holds the session once it has run out or been stopped, for the
+	// completion screen.
+	finished *models.Session
+	stopped  bool
+
+	now   time.Time
+	ticks int
+	bar   progress.Model
+	err   string
+	// status and extendErr report the last extend.
+	status    string
+	extendErr string
+}
+
+func NewTimerModel(env *Env) TimerModel {
+	return TimerModel{
+		env:  env,
+		keys: newTimerKeys(env.Keys),
+		id:   atomic.AddInt64(&lastTimerID, 1),
+		now:  env.Now(),
+		bar:  progress.New(env.Theme.ProgressOptions()...),
+	}
+}
+
+func (m TimerModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.tick())
+}
+
+// Keys lists extend while a session runs and setting a new timer while
+// none does, matching what Update acts on.
+func (m TimerModel) Keys() help.KeyMap {
+	keys := m.keys
+	keys.running = m.session != nil
+	return keys
+}
+
+func (m TimerModel) tick() tea.Cmd {
+	id := m.id
+	return m.env.Tick(time.Second, func(time.Time) tea.Msg { return timerTickMsg{id: id} })
+}
+
+func (m TimerModel) load() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		session, err := svc.ActiveSession()
+		if err != nil {
+			return sessionLoadedMsg{err: err}
+		}
+		sites, err := svc.ListBlockedSites()
+		if err != nil {
+			return sessionLoadedMsg{err: err}
+		}
+		apps, err := svc.ListBlockedApps()
+		if err != nil {
+			return sessionLoadedMsg{err: err}
+		}
+		return sessionLoadedMsg{session: session, sites: len(sites), apps: len(apps)}
+	}
+}
+
+func (m TimerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.bar.Width = min(max(msg.Width-8, 10), 60)
+		return m, nil
+
+	case sessionLoadedMsg:
+		m.loaded = true
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		m.err = ""
+		m.sites, m.apps = msg.sites, msg.apps
+		if m.session != nil && msg.session == nil {
+			// It went away before running out, so someone stopped it.
+			m.finish(m.session.RemainingAt(m.now) > 0)
+			return m, nil
+		}
+		m.session = msg.session
+		if m.session != nil {
+			m.finished = nil
+		}
+		return m, nil
+
+	case nav.RevealedMsg:
+		// The tick chain stopped while another page was on top. A new id
+		// keeps a tick still in flight from the old one from doubling it.
+		m.id = atomic.AddInt64(&lastTimerID, 1)
+		m.now = m.env.Now()
+		m.status, m.extendErr = "", ""
+		return m, tea.Batch(m.load(), m.tick())
+
+	case timerTickMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		m.now = m.env.Now()
+		m.ticks++
+
+		var cmds []tea.Cmd
+		if m.session != nil && m.session.RemainingAt(m.now) == 0 {
+			m.finish(false)
+		}
+		if m.ticks%timerReload == 0 {
+			cmds = append(cmds, m.load())
+		}
+		cmds = append(cmds, m.tick())
+		return m, tea.Batch(cmds...)
+
+	case sessionExtendedMsg:
+		if msg.err != nil {
+			m.extendErr = msg.err.Error()
+			return m, nil
+		}
+		if m.session != nil {
+			m.session = msg.session
+			m.status = fmt.Sprintf("Added %s; the session now ends at %s", models.FormatDuration(extendStep),
+				m.session.EndTime().In(m.now.Location()).Format("15:04"))
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		m.status, m.extendErr = "", ""
+		switch {
+		case key.Matches(msg, m.keys.NewSession) && m.session == nil:
+			return m, nav.Push(nav.SetTimer)
+		case key.Matches(msg, m.keys.Extend) && m.session != nil:
+			return m, m.extend()
+		}
+	}
 
-func (m TimerModel) Update(msg tea.Msg) (TimerModel, tea.Cmd) {
-	// placeholder for timer updates
 	return m, nil
 }
 
+func (m TimerModel) extend() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		session, err := svc.ExtendSession(extendStep)
+		return sessionExtendedMsg{session: session, err: err}
+	}
+}
+
+func (m *TimerModel) finish(stopped bool) {
+	m.finished = m.session
+	m.session = nil
+	m.stopped = stopped
+}
+
 func (m TimerModel) View() string {
-	return fmt.Sprintf("⏱ Timer Page\n\nElapsed: %d\n\nPress q → Quit", m.elapsed)
+	th := m.env.Theme
+	var b strings.Builder
+
+	b.WriteString(th.Header("⏱", "Focus Session"))
+
+	switch {
+	case m.err != "":
+		b.WriteString(th.Err(m.err) + "\n")
+	case m.session != nil:
+		m.viewRunning(&b)
+	case m.finished != nil:
+		m.viewFinished(&b)
+	case m.loaded:
+		b.WriteString(fmt.Sprintf("No session is running.\n\nPress %s to set a timer.\n", keyName(m.keys.NewSession)))
+	default:
+		b.WriteString(th.Muted.Render("Loading…") + "\n")
+	}
+
+	return b.String()
+}
+
+func (m TimerModel) viewRunning(b *strings.Builder) {
+	s := m.session
+	remaining := time.Duration(s.RemainingAt(m.now)) * time.Second
+	total := time.Duration(s.DurationSeconds) * time.Second
+
+	b.WriteString(m.env.Theme.Title.Render(bigClock(remaining)) + "\n\n")
+
+	done := 1.0
+	if total > 0 {
+		done = 1 - float64(remaining)/float64(total)
+	}
+	b.WriteString(m.bar.ViewAs(done) + "\n\n")
+
+	profile := s.Profile
+	if profile == "" {
+		profile = "none"
+	}
+	phase := s.Phase(m.now)
+	if s.Strict {
+		phase += " (strict)"
+	}
+	b.WriteString(fmt.Sprintf("Profile:  %s\n", profile))
+	b.WriteString(fmt.Sprintf("Phase:    %s\n", phase))
+	b.WriteString(fmt.Sprintf("Ends at:  %s\n", s.EndTime().In(m.now.Location()).Format("15:04")))
+	// Only sites are enforced; apps are listed but nothing stops them yet.
+	blocking := plural(m.sites, "site")
+	if m.apps > 0 {
+		blocking += fmt.Sprintf("; %s listed (not enforced)", plural(m.apps, "app"))
+	}
+	b.WriteString(fmt.Sprintf("Blocking: %s\n", blocking))
+
+	if m.extendErr != "" {
+		b.WriteString("\n" + m.env.Theme.Err(m.extendErr) + "\n")
+	} else if m.status != "" {
+		b.WriteString("\n" + m.env.Theme.OK(m.status) + "\n")
+	}
+}
+
+func (m TimerModel) viewFinished(b *strings.Builder) {
+	th := m.env.Theme
+	s := m.finished
+	if m.stopped {
+		focused := time.Duration(m.now.Unix()-s.StartTime) * time.Second
+		b.WriteString(th.Warning.Render(th.Icon("⏹ ", "")+"Session stopped early.") + "\n\n")
+		b.WriteString(fmt.Sprintf("You focused for %s of %s.\n", models.FormatDuration(focused), models.FormatDuration(time.Duration(s.DurationSeconds)*time.Second)))
+	} else {
+		b.WriteString(th.Success.Render(th.Icon("✅ ", "")+"Session complete!") + "\n\n")
+		b.WriteString(fmt.Sprintf("You focused for %s. Sites are unblocked.\n", models.FormatDuration(time.Duration(s.DurationSeconds)*time.Second)))
+	}
+	b.WriteString("\n" + th.Muted.Render(fmt.Sprintf("Press %s to start another, or %s to go back.", keyName(m.keys.NewSession), keyName(m.keys.Back))) + "\n")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// clockText formats d as H:MM:SS, or M:SS under an hour.
+func clockText(d time.Duration) string {
+	d = max(d, 0).Round(time.Second)
+	h := int(d / time.Hour)
+	mins := int(d%time.Hour) / int(time.Minute)
+	secs := int(d%time.Minute) / int(time.Second)
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, mins, secs)
+	}
+	return fmt.Sprintf("%d:%02d", mins, secs)
+}
+
+// bigDigits is a five-row block font for the countdown.
+var bigDigits = map[rune][5]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {" █ ", "██ ", " █ ", " █ ", "███"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", "███", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", "  █", "  █", "  █"},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	':': {" ", "█", " ", "█", " "},
+}
+
+// bigClock renders d in the block font, one line per font row.
+func bigClock(d time.Duration) string {
+	var rows [5]strings.Builder
+	for i, r := range clockText(d) {
+		glyph := bigDigits[r]
+		for row := range rows {
+			if i > 0 {
+				rows[row].WriteString(" ")
+			}
+			rows[row].WriteString(glyph[row])
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i := range rows {
+		lines[i] = "  " + rows[i].String()
+	}
+	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,50 @@
+package pages
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+// startWork puts a 50 minute "work" session in the store that started ten
+// minutes before testNow.
+func startWork(store *uitest.Store) {
+	seedSites(store)
+	seedApps(store)
+	store.AddSession(models.Session{
+		StartTime:       testNow.Unix() - 600,
+		DurationSeconds: 3000,
+		Active:          true,
+		Profile:         "work",
+	})
+}
+
+// tick advances the clock, firing the page's timer ticks on the way.
+func tick(d *uitest.Driver, clock *uitest.Clock, by time.Duration) {
+	d.Advance(clock, by)
+}
+
+// Test timer view - the countdown follows the clock through to completion
+func TestTimerView(t *testing.T) {
+	runViewCases(t, func(env *Env) tea.Model { return NewTimerModel(env) }, []viewCase{
+		{name: "no session", golden: "timer_none.golden"},
+		{name: "running", golden: "timer_running.golden", setup: startWork},
+		{name: "later", golden: "timer_later.golden", setup: startWork,
+			steps: func(d *uitest.Driver, clock *uitest.Clock) { tick(d, clock, 25*time.Minute) }},
+		{name: "strict", golden: "timer_strict.golden", setup: startStrict},
+		{name: "complete", golden: "timer_complete.golden", setup: startWork,
+			steps: func(d *uitest.Driver, clock *uitest.Clock) { tick(d, clock, 41*time.Minute) }},
+		{name: "extended", golden: "timer_extended.golden", setup: startWork,
+			steps: func(d *uitest.Driver, clock *uitest.Clock) { d.Press("+", "+") }},
+		{name: "strict extended", golden: "timer_strict_extended.golden", setup: startStrict,
+			steps: func(d *uitest.Driver, clock *uitest.Clock) { d.Press("+") }},
+		{name: "extended too far", golden: "timer_extend_limit.golden",
+			setup: func(store *uitest.Store) {
+				store.AddSession(models.Session{StartTime: testNow.Unix() - 600, DurationSeconds: 12 * 3600, Active: true})
+			},
+			steps: func(d *uitest.Driver, clock *uitest.Clock) { d.Press("+") }},
+	})
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/ui/pages"
+)
+
+// quitCheckMsg carries the running session, if any, when the user asks to
+// quit.
+type quitCheckMsg struct {
+	session *models.Session
+	err     error
+}
+
+// enforcerStartedMsg reports whether lockind came up in the background.
+type enforcerStartedMsg struct{ err error }
+
+// quitDialog asks before leaving the UI while a session is running, and
+// explains what keeps blocking once it is gone. Quitting never ends the
+// session: a strict one can't be cut short, so without lockind it keeps
+// running unenforced until lockind starts.
+type quitDialog struct {
+	session *models.Session
+	// daemon is set when lockind is enforcing the session already.
+	daemon   bool
+	starting bool
+	err      string
+}
+
+type quitKeys struct {
+	Quit       key.Binding
+	Background key.Binding
+	Stay       key.Binding
+	dialog     *quitDialog
+}
+
+func newQuitKeys(k pages.Keymap) quitKeys {
+	return quitKeys{
+		Quit:       k.Binding(pages.KeyConfirm, "quit"),
+		Background: k.Binding(pages.KeyBackground, "start lockind and quit"),
+		Stay:       k.Binding(pages.KeyDeny, "stay"),
+	}
+}
+
+func (k quitKeys) ShortHelp() []key.Binding {
+	var bindings []key.Binding
+	if !k.dialog.daemon {
+		bindings = append(bindings, k.Background)
+	}
+	return append(bindings, k.Quit, k.Stay)
+}
+
+func (k quitKeys) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// checkQuit looks up the running session before quitting; with none, the
+// UI simply exits.
+func (m *RootModel) checkQuit() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		session, err := svc.ActiveSession()
+		return quitCheckMsg{session: session, err: err}
+	}
+}
+
+func (m *RootModel) openQuit(msg quitCheckMsg) tea.Cmd {
+	if msg.err != nil || msg.session == nil || msg.session.RemainingAt(m.env.Now()) == 0 {
+		return tea.Quit
+	}
+	_, local := m.env.Service.(*service.Local)
+	m.quit = &quitDialog{session: msg.session, daemon: !local}
+	return nil
+}
+
+func (m *RootModel) updateQuit(msg tea.Msg) tea.Cmd {
+	d := m.quit
+	keys := m.quitKeys()
+
+	switch msg := msg.(type) {
+	case enforcerStartedMsg:
+		d.starting = false
+		if msg.err != nil {
+			d.err = msg.err.Error()
+			return nil
+		}
+		return tea.Quit
+
+	case tea.KeyMsg:
+		// ctrl+c always gets out: once the dialog is open it confirms
+		// quitting, whatever else is going on.
+		if key.Matches(msg, pages.ForceQuit) {
+			return tea.Quit
+		}
+		if d.starting {
+			return nil
+		}
+		switch {
+		case key.Matches(msg, keys.Quit):
+			return tea.Quit
+		case key.Matches(msg, keys.Background) && !d.daemon:
+			d.starting = true
+			d.err = ""
+			start := m.env.StartEnforcer
+			return func() tea.Msg {
+				if start == nil {
+					return enforcerStartedMsg{err: fmt.Errorf("can't start lockind from here")}
+				}
+				_, err := start()
+				return enforcerStartedMsg{err: err}
+			}
+		case key.Matches(msg, keys.Stay):
+			m.quit = nil
+		}
+	}
+	return nil
+}
+
+func (m *RootModel) quitKeys() quitKeys {
+	keys := newQuitKeys(m.env.Keys)
+	keys.dialog = m.quit
+	return keys
+}
+
+func (m *RootModel) viewQuit() string {
+	d := m.quit
+	th := m.env.Theme
+	keys := m.quitKeys()
+	label := func(b key.Binding) string { return b.Help().Key }
+
+	kind := "A session"
+	if d.session.Strict {
+		kind = "A strict session"
+	}
+	end := d.session.EndTime().In(m.env.Now().Location()).Format("15:04")
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%s is running until %s.", kind, end))
+	switch {
+	case d.daemon:
+		lines = append(lines,
+			"lockind keeps blocking after you quit, until the session ends.",
+			"",
+			fmt.Sprintf("Quit? (%s/%s)", label(keys.Quit), label(keys.Stay)))
+	case d.session.Strict:
+		lines = append(lines,
+			"lockind isn't running, so nothing blocks once you quit. The session",
+			"keeps running anyway, since a strict session can't be ended early.",
+			"",
+			fmt.Sprintf("Press %s to start lockind in the background and quit,", label(keys.Background)),
+			fmt.Sprintf("%s to quit anyway, or %s to stay.", label(keys.Quit), label(keys.Stay)))
+	default:
+		lines = append(lines,
+			"lockind isn't running, so nothing enforces the session once you quit.",
+			"",
+			fmt.Sprintf("Press %s to start lockind in the background and quit,", label(keys.Background)),
+			fmt.Sprintf("%s to quit anyway, or %s to stay.", label(keys.Quit), label(keys.Stay)))
+	}
+
+	var b strings.Builder
+	b.WriteString(th.Header("🚪", "Quit LockIn"))
+	b.WriteString(th.Notice(strings.Join(lines, "\n")) + "\n\n")
+	if d.starting {
+		b.WriteString(th.Muted.Render("Starting lockind…") + "\n")
+	}
+	if d.err != "" {
+		b.WriteString(th.Err(d.err) + "\n")
+	}
+	return b.String()
+}
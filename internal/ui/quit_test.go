@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+// localRoot opens the UI as if no daemon were running, with the quit dialog
+// already asked for session. A session like it runs in a real database, so
+// it can be checked to still run after quitting; the dialog is shown the test's session so its
+// view doesn't depend on the wall clock.
+func localRoot(t *testing.T, session *models.Session, startErr error, started *bool) (*uitest.Driver, *sql.DB) {
+	store := uitest.NewStore(uitest.NewClock(testNow))
+	d := newTestRoot(t, store)
+
+	db, err := storage.Open(filepath.Join(t.TempDir(), "local.db"))
+	if err == nil {
+		err = storage.InitSchema(db)
+	}
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if session != nil {
+		_, err = service.StartSession(db, service.StartOptions{
+			Duration: time.Duration(session.DurationSeconds) * time.Second,
+			Strict:   session.Strict,
+		})
+		if err != nil {
+			t.Fatalf("Failed to start session: %v", err)
+		}
+	}
+
+	root := d.Model.(*RootModel)
+	root.env.Service = service.NewLocal(db)
+	root.env.StartEnforcer = func() (service.Service, error) {
+		*started = true
+		return store, startErr
+	}
+	return d.Send(quitCheckMsg{session: session}), db
+}
+
+// Test quit dialog - quitting mid-session asks first, and never ends the
+// session, not even a strict one left without lockind
+func TestQuitDialog(t *testing.T) {
+	running := &models.Session{StartTime: testNow.Unix(), DurationSeconds: 3600, Active: true}
+	strict := &models.Session{StartTime: testNow.Unix(), DurationSeconds: 3600, Active: true, Strict: true}
+
+	tests := []struct {
+		name     string
+		local    bool
+		session  *models.Session
+		startErr error
+		keys     []string
+		wantQuit bool
+		wantOpen bool
+		started  bool
+	}{
+		{"no session quits", false, nil, nil, []string{"q"}, true, false, false},
+		{"session asks", false, running, nil, []string{"q"}, false, true, false},
+		{"daemon quit confirmed", false, running, nil, []string{"q", "y"}, true, false, false},
+		{"stay", false, running, nil, []string{"q", "n"}, false, false, false},
+		{"ctrl+c asks too", false, running, nil, []string{"ctrl+c"}, false, true, false},
+		{"local quit anyway", true, running, nil, []string{"y"}, true, false, false},
+		{"local stay", true, running, nil, []string{"n"}, false, false, false},
+		{"local background", true, running, nil, []string{"b"}, true, false, true},
+		{"local background fails", true, running, errors.New("no lockind"), []string{"b"}, false, true, true},
+		{"strict quit leaves it running", true, strict, nil, []string{"y"}, true, false, false},
+		{"strict ctrl+c leaves it running", true, strict, nil, []string{"ctrl+c"}, true, false, false},
+		{"strict stay", true, strict, nil, []string{"n"}, false, false, false},
+		{"strict background", true, strict, nil, []string{"b"}, true, false, true},
+		{"ctrl+c after lockind fails", true, strict, errors.New("no lockind"), []string{"b", "ctrl+c"}, true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := false
+			var d *uitest.Driver
+			var db *sql.DB
+			if tt.local {
+				d, db = localRoot(t, tt.session, tt.startErr, &started)
+			} else {
+				store := uitest.NewStore(uitest.NewClock(testNow))
+				if tt.session != nil {
+					store.AddSession(*tt.session)
+				}
+				d = newTestRoot(t, store)
+			}
+			d.Press(tt.keys...)
+
+			if d.Quit != tt.wantQuit {
+				t.Errorf("quit = %v, want %v", d.Quit, tt.wantQuit)
+			}
+			if open := d.Model.(*RootModel).quit != nil; open != tt.wantOpen && !d.Quit {
+				t.Errorf("dialog open = %v, want %v", open, tt.wantOpen)
+			}
+			if started != tt.started {
+				t.Errorf("enforcer started = %v, want %v", started, tt.started)
+			}
+			if db == nil {
+				return
+			}
+			if active, _ := service.ActiveSession(db); active == nil {
+				t.Error("the session ended, want it still running")
+			}
+		})
+	}
+}
+
+// Test quit dialog view - what the dialog says without a daemon
+func TestQuitDialogView(t *testing.T) {
+	tests := []struct {
+		name    string
+		golden  string
+		session *models.Session
+		keys    []string
+	}{
+		{"local", "quit_local.golden", &models.Session{StartTime: testNow.Unix(), DurationSeconds: 3600, Active: true}, nil},
+		{"local strict", "quit_local_strict.golden", &models.Session{StartTime: testNow.Unix(), DurationSeconds: 3600, Active: true, Strict: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := false
+			d, _ := localRoot(t, tt.session, nil, &started)
+			d.Press(tt.keys...)
+			checkGolden(t, tt.golden, d.View())
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/ui/pages"
+)
+
+// pageSpec describes one page. The registry below is the only place pages
+// are listed; the root model and the home menu are both driven from it.
+type pageSpec struct {
+	page  Page
+	title string
+	// inMenu lists the page on the home screen.
+	inMenu bool
+	new    func(env *pages.Env) tea.Model
+}
+
+var registry = []pageSpec{
+	{page: HomePage, title: "Home", new: func(env *pages.Env) tea.Model { return pages.NewHomeModel(env) }},
+	{page: BlockSitesPage, title: "Add website to block list", inMenu: true, new: func(env *pages.Env) tea.Model { return pages.NewBlockSitesModel(env) }},
+	{page: BlockAppsPage, title: "Blocked applications", inMenu: true, new: func(env *pages.Env) tea.Model { return pages.NewBlockAppsModel(env) }},
+	{page: SetTimerPage, title: "Set Timer", inMenu: true, new: func(env *pages.Env) tea.Model { return pages.NewSetTimerModel(env) }},
+	{page: TimerPage, title: "Current session", inMenu: true, new: func(env *pages.Env) tea.Model { return pages.NewTimerModel(env) }},
+	{page: HistoryPage, title: "Session history", inMenu: true, new: func(env *pages.Env) tea.Model { return pages.NewHistoryModel(env) }},
+	{page: StatsPage, title: "Focus stats", inMenu: true, new: func(env *pages.Env) tea.Model { return pages.NewStatsModel(env) }},
+}
+
+func lookup(page Page) (pageSpec, bool) {
+	for _, spec := range registry {
+		if spec.page == page {
+			return spec, true
+		}
+	}
+	return pageSpec{}, false
+}
+
+func menuItems() []pages.MenuItem {
+	var items []pages.MenuItem
+	for _, spec := range registry {
+		if spec.inMenu {
+			items = append(items, pages.MenuItem{Title: spec.title, Page: spec.page})
+		}
+	}
+	return items
+}
@@ -2,160 +2,291 @@ package ui
 
 import (
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/daemon"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/ui/nav"
 	"github.com/youssef28m/LockIn/internal/ui/pages"
+	"github.com/youssef28m/LockIn/internal/ui/theme"
 )
 
-type Page int
+type Page = nav.Page
 
 const (
-    HomePage Page = iota
-    SetTimerPage
-    TimerPage
-    BlockSitesPage
+	HomePage       = nav.Home
+	SetTimerPage   = nav.SetTimer
+	TimerPage      = nav.Timer
+	BlockSitesPage = nav.BlockSites
+	BlockAppsPage  = nav.BlockApps
+	HistoryPage    = nav.History
+	StatsPage      = nav.Stats
 )
 
-type NavigateMsg Page
+// NavigateMsg pushes a page onto the navigation stack.
+type NavigateMsg = nav.PushMsg
 
 // globalKeys holds keybindings that work on every page.
 type globalKeys struct {
+	Back key.Binding
 	Help key.Binding
 	Quit key.Binding
 }
 
 func (k globalKeys) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Quit}
+	return []key.Binding{k.Back, k.Help, k.Quit}
 }
 
 func (k globalKeys) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Help, k.Quit},
+		{k.Back, k.Help, k.Quit},
 	}
 }
 
-var gKeys = globalKeys{
-	Help: key.NewBinding(
-		key.WithKeys("?"),
-		key.WithHelp("?", "toggle help"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
-	),
+func newGlobalKeys(k pages.Keymap) globalKeys {
+	return globalKeys{
+		Back: k.Binding(pages.KeyBack, "back"),
+		Help: k.Binding(pages.KeyHelp, "toggle help"),
+		Quit: k.Binding(pages.KeyQuit, "quit"),
+	}
 }
 
-// PageKeys is an interface each page model can optionally implement
-// to expose its own keybindings to the root help footer.
+// PageKeys is implemented by every page to expose its keybindings to the
+// root help footer. Pages build them from the shared keymap and handle keys
+// with the same bindings, so the footer always lists what works.
 type PageKeys interface {
 	Keys() help.KeyMap
 }
 
+// InputFocuser is implemented by pages with text inputs. While it reports
+// true, keys go straight to the page instead of triggering the global
+// shortcuts; only ctrl+c still quits.
+type InputFocuser interface {
+	InputFocused() bool
+}
+
+// stackEntry is one open page. The bottom of the stack is always Home.
+type stackEntry struct {
+	page  Page
+	model tea.Model
+}
+
 type RootModel struct {
-    page       Page
-    home       pages.HomeModel
-    setTimer   pages.SetTimerModel
-    timer      pages.TimerModel
-    blockSites pages.BlockSitesModel
-    help       help.Model
-    width      int
-	height     int
+	env    *pages.Env
+	keys   globalKeys
+	stack  []stackEntry
+	help   help.Model
+	width  int
+	height int
+
+	// quit is the open quit dialog, if any.
+	quit *quitDialog
+	// auditWarning is shown above every page when the event log failed
+	// its check at startup.
+	auditWarning string
 }
 
-func NewRootModel() *RootModel {
-    return &RootModel{
-        page:       HomePage,
-        home:       pages.NewHomeModel(),
-        setTimer:   pages.NewSetTimerModel(),
-        timer:      pages.NewTimerModel(),
-        blockSites: pages.NewBlockSitesModel(),
-        help:       help.New(),
-    }
+// Options are the settings the UI takes from the config file.
+type Options struct {
+	Theme theme.Theme
+	Keys  pages.Keymap
+	// DefaultDuration is the session length the timer page starts on.
+	DefaultDuration time.Duration
 }
 
-func (m *RootModel) Init() tea.Cmd { return nil }
+func NewRootModel(svc service.Service, opts Options) *RootModel {
+	return newRootModel(&pages.Env{
+		Service:         svc,
+		Processes:       blocker.RunningProcesses,
+		Now:             time.Now,
+		Tick:            tea.Tick,
+		Theme:           opts.Theme,
+		Keys:            opts.Keys,
+		DefaultDuration: opts.DefaultDuration,
+		StartEnforcer:   startDaemon,
+	})
+}
+
+// startDaemon starts lockind in the background and connects to it.
+func startDaemon() (service.Service, error) {
+	err := daemon.StartBackground()
+	if err != nil {
+		return nil, err
+	}
+	return daemon.NewClient(daemon.SocketPath()), nil
+}
+
+// newRootModel opens the home page with env, whose menu it fills in from
+// the registry. Tests call it with a fake clock and store.
+func newRootModel(env *pages.Env) *RootModel {
+	env.Menu = menuItems()
+	m := &RootModel{
+		env:  env,
+		keys: newGlobalKeys(env.Keys),
+		help: help.New(),
+	}
+	m.help.Styles = env.Theme.HelpStyles()
+	m.push(HomePage)
+	return m
+}
+
+func (m *RootModel) Init() tea.Cmd {
+	return tea.Batch(m.top().model.Init(), m.checkAudit())
+}
+
+// auditMsg carries the event log check run when the UI opens.
+type auditMsg struct{ problem string }
+
+// checkAudit verifies the event log in the background. Errors are dropped:
+// the warning is for logs that were edited, not for a daemon that is slow
+// to answer.
+func (m *RootModel) checkAudit() tea.Cmd {
+	svc := m.env.Service
+	return func() tea.Msg {
+		report, err := svc.VerifyAudit()
+		if err != nil || report.OK() {
+			return nil
+		}
+		return auditMsg{problem: report.Problem}
+	}
+}
+
+func (m *RootModel) top() *stackEntry { return &m.stack[len(m.stack)-1] }
+
+// push opens page on top of the stack and returns its Init command. The
+// new page is told the current window size straight away.
+func (m *RootModel) push(page Page) tea.Cmd {
+	spec, ok := lookup(page)
+	if !ok {
+		return nil
+	}
+
+	model := spec.new(m.env)
+	if m.width > 0 {
+		model, _ = model.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	}
+	m.stack = append(m.stack, stackEntry{page: page, model: model})
+	return model.Init()
+}
+
+// pop closes the top page and returns the command the page below it
+// gives back on being revealed.
+func (m *RootModel) pop() tea.Cmd {
+	if len(m.stack) <= 1 {
+		return nil
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	return m.reveal()
+}
+
+func (m *RootModel) reveal() tea.Cmd {
+	var cmd tea.Cmd
+	top := m.top()
+	top.model, cmd = top.model.Update(nav.RevealedMsg{})
+	return cmd
+}
 
 func (m *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-    
-    if key, ok := msg.(tea.KeyMsg); ok {
-		switch key.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "?":
+
+	// The quit dialog takes the keyboard while it is open; everything else
+	// still reaches the page so timers keep ticking behind it.
+	if m.quit != nil {
+		switch msg.(type) {
+		case tea.KeyMsg, enforcerStartedMsg:
+			return m, m.updateQuit(msg)
+		}
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, pages.ForceQuit) {
+		return m, m.checkQuit()
+	} else if ok && !m.inputFocused() {
+		switch {
+		case key.Matches(keyMsg, m.keys.Quit):
+			return m, m.checkQuit()
+		case key.Matches(keyMsg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
+			return m, nil
+		case key.Matches(keyMsg, m.keys.Back) && len(m.stack) > 1:
+			return m, m.pop()
 		}
 	}
 
-    switch msg := msg.(type) {   
- 
-        case NavigateMsg:
-            m.page = Page(msg)
-            return m, nil
+	switch msg := msg.(type) {
+
+	case quitCheckMsg:
+		return m, m.openQuit(msg)
+
+	case nav.PushMsg:
+		return m, m.push(msg.Page)
 
-        case tea.WindowSizeMsg:
-		    m.width = msg.Width
-            m.height = msg.Height
-            m.help.Width = msg.Width
-    }
+	case nav.ReplaceMsg:
+		// Replacing a page with the one below it, like setting a timer
+		// opened from the timer page, goes back to that page rather than
+		// stacking a second copy.
+		if len(m.stack) > 1 && m.stack[len(m.stack)-2].page == msg.Page {
+			return m, m.pop()
+		}
+		if len(m.stack) > 1 {
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+		return m, m.push(msg.Page)
+
+	case nav.PopMsg:
+		return m, m.pop()
 
-    var cmd tea.Cmd
-    switch m.page {
-    case HomePage:
-        m.home, cmd = m.home.Update(msg)
-    case SetTimerPage:
-        m.setTimer, cmd = m.setTimer.Update(msg)
-    case TimerPage:
-        m.timer, cmd = m.timer.Update(msg)
-    case BlockSitesPage:
-        m.blockSites, cmd = m.blockSites.Update(msg)
-    }
+	case auditMsg:
+		m.auditWarning = "Audit log check failed: " + msg.problem + ". Run lockin audit verify for details."
+		return m, nil
 
-    return m, cmd
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+	}
+
+	var cmd tea.Cmd
+	top := m.top()
+	top.model, cmd = top.model.Update(msg)
+	return m, cmd
 }
 
+func (m *RootModel) inputFocused() bool {
+	f, ok := m.top().model.(InputFocuser)
+	return ok && f.InputFocused()
+}
 
 // currentPageKeys returns the active page's key.Map if it implements PageKeys,
 // otherwise falls back to just the global keys.
 func (m *RootModel) currentPageKeys() help.KeyMap {
-	var pageModel any
-	switch m.page {
-	case HomePage:
-		pageModel = m.home
-	case SetTimerPage:
-		pageModel = m.setTimer
-	case TimerPage:
-		pageModel = m.timer
-	case BlockSitesPage:
-		pageModel = m.blockSites
-	}
-
-	if pk, ok := pageModel.(PageKeys); ok {
+	if m.quit != nil {
+		return m.quitKeys()
+	}
+	if pk, ok := m.top().model.(PageKeys); ok {
 		return pk.Keys()
 	}
-	return gKeys
+	return m.keys
 }
 
 func (m *RootModel) View() string {
 
-     var pageView string
-
-    switch m.page {
-    case HomePage:
-        pageView = m.home.View()
-    case SetTimerPage:
-        pageView = m.setTimer.View()
-    case TimerPage:
-        pageView = m.timer.View()
-    case BlockSitesPage:
-        pageView = m.blockSites.View()
-    }
-
-    
-    helpView := m.help.View(m.currentPageKeys())
-	
-    // Pin help to the bottom by filling the gap with newlines
+	pageView := m.top().model.View()
+	if m.quit != nil {
+		pageView = m.viewQuit()
+	}
+	if m.auditWarning != "" {
+		warning := m.env.Theme.Warning
+		if m.width > 0 {
+			warning = warning.Width(m.width)
+		}
+		pageView = warning.Render(m.auditWarning) + "\n" + pageView
+	}
+	helpView := m.help.View(m.currentPageKeys())
+
+	// Pin help to the bottom by filling the gap with newlines
 	pageLines := strings.Count(pageView, "\n") + 1
 	helpLines := strings.Count(helpView, "\n") + 1
 	gap := m.height - pageLines - helpLines
@@ -163,6 +294,5 @@ func (m *RootModel) View() string {
 		gap = 1
 	}
 
-    return pageView + strings.Repeat("\n", gap) + helpView
+	return pageView + strings.Repeat("\n", gap) + helpView
 }
-
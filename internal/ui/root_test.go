@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/youssef28m/LockIn/internal/blocker"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/storage"
+	"github.com/youssef28m/LockIn/internal/ui/pages"
+	"github.com/youssef28m/LockIn/internal/ui/theme"
+	"github.com/youssef28m/LockIn/internal/ui/uitest"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+var testNow = time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	// Snapshots are plain text whatever terminal the tests run in.
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+// checkGolden compares got with testdata/name, or rewrites it under -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		err := os.MkdirAll("testdata", 0755)
+		if err != nil {
+			t.Fatalf("Failed to create testdata: %v", err)
+		}
+		err = os.WriteFile(path, []byte(got), 0644)
+		if err != nil {
+			t.Fatalf("Failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match golden file.\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// newTestRoot opens the UI on an in-memory store with its clock at testNow.
+func newTestRoot(t *testing.T, store *uitest.Store) *uitest.Driver {
+	clock := store.Clock
+	root := newRootModel(&pages.Env{
+		Service:      store,
+		Processes:    func() ([]blocker.Process, error) { return nil, nil },
+		Now:          clock.Now,
+		Tick:         clock.Tick,
+		StaticCursor: true,
+		Theme:        theme.Default(),
+	})
+	return uitest.New(t, root).Size(80, 24)
+}
+
+func runningSession(store *uitest.Store) {
+	store.AddSession(models.Session{
+		StartTime:       testNow.Unix() - 600,
+		DurationSeconds: 3000,
+		Active:          true,
+	})
+}
+
+// Test root view - navigation, help, validation and quitting, snapshotted
+// with the help footer pinned to the bottom of the window
+func TestRootView(t *testing.T) {
+	tests := []struct {
+		name     string
+		golden   string
+		setup    func(store *uitest.Store)
+		steps    func(d *uitest.Driver)
+		wantQuit bool
+	}{
+		{name: "home", golden: "root_home.golden",
+			steps: func(d *uitest.Driver) {}},
+		{name: "help", golden: "root_help.golden",
+			steps: func(d *uitest.Driver) { d.Press("?") }},
+		{name: "open page", golden: "root_block_apps.golden",
+			steps: func(d *uitest.Driver) { d.Press("j", "enter") }},
+		{name: "page help", golden: "root_block_apps_help.golden",
+			steps: func(d *uitest.Driver) { d.Press("j", "enter", "?") }},
+		{name: "back", golden: "root_back.golden",
+			steps: func(d *uitest.Driver) { d.Press("j", "j", "enter", "esc") }},
+		{name: "validation", golden: "root_invalid_site.golden",
+			steps: func(d *uitest.Driver) { d.Press("enter", "a").Type("not a domain").Press("enter") }},
+		{name: "typing q", golden: "root_typing_q.golden",
+			steps: func(d *uitest.Driver) { d.Press("enter", "a", "q") }},
+		{name: "start session", golden: "root_started.golden",
+			steps: func(d *uitest.Driver) { d.Press("j", "j", "enter", "right", "enter") }},
+		{name: "timer idle", golden: "root_timer_idle.golden",
+			steps: func(d *uitest.Driver) { d.Press("j", "j", "j", "enter") }},
+		{name: "quit mid-session", golden: "root_quit.golden", setup: runningSession,
+			steps: func(d *uitest.Driver) { d.Press("q") }},
+		{name: "quit idle", golden: "root_home.golden",
+			steps: func(d *uitest.Driver) { d.Press("q") }, wantQuit: true},
+		{name: "audit warning", golden: "root_audit_warning.golden",
+			setup: func(store *uitest.Store) {
+				store.Audit = &storage.AuditReport{Events: 4, Problem: "session 2 was removed outside LockIn", SessionID: 2}
+			},
+			steps: func(d *uitest.Driver) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := uitest.NewStore(uitest.NewClock(testNow))
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+
+			d := newTestRoot(t, store)
+			tt.steps(d)
+
+			if d.Quit != tt.wantQuit {
+				t.Errorf("quit = %v, want %v", d.Quit, tt.wantQuit)
+			}
+			checkGolden(t, tt.golden, d.View())
+		})
+	}
+}
+
+// Test timer revealed - a timer page covered by the set timer page picks
+// its session up again once it is back on top, whether the session was
+// started from the page above or elsewhere
+func TestRootTimerRevealed(t *testing.T) {
+	tests := []struct {
+		name   string
+		golden string
+		steps  func(d *uitest.Driver, store *uitest.Store)
+	}{
+		{name: "started above", golden: "root_timer_replaced.golden",
+			steps: func(d *uitest.Driver, store *uitest.Store) {
+				d.Press("s").Advance(store.Clock, 2*time.Second).Press("enter")
+			}},
+		{name: "started elsewhere", golden: "root_timer_back.golden",
+			steps: func(d *uitest.Driver, store *uitest.Store) {
+				d.Press("s").Advance(store.Clock, 2*time.Second).Press("esc")
+				runningSession(store)
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := uitest.NewStore(uitest.NewClock(testNow))
+			d := newTestRoot(t, store)
+			d.Press("j", "j", "j", "enter")
+			tt.steps(d, store)
+			// The timer page re-reads the session every five ticks.
+			d.Advance(store.Clock, 5*time.Second)
+
+			checkGolden(t, tt.golden, d.View())
+			d.Press("esc")
+			if !strings.Contains(d.View(), "Current session") {
+				t.Errorf("back from the timer didn't reach home:\n%s", d.View())
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Package theme holds the colours and decorations the TUI pages draw with,
+// so a page never hard-codes a colour or an emoji itself.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names accepted in the config file.
+const (
+	Auto         = "auto"
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+)
+
+// Names lists the themes that can be asked for by name.
+var Names = []string{Auto, Dark, Light, HighContrast}
+
+// palette is the set of colours a theme is built from.
+type palette struct {
+	accent  lipgloss.TerminalColor
+	text    lipgloss.TerminalColor
+	muted   lipgloss.TerminalColor
+	success lipgloss.TerminalColor
+	warning lipgloss.TerminalColor
+	err     lipgloss.TerminalColor
+	// heat runs from no focus to the most focus.
+	heat   [HeatLevels]lipgloss.TerminalColor
+	border lipgloss.Border
+}
+
+// HeatLevels is how many shades the stats heatmap uses.
+const HeatLevels = 5
+
+var palettes = map[string]palette{
+	Dark: {
+		accent:  lipgloss.Color("#A78BFA"),
+		text:    lipgloss.Color("#E5E7EB"),
+		muted:   lipgloss.Color("#6B7280"),
+		success: lipgloss.Color("#4ADE80"),
+		warning: lipgloss.Color("#FBBF24"),
+		err:     lipgloss.Color("#F87171"),
+		heat: [HeatLevels]lipgloss.TerminalColor{
+			lipgloss.Color("#2D333B"), lipgloss.Color("#0E4429"), lipgloss.Color("#006D32"),
+			lipgloss.Color("#26A641"), lipgloss.Color("#39D353"),
+		},
+		border: lipgloss.RoundedBorder(),
+	},
+	Light: {
+		accent:  lipgloss.Color("#6D28D9"),
+		text:    lipgloss.Color("#1F2937"),
+		muted:   lipgloss.Color("#6B7280"),
+		success: lipgloss.Color("#15803D"),
+		warning: lipgloss.Color("#B45309"),
+		err:     lipgloss.Color("#B91C1C"),
+		heat: [HeatLevels]lipgloss.TerminalColor{
+			lipgloss.Color("#EBEDF0"), lipgloss.Color("#9BE9A8"), lipgloss.Color("#40C463"),
+			lipgloss.Color("#30A14E"), lipgloss.Color("#216E39"),
+		},
+		border: lipgloss.RoundedBorder(),
+	},
+	// High contrast sticks to the basic ANSI colours, which terminals map to
+	// the user's own accessible palette.
+	HighContrast: {
+		accent:  lipgloss.Color("11"),
+		text:    lipgloss.Color("15"),
+		muted:   lipgloss.Color("7"),
+		success: lipgloss.Color("10"),
+		warning: lipgloss.Color("11"),
+		err:     lipgloss.Color("9"),
+		heat: [HeatLevels]lipgloss.TerminalColor{
+			lipgloss.Color("8"), lipgloss.Color("4"), lipgloss.Color("6"),
+			lipgloss.Color("14"), lipgloss.Color("15"),
+		},
+		border: lipgloss.ThickBorder(),
+	},
+}
+
+// monoHeat stands in for heat colours when colour is off.
+var monoHeat = [HeatLevels]string{"·", "░", "▒", "▓", "█"}
+
+// Theme is what pages style their output with. Build one with New or
+// Resolve; the zero value renders plain text.
+type Theme struct {
+	Name string
+	// Emoji shows emoji in headings and markers; off, plain symbols are used.
+	Emoji bool
+	// Color is false under NO_COLOR: styles keep bold and underline only.
+	Color bool
+
+	Title    lipgloss.Style
+	Rule     lipgloss.Style
+	Text     lipgloss.Style
+	Muted    lipgloss.Style
+	Selected lipgloss.Style
+	Success  lipgloss.Style
+	Warning  lipgloss.Style
+	Error    lipgloss.Style
+	Banner   lipgloss.Style
+
+	palette palette
+}
+
+// New builds the named theme. Auto isn't accepted here; see Resolve.
+func New(name string, emoji, color bool) (Theme, error) {
+	p, ok := palettes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(Names, ", "))
+	}
+	if !color {
+		p = palette{border: p.border}
+	}
+
+	fg := func(c lipgloss.TerminalColor) lipgloss.Style {
+		if c == nil {
+			return lipgloss.NewStyle()
+		}
+		return lipgloss.NewStyle().Foreground(c)
+	}
+
+	t := Theme{
+		Name:     name,
+		Emoji:    emoji,
+		Color:    color,
+		Title:    fg(p.accent).Bold(true),
+		Rule:     fg(p.muted),
+		Text:     fg(p.text),
+		Muted:    fg(p.muted),
+		Selected: fg(p.accent).Bold(true),
+		Success:  fg(p.success),
+		Warning:  fg(p.warning).Bold(true),
+		Error:    fg(p.err).Bold(true),
+		Banner:   fg(p.warning).Border(p.border).Padding(0, 1),
+		palette:  p,
+	}
+	if p.warning != nil {
+		t.Banner = t.Banner.BorderForeground(p.warning)
+	}
+	if name == HighContrast {
+		t.Selected = t.Selected.Reverse(true)
+	}
+	return t, nil
+}
+
+// Resolve picks a theme from the config's name: auto follows the terminal
+// background. noColor (NO_COLOR being set) turns colour off whatever the
+// theme.
+func Resolve(name string, emoji, noColor bool) (Theme, error) {
+	if name == "" || name == Auto {
+		name = Light
+		if lipgloss.HasDarkBackground() {
+			name = Dark
+		}
+	}
+	return New(name, emoji, !noColor)
+}
+
+// NoColor reports whether the NO_COLOR convention asks for no colour.
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Default is the dark theme with emoji, for tests and as a fallback when
+// the configured theme can't be loaded.
+func Default() Theme {
+	t, _ := New(Dark, true, true)
+	return t
+}
+
+// Icon returns emoji when emoji are on, otherwise plain.
+func (t Theme) Icon(emoji, plain string) string {
+	if t.Emoji {
+		return emoji
+	}
+	return plain
+}
+
+// Header renders a page heading with its underline.
+func (t Theme) Header(icon, title string) string {
+	heading := title
+	if t.Emoji && icon != "" {
+		heading = icon + " " + title
+	}
+	return "\n" + t.Title.Render(heading) + "\n" + t.Rule.Render(strings.Repeat("=", 20)) + "\n\n"
+}
+
+// Cursor is the three-column marker in front of a list row.
+func (t Theme) Cursor(selected bool) string {
+	if !selected {
+		return "   "
+	}
+	return t.Selected.Render(t.Icon("➜", ">")) + "  "
+}
+
+// Item renders one list row with its cursor, highlighting the selected row.
+func (t Theme) Item(selected bool, text string) string {
+	if selected {
+		return t.Cursor(true) + t.Selected.Render(text)
+	}
+	return t.Cursor(false) + text
+}
+
+// Err renders an error line.
+func (t Theme) Err(msg string) string {
+	return t.Error.Render(t.Icon("⚠", "!") + " " + msg)
+}
+
+// OK renders a confirmation line.
+func (t Theme) OK(msg string) string {
+	return t.Success.Render(t.Icon("✓", "*") + " " + msg)
+}
+
+// Notice renders a boxed warning, like the strict-session banner.
+func (t Theme) Notice(msg string) string {
+	return t.Banner.Render(msg)
+}
+
+// HeatCell renders one heatmap square at level 0 (no focus) to
+// HeatLevels-1. Without colour the level is shown by the glyph instead.
+func (t Theme) HeatCell(level int) string {
+	level = min(max(level, 0), HeatLevels-1)
+	if !t.Color || t.palette.heat[level] == nil {
+		return monoHeat[level]
+	}
+	return lipgloss.NewStyle().Foreground(t.palette.heat[level]).Render("■")
+}
+
+// ProgressOptions styles a bubbles progress bar to match.
+func (t Theme) ProgressOptions() []progress.Option {
+	opts := []progress.Option{progress.WithoutPercentage()}
+	if !t.Color || t.palette.accent == nil {
+		return append(opts, progress.WithFillCharacters('#', '-'), progress.WithSolidFill(""))
+	}
+	if t.Name == HighContrast {
+		return append(opts, progress.WithSolidFill("11"))
+	}
+	return append(opts, progress.WithGradient(colorString(t.palette.muted), colorString(t.palette.accent)))
+}
+
+// HelpStyles styles the key help at the bottom of the screen.
+func (t Theme) HelpStyles() help.Styles {
+	styles := help.New().Styles
+	styles.ShortKey = t.Text
+	styles.FullKey = t.Text
+	styles.ShortDesc = t.Muted
+	styles.FullDesc = t.Muted
+	styles.ShortSeparator = t.Muted
+	styles.FullSeparator = t.Muted
+	styles.Ellipsis = t.Muted
+	return styles
+}
+
+func colorString(c lipgloss.TerminalColor) string {
+	if color, ok := c.(lipgloss.Color); ok {
+		return string(color)
+	}
+	return ""
+}
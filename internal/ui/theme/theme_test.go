@@ -0,0 +1,48 @@
+package theme
+
+import "testing"
+
+// Test New - every named theme builds and unknown names are rejected
+func TestNew(t *testing.T) {
+	for _, name := range []string{Dark, Light, HighContrast} {
+		if _, err := New(name, true, true); err != nil {
+			t.Errorf("New(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{Auto, "", "solarized"} {
+		if _, err := New(name, true, true); err == nil {
+			t.Errorf("New(%q) should fail", name)
+		}
+	}
+}
+
+// Test plain output - without colour or emoji nothing but plain text is drawn
+func TestPlain(t *testing.T) {
+	th, err := New(Dark, false, false)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"header", th.Header("🔒", "Block Sites"), "\nBlock Sites\n====================\n\n"},
+		{"selected item", th.Item(true, "example.com"), ">  example.com"},
+		{"item", th.Item(false, "example.com"), "   example.com"},
+		{"error", th.Err("no"), "! no"},
+		{"ok", th.OK("yes"), "* yes"},
+		{"empty heat", th.HeatCell(0), "·"},
+		{"full heat", th.HeatCell(HeatLevels - 1), "█"},
+		{"heat clamped", th.HeatCell(HeatLevels + 3), "█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package uitest drives TUI models in tests. A Driver feeds key presses and
+// window sizes through Update, runs the commands that come back and keeps
+// the resulting model, so a test can snapshot View() after each step. The
+// Clock and Store stand in for the real clock and service.
+//
+// Commands run one at a time, in order, on the test's goroutine, and every
+// message they return is delivered before Send returns. Timers go through
+// Clock.Tick and only fire when the test moves the clock with Advance, so a
+// view never depends on how fast the test ran.
+package uitest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type Driver struct {
+	t     testing.TB
+	Model tea.Model
+	// Msgs is every message the model was sent, so page tests can check
+	// for navigation requests.
+	Msgs []tea.Msg
+	// Quit is set once a command asked the program to quit.
+	Quit bool
+
+	timers []timerMsg
+}
+
+// timerMsg is a Clock.Tick waiting for its clock to reach at.
+type timerMsg struct {
+	clock *Clock
+	at    time.Time
+	fire  func(time.Time) tea.Msg
+}
+
+// Tick is tea.Tick for pages under test: the message is delivered once the
+// Driver advances the clock d past when the command ran.
+func (c *Clock) Tick(d time.Duration, fn func(time.Time) tea.Msg) tea.Cmd {
+	return func() tea.Msg { return timerMsg{clock: c, at: c.Now().Add(d), fire: fn} }
+}
+
+// New wraps model and runs its Init command.
+func New(t testing.TB, model tea.Model) *Driver {
+	t.Helper()
+	d := &Driver{t: t, Model: model}
+	d.run(model.Init())
+	return d
+}
+
+// Size sends a window size.
+func (d *Driver) Size(width, height int) *Driver {
+	return d.Send(tea.WindowSizeMsg{Width: width, Height: height})
+}
+
+// Press sends each key in turn, named as tea.KeyMsg.String() names them:
+// "enter", "esc", "up", "ctrl+c", "a" and so on.
+func (d *Driver) Press(keys ...string) *Driver {
+	for _, k := range keys {
+		d.Send(Key(k))
+	}
+	return d
+}
+
+// Type sends text one character at a time, as if typed.
+func (d *Driver) Type(text string) *Driver {
+	for _, r := range text {
+		d.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return d
+}
+
+// Send delivers msg and everything its commands produce in response.
+func (d *Driver) Send(msg tea.Msg) *Driver {
+	d.t.Helper()
+	switch msg := msg.(type) {
+	case tea.QuitMsg:
+		d.Quit = true
+		return d
+	case timerMsg:
+		d.timers = append(d.timers, msg)
+		return d
+	}
+	d.Msgs = append(d.Msgs, msg)
+
+	var cmd tea.Cmd
+	d.Model, cmd = d.Model.Update(msg)
+	d.run(cmd)
+	return d
+}
+
+// Advance moves clock forward by, firing each timer set on it as the clock
+// reaches it, earliest first. Timers a fired timer sets go off too if they
+// fall due before the clock stops.
+func (d *Driver) Advance(clock *Clock, by time.Duration) *Driver {
+	d.t.Helper()
+	end := clock.Now().Add(by)
+	for {
+		i := d.nextTimer(clock, end)
+		if i < 0 {
+			break
+		}
+		timer := d.timers[i]
+		d.timers = slices.Delete(d.timers, i, i+1)
+		clock.Advance(timer.at.Sub(clock.Now()))
+		d.Send(timer.fire(timer.at))
+	}
+	clock.Advance(end.Sub(clock.Now()))
+	return d
+}
+
+// nextTimer finds the earliest timer on clock due by end, or returns -1.
+func (d *Driver) nextTimer(clock *Clock, end time.Time) int {
+	next := -1
+	for i, timer := range d.timers {
+		if timer.clock != clock || timer.at.After(end) {
+			continue
+		}
+		if next < 0 || timer.at.Before(d.timers[next].at) {
+			next = i
+		}
+	}
+	return next
+}
+
+func (d *Driver) View() string { return d.Model.View() }
+
+// run executes cmd, and every command in a batch in the order given,
+// delivering each message before the next command runs.
+func (d *Driver) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			d.run(cmd)
+		}
+	default:
+		d.Send(msg)
+	}
+}
+
+// Key builds the key message for a key name.
+func Key(name string) tea.KeyMsg {
+	if t, ok := namedKeys[name]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+var namedKeys = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"backspace": tea.KeyBackspace,
+	"ctrl+c":    tea.KeyCtrlC,
+	" ":         tea.KeySpace,
+}
@@ -0,0 +1,462 @@
+package uitest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/youssef28m/LockIn/internal/backup"
+	"github.com/youssef28m/LockIn/internal/blocklist"
+	"github.com/youssef28m/LockIn/internal/models"
+	"github.com/youssef28m/LockIn/internal/service"
+	"github.com/youssef28m/LockIn/internal/storage"
+	"github.com/youssef28m/LockIn/internal/validator"
+)
+
+// Clock is a settable clock for Env.Now and the Store.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewClock(now time.Time) *Clock { return &Clock{now: now} }
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Store is an in-memory service.Service that follows the same rules as
+// service.Local, but reads the time from its Clock so screens that show
+// sessions render the same on every run.
+type Store struct {
+	Clock *Clock
+
+	Sessions    []models.Session
+	Sites       []models.BlockedSite
+	Apps        []models.BlockedApp
+	ProfileList []models.Profile
+	// EventLog is appended to as sessions start and stop.
+	EventLog []models.SessionEvent
+	// StatsResult is what Stats returns.
+	StatsResult *service.Stats
+	// Audit is what VerifyAudit returns; nil means the log checks out.
+	Audit *storage.AuditReport
+
+	// mu guards everything above; the UI calls the store from commands.
+	mu     sync.Mutex
+	lastID int64
+}
+
+var _ service.Service = (*Store)(nil)
+
+func NewStore(clock *Clock) *Store { return &Store{Clock: clock} }
+
+func (s *Store) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+// AddSession records a session and returns it with its ID.
+func (s *Store) AddSession(session models.Session) models.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSession(session)
+}
+
+// AddSite and AddApp seed the block lists.
+func (s *Store) AddSite(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addSite(domain)
+}
+
+func (s *Store) AddApp(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addApp(name)
+}
+
+func (s *Store) addSession(session models.Session) models.Session {
+	session.ID = s.nextID()
+	s.Sessions = append(s.Sessions, session)
+	return session
+}
+
+func (s *Store) addSite(domain string) {
+	s.Sites = append(s.Sites, models.BlockedSite{ID: s.nextID(), Domain: domain})
+}
+
+func (s *Store) addApp(name string) {
+	s.Apps = append(s.Apps, models.BlockedApp{ID: s.nextID(), ProcessName: name})
+}
+
+func (s *Store) active() *models.Session {
+	now := s.Clock.Now()
+	for i := range s.Sessions {
+		if s.Sessions[i].Active && s.Sessions[i].RemainingAt(now) > 0 {
+			return &s.Sessions[i]
+		}
+	}
+	return nil
+}
+
+func (s *Store) checkNotStrict() error {
+	if active := s.active(); active != nil && active.Strict {
+		return service.ErrStrictSession
+	}
+	return nil
+}
+
+//***********************************************************//
+// Sessions
+//***********************************************************//
+
+func (s *Store) StartSession(opts service.StartOptions) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	opts.Profile = strings.TrimSpace(opts.Profile)
+	if opts.Duration == 0 && opts.Profile != "" {
+		for _, profile := range s.ProfileList {
+			if profile.Name == opts.Profile {
+				opts.Duration = time.Duration(profile.DurationSeconds) * time.Second
+			}
+		}
+	}
+	err := service.ValidateDuration(opts.Duration)
+	if err != nil {
+		return nil, err
+	}
+	err = service.ValidateBreak(opts.Break)
+	if err != nil {
+		return nil, err
+	}
+	if s.active() != nil {
+		return nil, service.ErrSessionActive
+	}
+
+	session := s.addSession(models.Session{
+		StartTime:       s.Clock.Now().Unix(),
+		DurationSeconds: int64(opts.Duration / time.Second),
+		Active:          true,
+		Profile:         opts.Profile,
+		Strict:          opts.Strict,
+		BreakSeconds:    int64(opts.Break / time.Second),
+	})
+	s.logEvent(session.ID, models.EventStarted, map[string]any{
+		"duration_seconds": session.DurationSeconds,
+		"profile":          session.Profile,
+		"strict":           session.Strict,
+	})
+	return &session, nil
+}
+
+func (s *Store) ActiveSession() (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := s.active()
+	if active == nil {
+		return nil, nil
+	}
+	session := *active
+	return &session, nil
+}
+
+func (s *Store) StopSession() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := s.active()
+	if active == nil {
+		return service.ErrNoActiveSession
+	}
+	if active.Strict {
+		return service.ErrStrictSession
+	}
+	now := s.Clock.Now()
+	active.Abort(now)
+	s.logEvent(active.ID, models.EventAborted, map[string]any{"remaining_seconds": active.RemainingAt(now)})
+	return nil
+}
+
+func (s *Store) ExtendSession(by time.Duration) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := s.active()
+	if active == nil {
+		return nil, service.ErrNoActiveSession
+	}
+	err := service.ValidateExtension(active, by, s.Clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	seconds := int64(by / time.Second)
+	active.DurationSeconds += seconds
+	s.logEvent(active.ID, models.EventExtended, map[string]any{"by_seconds": seconds, "duration_seconds": active.DurationSeconds})
+	session := *active
+	return &session, nil
+}
+
+func (s *Store) logEvent(sessionID int64, typ string, payload map[string]any) {
+	event := models.NewEvent(typ, s.Clock.Now(), payload)
+	event.ID = s.nextID()
+	event.SessionID = sessionID
+	s.EventLog = append(s.EventLog, event)
+}
+
+func (s *Store) Events(q service.EventQuery) ([]models.SessionEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []models.SessionEvent
+	for _, event := range s.EventLog {
+		switch {
+		case q.SessionID != 0 && event.SessionID != q.SessionID:
+		case !q.From.IsZero() && event.Time().Before(q.From):
+		case !q.To.IsZero() && !event.Time().Before(q.To):
+		default:
+			events = append(events, event)
+		}
+	}
+	if q.Limit > 0 && len(events) > q.Limit {
+		events = events[len(events)-q.Limit:]
+	}
+	return events, nil
+}
+
+func (s *Store) VerifyAudit() (*storage.AuditReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Audit != nil {
+		report := *s.Audit
+		return &report, nil
+	}
+	return &storage.AuditReport{Events: len(s.EventLog)}, nil
+}
+
+// newestFirst returns the sessions matching keep, most recent first.
+func (s *Store) newestFirst(keep func(models.Session) bool) []models.Session {
+	var sessions []models.Session
+	for _, session := range s.Sessions {
+		if keep(session) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartTime > sessions[j].StartTime })
+	return sessions
+}
+
+func (s *Store) History(limit int) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.newestFirst(func(models.Session) bool { return true })
+	return sessions[:min(limit, len(sessions))], nil
+}
+
+func (s *Store) QueryHistory(q service.HistoryQuery) (*service.HistoryPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q.Limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	sessions := s.newestFirst(func(session models.Session) bool {
+		switch {
+		case !q.From.IsZero() && session.StartTime < q.From.Unix():
+			return false
+		case !q.To.IsZero() && session.StartTime >= q.To.Unix():
+			return false
+		case q.Profile != "" && session.Profile != q.Profile:
+			return false
+		}
+		return true
+	})
+
+	page := &service.HistoryPage{Total: len(sessions)}
+	if q.Offset < len(sessions) {
+		page.Sessions = sessions[q.Offset:min(q.Offset+q.Limit, len(sessions))]
+	}
+	return page, nil
+}
+
+func (s *Store) DeleteSession(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if active := s.active(); active != nil && active.ID == id {
+		return fmt.Errorf("the running session can't be deleted")
+	}
+	s.Sessions = slices.DeleteFunc(s.Sessions, func(session models.Session) bool { return session.ID == id })
+	return nil
+}
+
+func (s *Store) AnnotateSession(id int64, note string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.Sessions {
+		if s.Sessions[i].ID == id {
+			s.Sessions[i].Note = strings.TrimSpace(note)
+			return nil
+		}
+	}
+	return fmt.Errorf("no session with id %d", id)
+}
+
+func (s *Store) Profiles() ([]models.Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ProfileList, nil
+}
+
+func (s *Store) Stats(now time.Time) (*service.Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.StatsResult == nil {
+		return &service.Stats{}, nil
+	}
+	return s.StatsResult, nil
+}
+
+//***********************************************************//
+// Block lists
+//***********************************************************//
+
+func (s *Store) AddBlockedSite(domain string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if !validator.IsValidDomain(domain) {
+		return fmt.Errorf("invalid domain format")
+	}
+	for _, site := range s.Sites {
+		if site.Domain == domain {
+			return fmt.Errorf("%s is already blocked", domain)
+		}
+	}
+	s.addSite(domain)
+	return nil
+}
+
+func (s *Store) UpdateBlockedSite(site models.BlockedSite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkNotStrict(); err != nil {
+		return err
+	}
+	for i := range s.Sites {
+		if s.Sites[i].ID == site.ID {
+			s.Sites[i].Domain = strings.ToLower(strings.TrimSpace(site.Domain))
+			return nil
+		}
+	}
+	return fmt.Errorf("no site with id %d", site.ID)
+}
+
+func (s *Store) RemoveBlockedSite(domain string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkNotStrict(); err != nil {
+		return err
+	}
+	s.Sites = slices.DeleteFunc(s.Sites, func(site models.BlockedSite) bool { return site.Domain == domain })
+	return nil
+}
+
+func (s *Store) ListBlockedSites() ([]models.BlockedSite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.Sites), nil
+}
+
+func (s *Store) AddBlockedApp(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name = strings.TrimSpace(name)
+	if !validator.IsValidProcessName(name) {
+		return fmt.Errorf("invalid process name")
+	}
+	for _, app := range s.Apps {
+		if app.ProcessName == name {
+			return fmt.Errorf("%s is already blocked", name)
+		}
+	}
+	s.addApp(name)
+	return nil
+}
+
+func (s *Store) UpdateBlockedApp(app models.BlockedApp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkNotStrict(); err != nil {
+		return err
+	}
+	for i := range s.Apps {
+		if s.Apps[i].ID == app.ID {
+			s.Apps[i].ProcessName = strings.TrimSpace(app.ProcessName)
+			return nil
+		}
+	}
+	return fmt.Errorf("no app with id %d", app.ID)
+}
+
+func (s *Store) RemoveBlockedApp(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkNotStrict(); err != nil {
+		return err
+	}
+	s.Apps = slices.DeleteFunc(s.Apps, func(app models.BlockedApp) bool { return app.ProcessName == name })
+	return nil
+}
+
+func (s *Store) ListBlockedApps() ([]models.BlockedApp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.Apps), nil
+}
+
+func (s *Store) ImportBlockList(list blocklist.List) (*service.ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checked, rejected := blocklist.Check(list.Document())
+	if len(rejected) > 0 {
+		return nil, fmt.Errorf("block list has %d invalid entries, starting with %s", len(rejected), rejected[0])
+	}
+
+	result := &service.ImportResult{}
+	for _, domain := range checked.Sites {
+		if slices.ContainsFunc(s.Sites, func(site models.BlockedSite) bool { return site.Domain == domain }) {
+			result.ExistingSites++
+			continue
+		}
+		s.addSite(domain)
+		result.AddedSites++
+	}
+	for _, name := range checked.Apps {
+		if slices.ContainsFunc(s.Apps, func(app models.BlockedApp) bool { return app.ProcessName == name }) {
+			result.ExistingApps++
+			continue
+		}
+		s.addApp(name)
+		result.AddedApps++
+	}
+	return result, nil
+}
+
+// errNoDatabase is returned by the operations that only make sense against
+// a real database. No screen uses them.
+var errNoDatabase = errors.New("uitest.Store has no database to back up")
+
+func (s *Store) Backup() (*backup.Archive, error) { return nil, errNoDatabase }
+
+func (s *Store) Restore(archive *backup.Archive, mode string) (*backup.Result, error) {
+	return nil, errNoDatabase
+}
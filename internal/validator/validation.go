@@ -29,4 +29,19 @@ func IsValidDomain(domain string) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+var processNameRegex = regexp.MustCompile(`^[A-Za-z0-9._+-][A-Za-z0-9 ._+-]*$`)
+
+// IsValidProcessName accepts bare executable names like "steam" or
+// "Discord", not paths or shell fragments.
+func IsValidProcessName(name string) bool {
+
+	name = strings.TrimSpace(name)
+
+	if name == "" || len(name) > 255 {
+		return false
+	}
+
+	return processNameRegex.MatchString(name)
+}